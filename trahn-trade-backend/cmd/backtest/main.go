@@ -0,0 +1,441 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/kjannette/trahn-backend/internal/backtest"
+	"github.com/kjannette/trahn-backend/internal/config"
+	"github.com/kjannette/trahn-backend/internal/db"
+	"github.com/kjannette/trahn-backend/internal/models"
+	"github.com/kjannette/trahn-backend/internal/repository"
+)
+
+const usage = `usage:
+  backtest [run] [flags]   replay one parameter set
+  backtest sweep [flags]   grid-search parameter ranges and rank the results
+  backtest walkforward [flags]
+                           optimize on rolling in-sample windows, score out of sample
+
+Run "backtest <command> -h" for flags.`
+
+func main() {
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "config load error: %v\n", err)
+		os.Exit(1)
+	}
+
+	cmd, args := "run", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		cmd, args = args[0], args[1:]
+	}
+
+	switch cmd {
+	case "run":
+		err = runCmd(cfg, args)
+	case "sweep":
+		err = sweepCmd(cfg, args)
+	case "walkforward":
+		err = walkForwardCmd(cfg, args)
+	default:
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+}
+
+// --- run ---
+
+func runCmd(cfg *config.Config, args []string) error {
+	bt := backtest.FromConfig(cfg)
+	fs := flag.NewFlagSet("run", flag.ExitOnError)
+	fromStr, toStr := rangeFlags(fs)
+	fs.IntVar(&bt.GridLevels, "levels", bt.GridLevels, "number of grid levels")
+	fs.Float64Var(&bt.GridSpacingPercent, "spacing", bt.GridSpacingPercent, "grid spacing percent")
+	fs.Float64Var(&bt.AmountPerGrid, "amount", bt.AmountPerGrid, "USD amount per grid level")
+	fs.Float64Var(&bt.CenterPrice, "center", bt.CenterPrice, "grid center price (0 = S/R midpoint or first price)")
+	fs.DurationVar(&bt.PostTradeCooldown, "cooldown", bt.PostTradeCooldown, "post-trade cooldown")
+	srMethod := fs.String("sr", bt.SRMethod, "S/R method for grid center: simple|percentile|pivot|pivot-fib|fractal|none (volume-profile runs as percentile)")
+	fs.DurationVar(&bt.SRLookback, "lookback", bt.SRLookback, "S/R warm-up window before -from")
+	fs.Int64Var(&bt.Seed, "seed", bt.Seed, "slippage RNG seed")
+	showTrades := fs.Bool("trades", false, "print every simulated trade")
+	asJSON := fs.Bool("json", false, "print the full result as JSON")
+	fs.Parse(args)
+
+	bt.SRMethod = parseSRMethod(*srMethod)
+	from, to, err := parseRange(*fromStr, *toStr)
+	if err != nil {
+		return err
+	}
+
+	pool, err := connect(cfg)
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+
+	res, err := backtest.RunRange(context.Background(), repository.NewPriceRepo(pool), from, to, bt)
+	if err != nil {
+		return fmt.Errorf("backtest failed: %w", err)
+	}
+
+	if *asJSON {
+		return printJSON(res)
+	}
+
+	if *showTrades {
+		for _, t := range res.Trades {
+			fmt.Printf("%s  %-4s L%-2d @ $%9.2f  %.6f ETH  %10.2f USDC  slip %.3f%%\n",
+				t.Timestamp.Format(time.RFC3339), t.Side, t.GridLevel, t.ExecutionPrice,
+				t.ETHAmount, t.USDCAmount, t.SlippagePct)
+		}
+		fmt.Println()
+	}
+
+	s := res.Summary
+	fmt.Println("=== Backtest Summary ===")
+	fmt.Printf("Range: %s -> %s (%d ticks)\n", s.Start.Format(time.RFC3339), s.End.Format(time.RFC3339), s.Ticks)
+	fmt.Printf("Grid: %d levels, %.2f%% spacing, $%.0f/level, cooldown %s, S/R %s\n",
+		bt.GridLevels, bt.GridSpacingPercent, bt.AmountPerGrid, bt.PostTradeCooldown, srLabel(bt.SRMethod))
+	fmt.Println("--------------------------------------")
+	fmt.Printf("Initial value:  $%.2f\n", s.InitialValueUSD)
+	fmt.Printf("Final value:    $%.2f\n", s.FinalValueUSD)
+	fmt.Printf("Net P&L:        $%.2f (%.2f%%)\n", s.NetPnL, s.NetPnLPct)
+	fmt.Printf("Buy & hold:     %.2f%%\n", s.BuyAndHoldPnLPct)
+	fmt.Printf("Max drawdown:   %.2f%%\n", s.MaxDrawdownPct)
+	fmt.Printf("Trades:         %d (%d buys, %d sells, %d failed fills)\n",
+		s.TotalTrades, s.BuyTrades, s.SellTrades, s.FailedFills)
+	fmt.Printf("Gas:            %.6f ETH ($%.2f)\n", s.GasSpentETH, s.GasSpentUSD)
+	fmt.Printf("Realized:       $%.2f over %d round trips\n", s.RealizedPnL, s.RoundTrips)
+	return nil
+}
+
+// --- sweep ---
+
+func sweepCmd(cfg *config.Config, args []string) error {
+	spec := backtest.SweepSpec{Base: backtest.FromConfig(cfg)}
+	fs := flag.NewFlagSet("sweep", flag.ExitOnError)
+	fromStr, toStr := rangeFlags(fs)
+	parseSweep := sweepFlags(fs, &spec)
+	top := fs.Int("top", 20, "rows to print (0 = all)")
+	save := fs.Bool("save", true, "persist the sweep and its runs")
+	asJSON := fs.Bool("json", false, "print all runs as JSON")
+	fs.Parse(args)
+
+	if err := parseSweep(); err != nil {
+		return err
+	}
+
+	from, to, err := parseRange(*fromStr, *toStr)
+	if err != nil {
+		return err
+	}
+
+	pool, err := connect(cfg)
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+
+	ctx := context.Background()
+	prices, err := spec.LoadRange(ctx, repository.NewPriceRepo(pool), from, to)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Sweeping %d combinations over %d ticks...\n", len(spec.Combinations()), len(prices))
+	started := time.Now()
+	runs, err := backtest.Sweep(ctx, prices, spec)
+	if err != nil {
+		return fmt.Errorf("sweep failed: %w", err)
+	}
+	fmt.Printf("Done in %s\n\n", time.Since(started).Round(time.Millisecond))
+
+	if *save {
+		specJSON, _ := json.Marshal(spec)
+		saved, err := repository.NewBacktestRepo(pool).RecordSweep(ctx, &models.BacktestSweep{
+			RangeFrom: from,
+			RangeTo:   to,
+			RankBy:    spec.RankBy,
+			SpecJSON:  specJSON,
+		}, backtest.RunModels(runs))
+		if err != nil {
+			return fmt.Errorf("save sweep: %w", err)
+		}
+		fmt.Printf("Saved as sweep #%d\n\n", saved.ID)
+	}
+
+	if *asJSON {
+		return printJSON(runs)
+	}
+
+	fmt.Printf("%4s  %6s  %7s  %8s  %8s  %-10s  %10s  %7s  %6s  %6s  %10s\n",
+		"RANK", "LEVELS", "SPACING", "AMOUNT", "COOLDOWN", "S/R", "NET P&L", "P&L %", "MAX DD", "TRADES", "GAS ETH")
+	for i, r := range runs {
+		if *top > 0 && i >= *top {
+			fmt.Printf("... %d more\n", len(runs)-i)
+			break
+		}
+		p := r.Params
+		if r.Err != "" {
+			fmt.Printf("%4d  %6d  %6.2f%%  %8.0f  %8s  %-10s  error: %s\n",
+				r.Rank, p.GridLevels, p.GridSpacingPercent, p.AmountPerGrid, p.PostTradeCooldown, srLabel(p.SRMethod), r.Err)
+			continue
+		}
+		s := r.Summary
+		fmt.Printf("%4d  %6d  %6.2f%%  %8.0f  %8s  %-10s  %10.2f  %6.2f%%  %5.2f%%  %6d  %10.6f\n",
+			r.Rank, p.GridLevels, p.GridSpacingPercent, p.AmountPerGrid, p.PostTradeCooldown, srLabel(p.SRMethod),
+			s.NetPnL, s.NetPnLPct, s.MaxDrawdownPct, s.TotalTrades, s.GasSpentETH)
+	}
+	return nil
+}
+
+// --- walk-forward ---
+
+func walkForwardCmd(cfg *config.Config, args []string) error {
+	spec := backtest.WalkForwardSpec{Sweep: backtest.SweepSpec{Base: backtest.FromConfig(cfg)}}
+	fs := flag.NewFlagSet("walkforward", flag.ExitOnError)
+	fromStr, toStr := rangeFlags(fs)
+	parseSweep := sweepFlags(fs, &spec.Sweep)
+	inDays := fs.Int("in-days", 14, "in-sample (optimization) window in days")
+	outDays := fs.Int("out-days", 7, "out-of-sample (evaluation) window in days")
+	stepDays := fs.Int("step-days", 0, "days between windows (0 = out-days)")
+	asJSON := fs.Bool("json", false, "print the full result as JSON")
+	fs.Parse(args)
+
+	if err := parseSweep(); err != nil {
+		return err
+	}
+	spec.InSample = time.Duration(*inDays) * 24 * time.Hour
+	spec.OutOfSample = time.Duration(*outDays) * 24 * time.Hour
+	spec.Step = time.Duration(*stepDays) * 24 * time.Hour
+
+	from, to, err := parseRange(*fromStr, *toStr)
+	if err != nil {
+		return err
+	}
+
+	pool, err := connect(cfg)
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+
+	ctx := context.Background()
+	prices, err := spec.Sweep.LoadRange(ctx, repository.NewPriceRepo(pool), from, to)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Walk-forward: %dd in-sample / %dd out-of-sample, %d combinations per window...\n",
+		*inDays, *outDays, len(spec.Sweep.Combinations()))
+	res, err := backtest.WalkForward(ctx, prices, spec)
+	if err != nil {
+		return fmt.Errorf("walk-forward failed: %w", err)
+	}
+
+	if *asJSON {
+		return printJSON(res)
+	}
+
+	fmt.Printf("\n%-10s  %6s  %7s  %8s  %-10s  %8s  %8s  %6s\n",
+		"OOS START", "LEVELS", "SPACING", "COOLDOWN", "S/R", "IS P&L", "OOS P&L", "TRADES")
+	for _, w := range res.Windows {
+		if w.Err != "" {
+			fmt.Printf("%-10s  error: %s\n", w.OutSampleStart.Format("2006-01-02"), w.Err)
+			continue
+		}
+		b := w.Best
+		fmt.Printf("%-10s  %6d  %6.2f%%  %8s  %-10s  %7.2f%%  %7.2f%%  %6d\n",
+			w.OutSampleStart.Format("2006-01-02"), b.GridLevels, b.GridSpacingPercent,
+			b.PostTradeCooldown, srLabel(b.SRMethod),
+			w.InSample.NetPnLPct, w.OutOfSample.NetPnLPct, w.OutOfSample.TotalTrades)
+	}
+
+	s := res.Summary
+	fmt.Println("\n=== Stitched Out-of-Sample ===")
+	fmt.Printf("Range: %s -> %s (%d ticks)\n", s.Start.Format(time.RFC3339), s.End.Format(time.RFC3339), s.Ticks)
+	fmt.Printf("Net P&L:        $%.2f (%.2f%%)\n", s.NetPnL, s.NetPnLPct)
+	fmt.Printf("Buy & hold:     %.2f%%\n", s.BuyAndHoldPnLPct)
+	fmt.Printf("Max drawdown:   %.2f%%\n", s.MaxDrawdownPct)
+	fmt.Printf("Trades:         %d (%d buys, %d sells)\n", s.TotalTrades, s.BuyTrades, s.SellTrades)
+	fmt.Printf("Gas:            %.6f ETH ($%.2f)\n", s.GasSpentETH, s.GasSpentUSD)
+	fmt.Printf("Realized:       $%.2f over %d round trips\n", s.RealizedPnL, s.RoundTrips)
+	fmt.Printf("WF efficiency:  %.2f\n", res.Efficiency)
+	return nil
+}
+
+// --- helpers ---
+
+// sweepFlags registers the swept-parameter flags on fs. The returned func
+// parses them into spec and must be called after fs.Parse.
+func sweepFlags(fs *flag.FlagSet, spec *backtest.SweepSpec) func() error {
+	levels := fs.String("levels", "", "grid levels to try: list (8,10,12) or range (8:16:2)")
+	spacing := fs.String("spacing", "", "grid spacing percents: list or range (1:3:0.5)")
+	amount := fs.String("amount", "", "USD amounts per grid: list or range")
+	cooldown := fs.String("cooldown", "", "post-trade cooldowns: list (0s,60s,5m)")
+	srMethods := fs.String("sr", "", "S/R methods: list (simple,percentile,pivot,pivot-fib,fractal,none; volume-profile runs as percentile)")
+	fs.DurationVar(&spec.Base.SRLookback, "lookback", spec.Base.SRLookback, "S/R warm-up window")
+	fs.IntVar(&spec.Workers, "workers", 0, "parallel runs (0 = one per CPU)")
+	fs.StringVar(&spec.RankBy, "rank", "pnl", "rank by: pnl|drawdown|trades|gas")
+
+	return func() error {
+		var err error
+		if spec.GridLevels, err = parseIntList(*levels); err != nil {
+			return fmt.Errorf("invalid -levels: %w", err)
+		}
+		if spec.GridSpacingPercent, err = parseFloatList(*spacing); err != nil {
+			return fmt.Errorf("invalid -spacing: %w", err)
+		}
+		if spec.AmountPerGrid, err = parseFloatList(*amount); err != nil {
+			return fmt.Errorf("invalid -amount: %w", err)
+		}
+		if spec.PostTradeCooldown, err = parseDurationList(*cooldown); err != nil {
+			return fmt.Errorf("invalid -cooldown: %w", err)
+		}
+		for _, m := range splitList(*srMethods) {
+			spec.SRMethods = append(spec.SRMethods, parseSRMethod(m))
+		}
+		return nil
+	}
+}
+
+func rangeFlags(fs *flag.FlagSet) (from, to *string) {
+	now := time.Now().UTC()
+	from = fs.String("from", now.AddDate(0, 0, -30).Format("2006-01-02"), "start date (YYYY-MM-DD, inclusive)")
+	to = fs.String("to", now.Format("2006-01-02"), "end date (YYYY-MM-DD, exclusive)")
+	return from, to
+}
+
+func parseRange(fromStr, toStr string) (time.Time, time.Time, error) {
+	from, err := time.Parse("2006-01-02", fromStr)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid -from: %w", err)
+	}
+	to, err := time.Parse("2006-01-02", toStr)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid -to: %w", err)
+	}
+	if !from.Before(to) {
+		return time.Time{}, time.Time{}, fmt.Errorf("-from must be before -to")
+	}
+	return from, to, nil
+}
+
+func connect(cfg *config.Config) (*pgxpool.Pool, error) {
+	pool, err := db.Connect(cfg.DSN())
+	if err != nil {
+		return nil, fmt.Errorf("[DB] Connection failed: %w", err)
+	}
+	return pool, nil
+}
+
+func printJSON(v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func parseSRMethod(m string) string {
+	if m == "none" {
+		return ""
+	}
+	return backtest.SRMethod(m)
+}
+
+func srLabel(m string) string {
+	if m == "" {
+		return "none"
+	}
+	return m
+}
+
+func splitList(s string) []string {
+	if s == "" {
+		return nil
+	}
+	parts := strings.Split(s, ",")
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+	return parts
+}
+
+// parseFloatList accepts "a,b,c" or an inclusive "min:max:step" range.
+func parseFloatList(s string) ([]float64, error) {
+	if s == "" {
+		return nil, nil
+	}
+	if strings.Contains(s, ":") {
+		parts := strings.Split(s, ":")
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("range must be min:max:step, got %q", s)
+		}
+		var bounds [3]float64
+		for i, p := range parts {
+			f, err := strconv.ParseFloat(p, 64)
+			if err != nil {
+				return nil, err
+			}
+			bounds[i] = f
+		}
+		lo, hi, step := bounds[0], bounds[1], bounds[2]
+		if step <= 0 || hi < lo {
+			return nil, fmt.Errorf("range %q must have min <= max and step > 0", s)
+		}
+		// The epsilon keeps e.g. 1:3:0.1 from dropping 3 to float error
+		// without reaching past max.
+		n := int(math.Floor((hi-lo)/step + 1e-9))
+		out := make([]float64, 0, n+1)
+		for i := 0; i <= n; i++ {
+			out = append(out, lo+float64(i)*step)
+		}
+		return out, nil
+	}
+
+	var out []float64
+	for _, p := range splitList(s) {
+		f, err := strconv.ParseFloat(p, 64)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, f)
+	}
+	return out, nil
+}
+
+func parseIntList(s string) ([]int, error) {
+	fs, err := parseFloatList(s)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]int, len(fs))
+	for i, f := range fs {
+		out[i] = int(f + 0.5)
+	}
+	return out, nil
+}
+
+func parseDurationList(s string) ([]time.Duration, error) {
+	var out []time.Duration
+	for _, p := range splitList(s) {
+		d, err := time.ParseDuration(p)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, d)
+	}
+	return out, nil
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/kjannette/trahn-backend/internal/config"
 	"github.com/kjannette/trahn-backend/internal/db"
 	"github.com/kjannette/trahn-backend/internal/external"
+	"github.com/kjannette/trahn-backend/internal/levels"
 	"github.com/kjannette/trahn-backend/internal/notifications"
 	"github.com/kjannette/trahn-backend/internal/repository"
 	"github.com/kjannette/trahn-backend/internal/scheduler"
@@ -64,21 +65,37 @@ func main() {
 
 	// Repos
 	priceRepo := repository.NewPriceRepo(pool)
+	candleRepo := repository.NewCandleRepo(pool)
 	tradeRepo := repository.NewTradeRepo(pool)
 	srRepo := repository.NewSRRepo(pool)
 	gridRepo := repository.NewGridStateRepo(pool)
-
-	// Shared Dune client (single instance for bot + scheduler)
-	var dune *external.DuneClient
-	if cfg.DuneAPIKey != "" {
-		dune = external.NewDuneClient(cfg.DuneAPIKey, external.DuneOptions{
+	lotRepo := repository.NewLotRepo(pool)
+	portRepo := repository.NewPortfolioRepo(pool)
+	txRepo := repository.NewPendingTxRepo(pool)
+
+	// Shared S/R provider (single instance for bot + scheduler). Local
+	// methods compute levels from price_history; with Dune as the provider,
+	// the local equivalent of its method cross-checks Dune's numbers in the
+	// scheduler log. volume-profile needs DEX trade volume, which only Dune
+	// has, so it alone runs without a local provider or cross-check.
+	var local external.SRProvider
+	if cfg.SRMethod != "volume-profile" {
+		if local, err = levels.New(candleRepo, cfg.SRMethod, cfg.SRLookbackDays); err != nil {
+			fmt.Fprintf(os.Stderr, "[S/R] %v\n", err)
+			os.Exit(1)
+		}
+	}
+	srSource := local
+	var crossCheck external.SRProvider
+	if cfg.SRSource() == "dune" {
+		dune := external.NewDuneClient(cfg.DuneAPIKey, external.DuneOptions{
 			Method:       cfg.SRMethod,
 			LookbackDays: cfg.SRLookbackDays,
 			RefreshHours: cfg.SRRefreshHours,
 		})
 
-		// Warm cache from DB if a recent S/R record exists
-		if latest, err := srRepo.GetLatest(context.Background()); err == nil && latest != nil {
+		// Warm cache from DB if a recent S/R record from this method exists
+		if latest, err := srRepo.GetLatest(context.Background()); err == nil && latest != nil && latest.Method == cfg.SRMethod {
 			dune.SeedCache(&external.SRResult{
 				Support:      latest.Support,
 				Resistance:   latest.Resistance,
@@ -88,6 +105,7 @@ func main() {
 				FetchedAt:    latest.Timestamp,
 			})
 		}
+		srSource, crossCheck = dune, local
 	}
 
 	// Notifications
@@ -98,7 +116,7 @@ func main() {
 	defer stop()
 
 	// 1. API server
-	srv := api.NewServer(pool, apiPort, cfg.APIKey, cfg.CORSAllowOrigin)
+	srv := api.NewServer(pool, apiPort, cfg.APIKey, cfg.CORSAllowOrigin, cfg.CostBasisMethod)
 	go func() {
 		if err := srv.Start(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			fmt.Fprintf(os.Stderr, "[API] Server error: %v\n", err)
@@ -106,28 +124,24 @@ func main() {
 		}
 	}()
 
-	// 2. Grid bot (shares the Dune client)
+	// 2. Grid bot (shares the S/R provider)
 	botService := bot.NewService()
-	if err := botService.Start(ctx, cfg, priceRepo, tradeRepo, gridRepo, notify, dune); err != nil {
+	if err := botService.Start(ctx, cfg, priceRepo, candleRepo, tradeRepo, gridRepo, lotRepo, portRepo, txRepo, notify, srSource); err != nil {
 		fmt.Fprintf(os.Stderr, "[BOT] Start failed: %v\n", err)
 		os.Exit(1)
 	}
 
-	// 3. S/R Scheduler (shares the same Dune client)
-	var srSched *scheduler.SRScheduler
-	if dune != nil {
-		srSched = scheduler.NewSRScheduler(dune, srRepo, scheduler.SRSchedulerConfig{
-			CronInterval:      1 * time.Hour,
-			SRChangeThreshold: 5,
-			GetBotState:       botService.BotState,
-			OnGridRecalculate: func(sr *external.SRResult) {
-				botService.InitializeGrid(ctx)
-			},
-		})
-		srSched.Start()
-	} else {
-		fmt.Println("[SCHEDULER] Skipped - no Dune API key configured")
-	}
+	// 3. S/R Scheduler (shares the same S/R provider)
+	srSched := scheduler.NewSRScheduler(srSource, srRepo, scheduler.SRSchedulerConfig{
+		CronInterval:      1 * time.Hour,
+		SRChangeThreshold: 5,
+		GetBotState:       botService.BotState,
+		OnGridRecalculate: func(sr *external.SRResult) {
+			botService.InitializeGrid(ctx)
+		},
+		CrossCheck: crossCheck,
+	})
+	srSched.Start()
 
 	fmt.Println("\nAll services started successfully")
 
@@ -135,9 +149,7 @@ func main() {
 	<-ctx.Done()
 	fmt.Println("\nShutting down gracefully...")
 
-	if srSched != nil {
-		srSched.Stop()
-	}
+	srSched.Stop()
 
 	botService.Stop()
 
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/kjannette/trahn-backend/internal/accounting"
+	"github.com/kjannette/trahn-backend/internal/config"
+	"github.com/kjannette/trahn-backend/internal/db"
+	"github.com/kjannette/trahn-backend/internal/repository"
+)
+
+const usage = `usage:
+  trades tax-export [flags]   write realized capital gains for a tax year as CSV
+
+Run "trades <command> -h" for flags.`
+
+func main() {
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "config load error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	switch os.Args[1] {
+	case "tax-export":
+		err = taxExportCmd(cfg, os.Args[2:])
+	default:
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+}
+
+func taxExportCmd(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("tax-export", flag.ExitOnError)
+	year := fs.Int("year", time.Now().UTC().Year()-1, "calendar tax year (UTC)")
+	fromStr := fs.String("from", "", "start date (YYYY-MM-DD, inclusive); overrides -year")
+	toStr := fs.String("to", "", "end date (YYYY-MM-DD, exclusive); overrides -year")
+	format := fs.String("format", "csv", "output format: csv|8949")
+	mode := fs.String("mode", "live", "trades to include: live|paper|all")
+	out := fs.String("o", "", "output file (default stdout)")
+	fs.Parse(args)
+
+	from, to := accounting.TaxYear(*year)
+	if *fromStr != "" || *toStr != "" {
+		var err error
+		if from, err = time.Parse("2006-01-02", *fromStr); err != nil {
+			return fmt.Errorf("invalid -from: %w", err)
+		}
+		if to, err = time.Parse("2006-01-02", *toStr); err != nil {
+			return fmt.Errorf("invalid -to: %w", err)
+		}
+		if !from.Before(to) {
+			return fmt.Errorf("-from must be before -to")
+		}
+	}
+
+	var paperMode *bool
+	switch *mode {
+	case "live", "paper":
+		p := *mode == "paper"
+		paperMode = &p
+	case "all":
+	default:
+		return fmt.Errorf("invalid -mode %q, expected live|paper|all", *mode)
+	}
+
+	pool, err := db.Connect(cfg.DSN())
+	if err != nil {
+		return fmt.Errorf("[DB] Connection failed: %w", err)
+	}
+	defer pool.Close()
+
+	ctx := context.Background()
+	lots := repository.NewLotRepo(pool)
+
+	// Bring lots up to date so trades the bot has not synced are included.
+	if n, err := lots.Sync(ctx, cfg.CostBasisMethod); err != nil {
+		return fmt.Errorf("sync lots: %w", err)
+	} else if n > 0 {
+		fmt.Fprintf(os.Stderr, "Applied %d trade(s) to %s lots\n", n, cfg.CostBasisMethod)
+	}
+
+	gains, err := lots.GetCapitalGains(ctx, from, to, paperMode)
+	if err != nil {
+		return fmt.Errorf("load capital gains: %w", err)
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	if err := accounting.WriteGains(w, *format, gains); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Exported %d disposal(s) from %s to %s\n",
+		len(gains), from.Format("2006-01-02"), to.Format("2006-01-02"))
+	return nil
+}
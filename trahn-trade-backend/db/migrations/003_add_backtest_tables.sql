@@ -0,0 +1,35 @@
+-- Migration: Backtest sweep results
+-- Each parameter sweep is one backtest_sweeps row; every combination it ran
+-- is a backtest_runs row, so sweeps can be compared over time.
+
+CREATE TABLE IF NOT EXISTS backtest_sweeps (
+    id BIGSERIAL PRIMARY KEY,
+    range_from TIMESTAMPTZ NOT NULL,
+    range_to TIMESTAMPTZ NOT NULL,
+    rank_by VARCHAR(20) NOT NULL,
+    spec_json JSONB,
+    run_count INTEGER DEFAULT 0,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_backtest_sweep_created ON backtest_sweeps(created_at);
+
+CREATE TABLE IF NOT EXISTS backtest_runs (
+    id BIGSERIAL PRIMARY KEY,
+    sweep_id BIGINT NOT NULL REFERENCES backtest_sweeps(id) ON DELETE CASCADE,
+    rank INTEGER NOT NULL,
+    grid_levels INTEGER NOT NULL,
+    grid_spacing_percent DECIMAL(6, 3) NOT NULL,
+    amount_per_grid DECIMAL(12, 2) NOT NULL,
+    cooldown_seconds INTEGER NOT NULL,
+    sr_method VARCHAR(20) NOT NULL DEFAULT '',
+    net_pnl DECIMAL(12, 2) NOT NULL,
+    net_pnl_percent DECIMAL(8, 3) NOT NULL,
+    max_drawdown_percent DECIMAL(8, 3) NOT NULL,
+    trade_count INTEGER NOT NULL,
+    gas_spent_eth DECIMAL(18, 8) NOT NULL,
+    error TEXT,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_backtest_run_sweep ON backtest_runs(sweep_id);
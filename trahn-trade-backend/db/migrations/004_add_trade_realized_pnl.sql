@@ -0,0 +1,6 @@
+-- Migration: Realized P&L per trade
+-- Sells that close a grid buy record the round trip's profit, net of
+-- slippage and gas. NULL for buys and for sells with no paired buy.
+
+ALTER TABLE trade_history
+  ADD COLUMN IF NOT EXISTS realized_pnl DECIMAL(12, 4);
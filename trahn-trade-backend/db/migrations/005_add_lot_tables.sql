@@ -0,0 +1,35 @@
+-- Migration: Lot-based cost basis
+-- Every buy in trade_history opens a trade_lots row; every sell is matched
+-- against open lots (FIFO/LIFO/HIFO) and recorded in lot_disposals.
+
+CREATE TABLE IF NOT EXISTS trade_lots (
+    id BIGSERIAL PRIMARY KEY,
+    trade_id BIGINT NOT NULL UNIQUE REFERENCES trade_history(id) ON DELETE CASCADE,
+    acquired_at TIMESTAMPTZ NOT NULL,
+    quantity DECIMAL(18, 8) NOT NULL,
+    remaining_quantity DECIMAL(18, 8) NOT NULL,
+    cost_per_eth DECIMAL(12, 4) NOT NULL,
+    is_paper_trade BOOLEAN DEFAULT FALSE,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_lot_open ON trade_lots(is_paper_trade, remaining_quantity);
+
+CREATE TABLE IF NOT EXISTS lot_disposals (
+    id BIGSERIAL PRIMARY KEY,
+    lot_id BIGINT REFERENCES trade_lots(id) ON DELETE CASCADE,
+    sell_trade_id BIGINT NOT NULL REFERENCES trade_history(id) ON DELETE CASCADE,
+    acquired_at TIMESTAMPTZ,
+    disposed_at TIMESTAMPTZ NOT NULL,
+    quantity DECIMAL(18, 8) NOT NULL,
+    proceeds_usd DECIMAL(12, 4) NOT NULL,
+    cost_basis_usd DECIMAL(12, 4),
+    gain_usd DECIMAL(12, 4),
+    method VARCHAR(10) NOT NULL,
+    is_paper_trade BOOLEAN DEFAULT FALSE,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_disposal_lot ON lot_disposals(lot_id);
+CREATE INDEX IF NOT EXISTS idx_disposal_sell ON lot_disposals(sell_trade_id);
+CREATE INDEX IF NOT EXISTS idx_disposal_disposed ON lot_disposals(disposed_at);
@@ -0,0 +1,19 @@
+-- Migration: Live portfolio snapshots
+-- The baseline row holds the wallet's starting ETH/USDC balances, which
+-- live-mode stop-loss/take-profit measure P&L against. Periodic rows are
+-- recorded with each status report.
+
+CREATE TABLE IF NOT EXISTS portfolio_snapshots (
+    id BIGSERIAL PRIMARY KEY,
+    timestamp TIMESTAMPTZ NOT NULL,
+    wallet_address VARCHAR(42) NOT NULL,
+    eth_balance DECIMAL(18, 8) NOT NULL,
+    usdc_balance DECIMAL(18, 6) NOT NULL,
+    eth_price DECIMAL(12, 2),
+    value_usd DECIMAL(14, 2),
+    is_baseline BOOLEAN DEFAULT FALSE,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_portfolio_wallet_baseline ON portfolio_snapshots(wallet_address, is_baseline);
+CREATE INDEX IF NOT EXISTS idx_portfolio_timestamp ON portfolio_snapshots(timestamp);
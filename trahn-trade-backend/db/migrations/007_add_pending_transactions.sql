@@ -0,0 +1,30 @@
+-- Migration: Nonce manager state
+-- One row per wallet nonce the bot has broadcast. hashes lists every
+-- broadcast at that nonce (original, speed-ups and cancel) so the mined one
+-- can be found after a restart. Fee columns hold wei as decimal strings.
+
+CREATE TABLE IF NOT EXISTS pending_transactions (
+    id BIGSERIAL PRIMARY KEY,
+    wallet_address VARCHAR(42) NOT NULL,
+    nonce BIGINT NOT NULL,
+    orig_hash VARCHAR(66) NOT NULL,
+    tx_hash VARCHAR(66) NOT NULL,
+    hashes TEXT[] NOT NULL,
+    to_address VARCHAR(42) NOT NULL,
+    value_wei VARCHAR(80) NOT NULL,
+    data BYTEA,
+    gas_limit BIGINT NOT NULL,
+    gas_price_wei VARCHAR(80),
+    tip_cap_wei VARCHAR(80),
+    fee_cap_wei VARCHAR(80),
+    sent_block BIGINT NOT NULL,
+    replacements INTEGER DEFAULT 0,
+    cancel_hash VARCHAR(66),
+    mined_hash VARCHAR(66),
+    status VARCHAR(10) NOT NULL DEFAULT 'pending',
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    UNIQUE (wallet_address, nonce)
+);
+
+CREATE INDEX IF NOT EXISTS idx_pending_tx_status ON pending_transactions(wallet_address, status);
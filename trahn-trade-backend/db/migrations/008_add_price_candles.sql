@@ -0,0 +1,17 @@
+-- Migration: OHLC candles over price_history
+-- One row per resolution (1m, 5m, 1h, 1d) and UTC-aligned bucket start.
+-- Built incrementally: each sync re-aggregates from the bucket before the
+-- newest stored one, so late samples in a just-closed bucket count. ticks is the
+-- number of price samples in the bucket (price_history has no traded volume).
+
+CREATE TABLE IF NOT EXISTS price_candles (
+    resolution VARCHAR(4) NOT NULL,
+    bucket TIMESTAMPTZ NOT NULL,
+    open DECIMAL(12, 2) NOT NULL,
+    high DECIMAL(12, 2) NOT NULL,
+    low DECIMAL(12, 2) NOT NULL,
+    close DECIMAL(12, 2) NOT NULL,
+    ticks INTEGER NOT NULL,
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (resolution, bucket)
+);
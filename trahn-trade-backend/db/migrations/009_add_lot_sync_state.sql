@@ -0,0 +1,17 @@
+-- Migration: Lot sync watermark
+-- The last trade_history id LotRepo.Sync has examined. Trades that open no
+-- lot and dispose of nothing (no quantity) leave no row in trade_lots or
+-- lot_disposals, so without this they would be re-read on every sync.
+-- Seeded from the lot tables so existing databases carry on where they were.
+
+CREATE TABLE IF NOT EXISTS lot_sync_state (
+    id BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+    last_trade_id BIGINT NOT NULL,
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+INSERT INTO lot_sync_state (last_trade_id)
+SELECT GREATEST(
+    (SELECT COALESCE(MAX(trade_id), 0) FROM trade_lots),
+    (SELECT COALESCE(MAX(sell_trade_id), 0) FROM lot_disposals))
+ON CONFLICT (id) DO NOTHING;
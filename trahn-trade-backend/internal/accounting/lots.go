@@ -0,0 +1,137 @@
+package accounting
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/kjannette/trahn-backend/internal/models"
+)
+
+// Lot selection methods for matching sells against open lots.
+const (
+	FIFO = "fifo" // oldest lot first
+	LIFO = "lifo" // newest lot first
+	HIFO = "hifo" // highest cost first (minimizes realized gains)
+)
+
+// ErrNoQuantity is returned (wrapped) by Dispose for a sell with nothing to
+// match, such as a zero-quantity record of a failed swap.
+var ErrNoQuantity = errors.New("sell has no quantity")
+
+// dust is the quantity below which a lot is treated as fully consumed.
+const dust = 1e-9
+
+func ValidateMethod(method string) error {
+	switch method {
+	case FIFO, LIFO, HIFO:
+		return nil
+	}
+	return fmt.Errorf("invalid cost basis method %q, expected fifo|lifo|hifo", method)
+}
+
+// Acquired returns the ETH a buy trade added and its cost per ETH: USD spent
+// plus gas, over ETH received after slippage.
+func Acquired(t models.Trade) (qty, costPerETH float64) {
+	qty = t.Quantity * (1 - valOr(t.SlippagePercent)/100)
+	if qty <= 0 {
+		return 0, 0
+	}
+	cost := t.USDValue + valOr(t.GasCostETH)*t.Price
+	return qty, cost / qty
+}
+
+// Proceeds returns the ETH a sell trade disposed of and the USD received
+// net of slippage and gas.
+func Proceeds(t models.Trade) (qty, proceedsUSD float64) {
+	usd := t.USDValue*(1-valOr(t.SlippagePercent)/100) - valOr(t.GasCostETH)*t.Price
+	return t.Quantity, usd
+}
+
+// OrderLots sorts lots into the order method consumes them.
+func OrderLots(lots []models.Lot, method string) {
+	sort.SliceStable(lots, func(i, j int) bool {
+		a, b := lots[i], lots[j]
+		switch method {
+		case LIFO:
+			if !a.AcquiredAt.Equal(b.AcquiredAt) {
+				return a.AcquiredAt.After(b.AcquiredAt)
+			}
+			return a.ID > b.ID
+		case HIFO:
+			if a.CostPerETH != b.CostPerETH {
+				return a.CostPerETH > b.CostPerETH
+			}
+		}
+		if !a.AcquiredAt.Equal(b.AcquiredAt) {
+			return a.AcquiredAt.Before(b.AcquiredAt)
+		}
+		return a.ID < b.ID
+	})
+}
+
+// Dispose matches a sell trade against open lots using method, reducing each
+// consumed lot's RemainingQuantity in place. Proceeds are allocated to the
+// disposals pro rata by quantity. Any quantity not covered by open lots is
+// returned as a final disposal with no lot and unknown cost basis.
+func Dispose(lots []models.Lot, sell models.Trade, method string) ([]models.LotDisposal, error) {
+	if err := ValidateMethod(method); err != nil {
+		return nil, err
+	}
+	qty, proceeds := Proceeds(sell)
+	if qty <= 0 {
+		return nil, fmt.Errorf("sell trade %d: %w", sell.ID, ErrNoQuantity)
+	}
+	OrderLots(lots, method)
+
+	var out []models.LotDisposal
+	left := qty
+	for i := range lots {
+		if left <= dust {
+			break
+		}
+		lot := &lots[i]
+		if lot.RemainingQuantity <= dust {
+			continue
+		}
+		take := min(left, lot.RemainingQuantity)
+		lot.RemainingQuantity -= take
+		if lot.RemainingQuantity < dust {
+			lot.RemainingQuantity = 0
+		}
+		left -= take
+
+		id, acquired := lot.ID, lot.AcquiredAt
+		basis := take * lot.CostPerETH
+		d := disposal(sell, method, take, proceeds*take/qty)
+		d.LotID = &id
+		d.AcquiredAt = &acquired
+		d.CostBasisUSD = &basis
+		gain := d.ProceedsUSD - basis
+		d.GainUSD = &gain
+		out = append(out, d)
+	}
+
+	if left > dust {
+		out = append(out, disposal(sell, method, left, proceeds*left/qty))
+	}
+	return out, nil
+}
+
+func disposal(sell models.Trade, method string, qty, proceeds float64) models.LotDisposal {
+	return models.LotDisposal{
+		SellTradeID:  sell.ID,
+		DisposedAt:   sell.Timestamp,
+		Quantity:     qty,
+		ProceedsUSD:  proceeds,
+		Method:       method,
+		IsPaperTrade: sell.IsPaperTrade,
+	}
+}
+
+func valOr(p *float64) float64 {
+	if p != nil {
+		return *p
+	}
+	return 0
+}
@@ -0,0 +1,115 @@
+package accounting
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/kjannette/trahn-backend/internal/models"
+)
+
+var t0 = time.Date(2024, 1, 15, 18, 0, 0, 0, time.UTC)
+
+func openLots() []models.Lot {
+	return []models.Lot{
+		{ID: 1, AcquiredAt: t0, Quantity: 1, RemainingQuantity: 1, CostPerETH: 2000},
+		{ID: 2, AcquiredAt: t0.Add(time.Hour), Quantity: 1, RemainingQuantity: 1, CostPerETH: 2200},
+		{ID: 3, AcquiredAt: t0.Add(2 * time.Hour), Quantity: 1, RemainingQuantity: 1, CostPerETH: 2100},
+	}
+}
+
+func sell(qty, usd float64) models.Trade {
+	return models.Trade{ID: 10, Timestamp: t0.Add(3 * time.Hour), Side: "sell", Price: usd / qty, Quantity: qty, USDValue: usd}
+}
+
+func TestDispose_Methods(t *testing.T) {
+	cases := []struct {
+		method string
+		lotIDs []int64
+		gain   float64
+	}{
+		{FIFO, []int64{1, 2}, 1.5*2300 - (2000 + 0.5*2200)},
+		{LIFO, []int64{3, 2}, 1.5*2300 - (2100 + 0.5*2200)},
+		{HIFO, []int64{2, 3}, 1.5*2300 - (2200 + 0.5*2100)},
+	}
+	for _, tc := range cases {
+		t.Run(tc.method, func(t *testing.T) {
+			lots := openLots()
+			out, err := Dispose(lots, sell(1.5, 1.5*2300), tc.method)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(out) != 2 {
+				t.Fatalf("expected 2 disposals, got %d", len(out))
+			}
+			var gain float64
+			for i, d := range out {
+				if d.LotID == nil || *d.LotID != tc.lotIDs[i] {
+					t.Fatalf("disposal %d: expected lot %d, got %v", i, tc.lotIDs[i], d.LotID)
+				}
+				gain += *d.GainUSD
+			}
+			if math.Abs(gain-tc.gain) > 1e-9 {
+				t.Fatalf("expected gain %.4f, got %.4f", tc.gain, gain)
+			}
+			if out[1].Quantity != 0.5 {
+				t.Fatalf("expected partial 0.5 from second lot, got %f", out[1].Quantity)
+			}
+		})
+	}
+}
+
+func TestDispose_ConsumesLots(t *testing.T) {
+	lots := openLots()
+	if _, err := Dispose(lots, sell(1.5, 3000), FIFO); err != nil {
+		t.Fatal(err)
+	}
+	// OrderLots sorted FIFO: lot 1 consumed, lot 2 half left.
+	if lots[0].RemainingQuantity != 0 || lots[1].RemainingQuantity != 0.5 || lots[2].RemainingQuantity != 1 {
+		t.Fatalf("unexpected remaining: %v, %v, %v",
+			lots[0].RemainingQuantity, lots[1].RemainingQuantity, lots[2].RemainingQuantity)
+	}
+}
+
+func TestDispose_Unmatched(t *testing.T) {
+	lots := []models.Lot{{ID: 1, AcquiredAt: t0, Quantity: 0.5, RemainingQuantity: 0.5, CostPerETH: 2000}}
+	out, err := Dispose(lots, sell(2, 4000), FIFO)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out) != 2 {
+		t.Fatalf("expected matched + unmatched disposal, got %d", len(out))
+	}
+	u := out[1]
+	if u.LotID != nil || u.CostBasisUSD != nil || u.GainUSD != nil {
+		t.Fatal("unmatched disposal should have no lot or basis")
+	}
+	if u.Quantity != 1.5 || u.ProceedsUSD != 3000 {
+		t.Fatalf("expected 1.5 ETH / $3000 unmatched, got %f / %f", u.Quantity, u.ProceedsUSD)
+	}
+
+	if _, err := Dispose(lots, sell(1, 2000), "avg"); err == nil {
+		t.Fatal("expected invalid method error")
+	}
+	if _, err := Dispose(lots, sell(0, 0), FIFO); !errors.Is(err, ErrNoQuantity) {
+		t.Fatalf("expected ErrNoQuantity for an empty sell, got %v", err)
+	}
+}
+
+func TestAcquiredAndProceeds(t *testing.T) {
+	slip, gas := 0.5, 0.005
+	buy := models.Trade{Side: "buy", Price: 2000, Quantity: 0.05, USDValue: 100, SlippagePercent: &slip, GasCostETH: &gas}
+
+	qty, cost := Acquired(buy)
+	wantQty := 0.05 * 0.995
+	if math.Abs(qty-wantQty) > 1e-12 || math.Abs(cost-110/wantQty) > 1e-9 {
+		t.Fatalf("unexpected lot: qty=%f cost=%f", qty, cost)
+	}
+
+	sellTrade := models.Trade{Side: "sell", Price: 2000, Quantity: 0.05, USDValue: 100, SlippagePercent: &slip, GasCostETH: &gas}
+	qty, usd := Proceeds(sellTrade)
+	if qty != 0.05 || math.Abs(usd-(99.5-10)) > 1e-9 {
+		t.Fatalf("unexpected proceeds: qty=%f usd=%f", qty, usd)
+	}
+}
@@ -0,0 +1,149 @@
+package accounting
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/kjannette/trahn-backend/internal/models"
+)
+
+// Holding periods. Disposals with no lot have an unknown holding period.
+const (
+	ShortTerm = "short"
+	LongTerm  = "long"
+	Unknown   = "unknown"
+)
+
+// HoldingPeriod classifies a disposal as short or long term. Long term
+// requires holding for more than one year.
+func HoldingPeriod(acquired *time.Time, disposed time.Time) string {
+	if acquired == nil {
+		return Unknown
+	}
+	if disposed.After(acquired.AddDate(1, 0, 0)) {
+		return LongTerm
+	}
+	return ShortTerm
+}
+
+// TaxYear returns the [from, to) range of a calendar tax year in UTC.
+func TaxYear(year int) (time.Time, time.Time) {
+	from := time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)
+	return from, from.AddDate(1, 0, 0)
+}
+
+// WriteGains writes gains in the named format: "csv" (detailed) or "8949".
+func WriteGains(w io.Writer, format string, gains []models.CapitalGain) error {
+	switch format {
+	case "", "csv":
+		return WriteGainsCSV(w, gains)
+	case "8949":
+		return WriteForm8949(w, gains)
+	}
+	return fmt.Errorf("invalid format %q, expected csv|8949", format)
+}
+
+// WriteGainsCSV writes one detailed row per lot disposal.
+func WriteGainsCSV(w io.Writer, gains []models.CapitalGain) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{
+		"description", "date_acquired", "date_sold", "quantity_eth",
+		"proceeds_usd", "cost_basis_usd", "gain_usd", "holding_period",
+		"fee_usd", "gas_eth", "method", "lot_id", "sell_trade_id", "mode",
+	})
+	for _, g := range gains {
+		mode := "live"
+		if g.IsPaperTrade {
+			mode = "paper"
+		}
+		lotID := ""
+		if g.LotID != nil {
+			lotID = fmt.Sprint(*g.LotID)
+		}
+		cw.Write([]string{
+			description(g.Quantity),
+			dateOr(g.AcquiredAt, "2006-01-02", ""),
+			g.DisposedAt.UTC().Format("2006-01-02"),
+			fmt.Sprintf("%.8f", g.Quantity),
+			usd(g.ProceedsUSD),
+			usdOr(g.CostBasisUSD),
+			usdOr(g.GainUSD),
+			HoldingPeriod(g.AcquiredAt, g.DisposedAt),
+			usd(g.BuyFeeUSD + g.SellFeeUSD),
+			fmt.Sprintf("%.8f", g.GasETH),
+			g.Method,
+			lotID,
+			fmt.Sprint(g.SellTradeID),
+			mode,
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteForm8949 writes Form 8949-style rows: Part I (short term) first, then
+// Part II (long term). Gas is already netted into proceeds and cost basis,
+// so no adjustment column is used. Disposals with no lot report an empty
+// basis and are listed under Part I with date acquired "UNKNOWN".
+func WriteForm8949(w io.Writer, gains []models.CapitalGain) error {
+	sorted := make([]models.CapitalGain, len(gains))
+	copy(sorted, gains)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		pi := HoldingPeriod(sorted[i].AcquiredAt, sorted[i].DisposedAt) == LongTerm
+		pj := HoldingPeriod(sorted[j].AcquiredAt, sorted[j].DisposedAt) == LongTerm
+		if pi != pj {
+			return !pi
+		}
+		return sorted[i].DisposedAt.Before(sorted[j].DisposedAt)
+	})
+
+	cw := csv.NewWriter(w)
+	cw.Write([]string{
+		"Part", "(a) Description of property", "(b) Date acquired", "(c) Date sold or disposed of",
+		"(d) Proceeds", "(e) Cost or other basis", "(f) Code", "(g) Amount of adjustment", "(h) Gain or (loss)",
+	})
+	for _, g := range sorted {
+		part := "I"
+		if HoldingPeriod(g.AcquiredAt, g.DisposedAt) == LongTerm {
+			part = "II"
+		}
+		cw.Write([]string{
+			part,
+			description(g.Quantity),
+			dateOr(g.AcquiredAt, "01/02/2006", "UNKNOWN"),
+			g.DisposedAt.UTC().Format("01/02/2006"),
+			usd(g.ProceedsUSD),
+			usdOr(g.CostBasisUSD),
+			"",
+			"",
+			usdOr(g.GainUSD),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func description(qty float64) string {
+	return fmt.Sprintf("%.8f ETH", qty)
+}
+
+func dateOr(t *time.Time, layout, fallback string) string {
+	if t == nil {
+		return fallback
+	}
+	return t.UTC().Format(layout)
+}
+
+func usd(v float64) string {
+	return fmt.Sprintf("%.2f", v)
+}
+
+func usdOr(v *float64) string {
+	if v == nil {
+		return ""
+	}
+	return usd(*v)
+}
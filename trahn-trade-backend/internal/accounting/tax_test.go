@@ -0,0 +1,92 @@
+package accounting
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+	"time"
+
+	"github.com/kjannette/trahn-backend/internal/models"
+)
+
+func gain(lotID int64, acquired time.Time, disposed time.Time, proceeds, basis float64) models.CapitalGain {
+	g := models.CapitalGain{
+		LotDisposal: models.LotDisposal{
+			LotID:       &lotID,
+			SellTradeID: 7,
+			AcquiredAt:  &acquired,
+			DisposedAt:  disposed,
+			Quantity:    0.05,
+			ProceedsUSD: proceeds,
+			Method:      FIFO,
+		},
+		BuyFeeUSD:  1,
+		SellFeeUSD: 2,
+		GasETH:     0.0015,
+	}
+	g.CostBasisUSD = &basis
+	gl := proceeds - basis
+	g.GainUSD = &gl
+	return g
+}
+
+func TestHoldingPeriod(t *testing.T) {
+	acq := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	if p := HoldingPeriod(&acq, acq.AddDate(1, 0, 0)); p != ShortTerm {
+		t.Fatalf("exactly one year should be short term, got %s", p)
+	}
+	if p := HoldingPeriod(&acq, acq.AddDate(1, 0, 1)); p != LongTerm {
+		t.Fatalf("more than one year should be long term, got %s", p)
+	}
+	if p := HoldingPeriod(nil, acq); p != Unknown {
+		t.Fatalf("missing acquisition should be unknown, got %s", p)
+	}
+}
+
+func TestWriteGainsCSV(t *testing.T) {
+	acq := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	gains := []models.CapitalGain{
+		gain(1, acq, acq.AddDate(0, 2, 0), 110, 100),
+		{LotDisposal: models.LotDisposal{SellTradeID: 8, DisposedAt: acq, Quantity: 0.1, ProceedsUSD: 200, Method: FIFO}},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteGainsCSV(&buf, gains); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("expected header + 2 rows, got %d", len(rows))
+	}
+	r := rows[1]
+	if r[1] != "2024-03-01" || r[2] != "2024-05-01" || r[6] != "10.00" || r[7] != ShortTerm || r[8] != "3.00" {
+		t.Fatalf("unexpected row: %v", r)
+	}
+	if rows[2][1] != "" || rows[2][5] != "" || rows[2][7] != Unknown {
+		t.Fatalf("unmatched row should have no acquisition or basis: %v", rows[2])
+	}
+}
+
+func TestWriteForm8949(t *testing.T) {
+	acq := time.Date(2023, 1, 10, 12, 0, 0, 0, time.UTC)
+	long := gain(1, acq, acq.AddDate(1, 1, 0), 150, 100)
+	short := gain(2, acq.AddDate(1, 0, 0), acq.AddDate(1, 2, 0), 90, 100)
+
+	var buf bytes.Buffer
+	if err := WriteForm8949(&buf, []models.CapitalGain{long, short}); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rows[1][0] != "I" || rows[2][0] != "II" {
+		t.Fatalf("expected short term (Part I) before long term (Part II), got %s, %s", rows[1][0], rows[2][0])
+	}
+	if rows[1][2] != "01/10/2024" || rows[1][8] != "-10.00" {
+		t.Fatalf("unexpected Part I row: %v", rows[1])
+	}
+}
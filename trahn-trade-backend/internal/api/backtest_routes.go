@@ -0,0 +1,43 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/kjannette/trahn-backend/internal/models"
+)
+
+func (s *Server) handleBacktestSweeps(w http.ResponseWriter, r *http.Request) {
+	limit := parseLimit(r, 50)
+
+	sweeps, err := s.btRepo.GetSweeps(r.Context(), limit)
+	if err != nil {
+		fmt.Printf("Error fetching backtest sweeps: %v\n", err)
+		writeError(w, http.StatusInternalServerError, "failed to fetch backtest sweeps")
+		return
+	}
+	if sweeps == nil {
+		sweeps = []models.BacktestSweep{}
+	}
+	writeJSON(w, http.StatusOK, sweeps)
+}
+
+func (s *Server) handleBacktestRuns(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || id <= 0 {
+		writeError(w, http.StatusBadRequest, "invalid sweep id")
+		return
+	}
+
+	runs, err := s.btRepo.GetRuns(r.Context(), id)
+	if err != nil {
+		fmt.Printf("Error fetching runs for sweep %d: %v\n", id, err)
+		writeError(w, http.StatusInternalServerError, "failed to fetch backtest runs")
+		return
+	}
+	if runs == nil {
+		runs = []models.BacktestRun{}
+	}
+	writeJSON(w, http.StatusOK, runs)
+}
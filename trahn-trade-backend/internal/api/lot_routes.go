@@ -0,0 +1,74 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/kjannette/trahn-backend/internal/models"
+)
+
+// handleLots lists cost basis lots with their realized gains.
+// ?status=open (default) returns lots with ETH remaining, ?status=all every lot.
+func (s *Server) handleLots(w http.ResponseWriter, r *http.Request) {
+	mode, err := parseTradeMode(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var openOnly bool
+	switch v := r.URL.Query().Get("status"); v {
+	case "", "open":
+		openOnly = true
+	case "all":
+	default:
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid status %q, expected open|all", v))
+		return
+	}
+
+	lots, err := s.lotRepo.GetLots(r.Context(), openOnly, parseLimit(r, 100), mode)
+	if err != nil {
+		fmt.Printf("Error fetching lots: %v\n", err)
+		writeError(w, http.StatusInternalServerError, "failed to fetch lots")
+		return
+	}
+	if lots == nil {
+		lots = []models.Lot{}
+	}
+	writeJSON(w, http.StatusOK, lots)
+}
+
+func (s *Server) handleLotDisposals(w http.ResponseWriter, r *http.Request) {
+	mode, err := parseTradeMode(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	disposals, err := s.lotRepo.GetDisposals(r.Context(), parseLimit(r, 100), mode)
+	if err != nil {
+		fmt.Printf("Error fetching lot disposals: %v\n", err)
+		writeError(w, http.StatusInternalServerError, "failed to fetch lot disposals")
+		return
+	}
+	if disposals == nil {
+		disposals = []models.LotDisposal{}
+	}
+	writeJSON(w, http.StatusOK, disposals)
+}
+
+func (s *Server) handleLotSummary(w http.ResponseWriter, r *http.Request) {
+	mode, err := parseTradeMode(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	summary, err := s.lotRepo.GetSummary(r.Context(), mode)
+	if err != nil {
+		fmt.Printf("Error fetching lot summary: %v\n", err)
+		writeError(w, http.StatusInternalServerError, "failed to fetch lot summary")
+		return
+	}
+	writeJSON(w, http.StatusOK, summary)
+}
@@ -3,6 +3,7 @@ package api
 import (
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/kjannette/trahn-backend/internal/repository"
 )
@@ -77,3 +78,73 @@ func (s *Server) handleLatestPrice(w http.ResponseWriter, r *http.Request) {
 	}
 	writeJSON(w, http.StatusOK, priceJSON{T: price.Timestamp.UnixMilli(), P: price.Price})
 }
+
+// defaultCandles is how many candles are returned when from is omitted.
+const defaultCandles = 200
+
+type candleJSON struct {
+	T int64   `json:"t"`
+	O float64 `json:"o"`
+	H float64 `json:"h"`
+	L float64 `json:"l"`
+	C float64 `json:"c"`
+	V int     `json:"v"` // price samples in the bucket
+}
+
+// handleCandles serves OHLC candles for ?interval=1m|5m|1h|1d (default 1h)
+// between ?from= and ?to= (RFC 3339 or YYYY-MM-DD, to exclusive). to
+// defaults to now and from to 200 candles before it. The bot builds candles
+// from price_history on every tick; this handler only reads them.
+func (s *Server) handleCandles(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	interval := q.Get("interval")
+	if interval == "" {
+		interval = "1h"
+	}
+	width, ok := repository.CandleResolution(interval)
+	if !ok {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid interval %q, expected 1m|5m|1h|1d", interval))
+		return
+	}
+
+	to := time.Now()
+	if v := q.Get("to"); v != "" {
+		t, ok := parseTimeParam(v)
+		if !ok {
+			writeError(w, http.StatusBadRequest, "invalid to, expected RFC 3339 or YYYY-MM-DD")
+			return
+		}
+		to = t
+	}
+	from := to.Add(-defaultCandles * width)
+	if v := q.Get("from"); v != "" {
+		t, ok := parseTimeParam(v)
+		if !ok {
+			writeError(w, http.StatusBadRequest, "invalid from, expected RFC 3339 or YYYY-MM-DD")
+			return
+		}
+		from = t
+	}
+	if !from.Before(to) {
+		writeError(w, http.StatusBadRequest, "from must be before to")
+		return
+	}
+	if to.Sub(from)/width > maxQueryLimit {
+		writeError(w, http.StatusBadRequest,
+			fmt.Sprintf("range spans more than %d %s candles, use a wider interval", maxQueryLimit, interval))
+		return
+	}
+
+	candles, err := s.candleRepo.GetRange(r.Context(), interval, from.Truncate(width), to)
+	if err != nil {
+		fmt.Printf("Error fetching %s candles: %v\n", interval, err)
+		writeError(w, http.StatusInternalServerError, "failed to fetch candles")
+		return
+	}
+
+	out := make([]candleJSON, len(candles))
+	for i, c := range candles {
+		out[i] = candleJSON{T: c.Bucket.UnixMilli(), O: c.Open, H: c.High, L: c.Low, C: c.Close, V: c.Ticks}
+	}
+	writeJSON(w, http.StatusOK, out)
+}
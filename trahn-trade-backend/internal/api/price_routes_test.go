@@ -0,0 +1,36 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandleCandles_BadRequests(t *testing.T) {
+	s := &Server{}
+	cases := []string{
+		"/v1/prices/candles?interval=15m",
+		"/v1/prices/candles?from=yesterday",
+		"/v1/prices/candles?from=2025-02-01&to=2025-01-01",
+		"/v1/prices/candles?interval=1m&from=2025-01-01&to=2025-02-01",
+	}
+	for _, url := range cases {
+		rr := httptest.NewRecorder()
+		s.handleCandles(rr, httptest.NewRequest(http.MethodGet, url, nil))
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", url, rr.Code)
+		}
+	}
+}
+
+func TestParseTimeParam(t *testing.T) {
+	if ts, ok := parseTimeParam("2025-03-01"); !ok || ts.Hour() != 0 || ts.Day() != 1 {
+		t.Errorf("date: got %v, %v", ts, ok)
+	}
+	if ts, ok := parseTimeParam("2025-03-01T17:30:00Z"); !ok || ts.Hour() != 17 {
+		t.Errorf("RFC 3339: got %v, %v", ts, ok)
+	}
+	if _, ok := parseTimeParam("1740787200"); ok {
+		t.Error("expected unix seconds to be rejected")
+	}
+}
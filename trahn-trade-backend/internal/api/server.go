@@ -21,21 +21,31 @@ var dateRegexp = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
 type Server struct {
 	pool       *pgxpool.Pool
 	priceRepo  *repository.PriceRepo
+	candleRepo *repository.CandleRepo
 	tradeRepo  *repository.TradeRepo
 	srRepo     *repository.SRRepo
 	gridRepo   *repository.GridStateRepo
+	btRepo     *repository.BacktestRepo
+	lotRepo    *repository.LotRepo
 	httpServer *http.Server
 	apiKey     string
+
+	costBasisMethod string // lot matching for syncs before tax exports
 }
 
-func NewServer(pool *pgxpool.Pool, port int, apiKey, corsOrigin string) *Server {
+func NewServer(pool *pgxpool.Pool, port int, apiKey, corsOrigin, costBasisMethod string) *Server {
 	s := &Server{
-		pool:      pool,
-		priceRepo: repository.NewPriceRepo(pool),
-		tradeRepo: repository.NewTradeRepo(pool),
-		srRepo:    repository.NewSRRepo(pool),
-		gridRepo:  repository.NewGridStateRepo(pool),
-		apiKey:    apiKey,
+		pool:       pool,
+		priceRepo:  repository.NewPriceRepo(pool),
+		candleRepo: repository.NewCandleRepo(pool),
+		tradeRepo:  repository.NewTradeRepo(pool),
+		srRepo:     repository.NewSRRepo(pool),
+		gridRepo:   repository.NewGridStateRepo(pool),
+		btRepo:     repository.NewBacktestRepo(pool),
+		lotRepo:    repository.NewLotRepo(pool),
+		apiKey:     apiKey,
+
+		costBasisMethod: costBasisMethod,
 	}
 
 	mux := http.NewServeMux()
@@ -45,12 +55,19 @@ func NewServer(pool *pgxpool.Pool, port int, apiKey, corsOrigin string) *Server
 	mux.HandleFunc("GET /v1/prices/day/{date}", s.handlePricesByDay)
 	mux.HandleFunc("GET /v1/prices/days", s.handleAvailableDays)
 	mux.HandleFunc("GET /v1/prices/latest", s.handleLatestPrice)
+	mux.HandleFunc("GET /v1/prices/candles", s.handleCandles)
 
 	// Trade routes
 	mux.HandleFunc("GET /v1/trades/today", s.handleTradesToday)
 	mux.HandleFunc("GET /v1/trades/day/{date}", s.handleTradesByDay)
 	mux.HandleFunc("GET /v1/trades/all", s.handleAllTrades)
 	mux.HandleFunc("GET /v1/trades/stats", s.handleTradeStats)
+	mux.HandleFunc("GET /v1/trades/tax-export", s.handleTaxExport)
+
+	// Cost basis lot routes
+	mux.HandleFunc("GET /v1/lots", s.handleLots)
+	mux.HandleFunc("GET /v1/lots/disposals", s.handleLotDisposals)
+	mux.HandleFunc("GET /v1/lots/summary", s.handleLotSummary)
 
 	// Grid routes
 	mux.HandleFunc("GET /v1/grid/current", s.handleGridCurrent)
@@ -59,6 +76,10 @@ func NewServer(pool *pgxpool.Pool, port int, apiKey, corsOrigin string) *Server
 	mux.HandleFunc("GET /v1/support-resistance/latest", s.handleSRLatest)
 	mux.HandleFunc("GET /v1/support-resistance/history", s.handleSRHistory)
 
+	// Backtest routes
+	mux.HandleFunc("GET /v1/backtests/sweeps", s.handleBacktestSweeps)
+	mux.HandleFunc("GET /v1/backtests/sweeps/{id}/runs", s.handleBacktestRuns)
+
 	// Health check (no auth required)
 	mux.HandleFunc("GET /health", s.handleHealth)
 
@@ -141,6 +162,17 @@ func validateDate(date string) bool {
 	return err == nil
 }
 
+// parseTimeParam accepts an RFC 3339 timestamp or a YYYY-MM-DD date
+// (midnight UTC).
+func parseTimeParam(v string) (time.Time, bool) {
+	if validateDate(v) {
+		t, _ := time.Parse("2006-01-02", v)
+		return t, true
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	return t, err == nil
+}
+
 func parseLimit(r *http.Request, defaultLimit int) int {
 	v := r.URL.Query().Get("limit")
 	if v == "" {
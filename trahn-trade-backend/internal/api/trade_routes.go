@@ -1,9 +1,13 @@
 package api
 
 import (
+	"bytes"
 	"fmt"
 	"net/http"
+	"strconv"
+	"time"
 
+	"github.com/kjannette/trahn-backend/internal/accounting"
 	"github.com/kjannette/trahn-backend/internal/repository"
 )
 
@@ -130,3 +134,73 @@ func (s *Server) handleTradeStats(w http.ResponseWriter, r *http.Request) {
 	}
 	writeJSON(w, http.StatusOK, stats)
 }
+
+// handleTaxExport serves realized capital gains as CSV for ?year=YYYY or
+// ?from=YYYY-MM-DD&to=YYYY-MM-DD (to exclusive). ?format=csv (default) gives
+// detailed rows, ?format=8949 Form 8949-style rows. Defaults to live trades.
+// Lots are synced first, as in the CLI, so trades the bot has not yet
+// applied are included.
+func (s *Server) handleTaxExport(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	var from, to time.Time
+	if y := q.Get("year"); y != "" {
+		year, err := strconv.Atoi(y)
+		if err != nil || year < 2000 || year > 9999 {
+			writeError(w, http.StatusBadRequest, "invalid year")
+			return
+		}
+		from, to = accounting.TaxYear(year)
+	} else {
+		if !validateDate(q.Get("from")) || !validateDate(q.Get("to")) {
+			writeError(w, http.StatusBadRequest, "year or from/to (YYYY-MM-DD) is required")
+			return
+		}
+		from, _ = time.Parse("2006-01-02", q.Get("from"))
+		to, _ = time.Parse("2006-01-02", q.Get("to"))
+		if !from.Before(to) {
+			writeError(w, http.StatusBadRequest, "from must be before to")
+			return
+		}
+	}
+
+	mode, err := parseTradeMode(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if q.Get("mode") == "" {
+		live := false
+		mode = &live
+	}
+
+	if _, err := s.lotRepo.Sync(r.Context(), s.costBasisMethod); err != nil {
+		fmt.Printf("Error syncing cost basis lots: %v\n", err)
+		writeError(w, http.StatusInternalServerError, "failed to sync cost basis lots")
+		return
+	}
+
+	gains, err := s.lotRepo.GetCapitalGains(r.Context(), from, to, mode)
+	if err != nil {
+		fmt.Printf("Error fetching capital gains: %v\n", err)
+		writeError(w, http.StatusInternalServerError, "failed to fetch capital gains")
+		return
+	}
+
+	var buf bytes.Buffer
+	format := q.Get("format")
+	if err := accounting.WriteGains(&buf, format, gains); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if format == "" {
+		format = "csv"
+	}
+
+	filename := fmt.Sprintf("capital-gains-%s-%s-%s.csv",
+		format, from.Format("20060102"), to.Format("20060102"))
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+}
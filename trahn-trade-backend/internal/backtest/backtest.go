@@ -0,0 +1,356 @@
+package backtest
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/kjannette/trahn-backend/internal/bot"
+	"github.com/kjannette/trahn-backend/internal/config"
+	"github.com/kjannette/trahn-backend/internal/levels"
+	"github.com/kjannette/trahn-backend/internal/models"
+	"github.com/kjannette/trahn-backend/internal/strategy"
+)
+
+// PriceSource is the subset of PriceRepo the backtester needs, so runs can
+// be driven from the database or from an in-memory series in tests.
+type PriceSource interface {
+	GetRange(ctx context.Context, from, to time.Time) ([]models.PricePoint, error)
+}
+
+// Config describes a single backtest run.
+type Config struct {
+	// Grid
+	GridLevels         int
+	GridSpacingPercent float64
+	AmountPerGrid      float64
+	CenterPrice        float64 // 0 = S/R midpoint, or first price if SRMethod is unset
+
+	// Support/Resistance: when SRMethod is set, the first SRLookback of the
+	// series is a warm-up window used only to center the grid.
+	SRMethod   string
+	SRLookback time.Duration
+
+	// Timing
+	PostTradeCooldown time.Duration
+
+	// Simulated wallet
+	InitialETH      float64
+	InitialUSDC     float64
+	SlippagePercent float64 // max random slippage per fill
+	GasCostETH      float64 // flat gas charge per fill
+	Seed            int64   // slippage RNG seed, for reproducible runs
+}
+
+// FromConfig builds a backtest config from the bot's runtime configuration:
+// levels, fixed spacing, amount per grid, center, S/R method, cooldown and
+// the paper wallet. The backtest always lays out a centered grid with
+// GRID_SPACING_PERCENT between levels and AMOUNT_PER_GRID on each, so
+// spacing modes, bounded mode, layouts, ladders and sizing modes are not
+// replayed.
+func FromConfig(c *config.Config) Config {
+	return Config{
+		GridLevels:         c.GridLevels,
+		GridSpacingPercent: c.GridSpacingPercent,
+		AmountPerGrid:      c.AmountPerGrid,
+		CenterPrice:        c.GridBasePrice,
+		SRMethod:           SRMethod(c.SRMethod),
+		SRLookback:         time.Duration(c.SRLookbackDays) * 24 * time.Hour,
+		PostTradeCooldown:  time.Duration(c.PostTradeCooldownSeconds) * time.Second,
+		InitialETH:         c.PaperInitialETH,
+		InitialUSDC:        c.PaperInitialUSDC,
+		SlippagePercent:    c.PaperSlippagePercent,
+		GasCostETH:         bot.DefaultPaperGasCost,
+		Seed:               1,
+	}
+}
+
+// SRMethod returns the S/R method a backtest uses for an SR_METHOD.
+// volume-profile needs DEX trade volume, which price_history does not have,
+// so it is replayed as percentile, the closest range over prices alone.
+// Every other method is used as is.
+func SRMethod(method string) string {
+	if method == "volume-profile" {
+		return "percentile"
+	}
+	return method
+}
+
+type Trade struct {
+	Timestamp      time.Time `json:"timestamp"`
+	Side           string    `json:"side"`
+	GridLevel      int       `json:"gridLevel"`
+	TriggerPrice   float64   `json:"triggerPrice"`
+	ExecutionPrice float64   `json:"executionPrice"`
+	ETHAmount      float64   `json:"ethAmount"`
+	USDCAmount     float64   `json:"usdcAmount"`
+	SlippagePct    float64   `json:"slippagePercent"`
+	GasCost        float64   `json:"gasCost"`
+	RealizedPnL    *float64  `json:"realizedPnl,omitempty"`
+}
+
+type EquityPoint struct {
+	Timestamp time.Time `json:"timestamp"`
+	Price     float64   `json:"price"`
+	ETH       float64   `json:"eth"`
+	USDC      float64   `json:"usdc"`
+	ValueUSD  float64   `json:"valueUsd"`
+}
+
+type Summary struct {
+	Start            time.Time `json:"start"`
+	End              time.Time `json:"end"`
+	Ticks            int       `json:"ticks"`
+	InitialValueUSD  float64   `json:"initialValueUsd"`
+	FinalValueUSD    float64   `json:"finalValueUsd"`
+	NetPnL           float64   `json:"netPnl"`
+	NetPnLPct        float64   `json:"netPnlPercent"`
+	BuyAndHoldPnLPct float64   `json:"buyAndHoldPnlPercent"`
+	MaxDrawdownPct   float64   `json:"maxDrawdownPercent"`
+	TotalTrades      int       `json:"totalTrades"`
+	BuyTrades        int       `json:"buyTrades"`
+	SellTrades       int       `json:"sellTrades"`
+	FailedFills      int       `json:"failedFills"`
+	GasSpentETH      float64   `json:"gasSpentEth"`
+	GasSpentUSD      float64   `json:"gasSpentUsd"`
+	RealizedPnL      float64   `json:"realizedPnl"`
+	RoundTrips       int       `json:"roundTrips"`
+}
+
+type Result struct {
+	Config  Config               `json:"config"`
+	Grid    []strategy.GridLevel `json:"grid"`
+	Trades  []Trade              `json:"trades"`
+	Equity  []EquityPoint        `json:"equity"`
+	Summary Summary              `json:"summary"`
+}
+
+// RunRange loads [from, to) from src and replays it through Run. When an
+// S/R method is configured the S/R warm-up window is loaded from before
+// from, so trading still starts at from.
+func RunRange(ctx context.Context, src PriceSource, from, to time.Time, cfg Config) (*Result, error) {
+	prices, err := LoadRange(ctx, src, from, to, cfg.warmup())
+	if err != nil {
+		return nil, err
+	}
+	return Run(ctx, prices, cfg)
+}
+
+// LoadRange fetches [from, to) plus warmup of leading history.
+func LoadRange(ctx context.Context, src PriceSource, from, to time.Time, warmup time.Duration) ([]models.PricePoint, error) {
+	prices, err := src.GetRange(ctx, from.Add(-warmup), to)
+	if err != nil {
+		return nil, fmt.Errorf("load prices: %w", err)
+	}
+	return prices, nil
+}
+
+// warmup is how much history before the traded window the run consumes.
+func (c Config) warmup() time.Duration {
+	if c.SRMethod == "" {
+		return 0
+	}
+	return c.SRLookback
+}
+
+// Run replays prices (oldest first) through the same grid logic the live bot
+// uses: one triggered level per tick, opposite level re-armed after each fill,
+// and a cooldown after every successful trade. Fills go through an in-memory
+// PaperWallet so slippage and gas match paper trading.
+func Run(ctx context.Context, prices []models.PricePoint, cfg Config) (*Result, error) {
+	prices, center, err := splitWarmup(prices, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if len(prices) < 2 {
+		return nil, fmt.Errorf("need at least 2 price points, got %d", len(prices))
+	}
+	if center <= 0 {
+		center = prices[0].Price
+	}
+
+	grid, err := strategy.CalculateGridLevels(strategy.GridParams{
+		CenterPrice:    center,
+		LevelCount:     cfg.GridLevels,
+		SpacingPercent: cfg.GridSpacingPercent,
+		AmountPerGrid:  cfg.AmountPerGrid,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("calculate grid: %w", err)
+	}
+
+	wallet := bot.NewPaperWallet(nil, cfg.InitialETH, cfg.InitialUSDC)
+	rng := rand.New(rand.NewSource(cfg.Seed))
+
+	res := &Result{
+		Config: cfg,
+		Equity: make([]EquityPoint, 0, len(prices)),
+	}
+	var failed int
+	var cooldownUntil time.Time
+
+	for _, p := range prices {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		if !p.Timestamp.Before(cooldownUntil) {
+			if level := strategy.FindTriggeredLevel(p.Price, grid); level != nil {
+				t, err := fill(ctx, wallet, rng, cfg, level, p)
+				if err != nil {
+					failed++
+				} else {
+					if profit, ok := strategy.SettleFill(grid, level, t.ETHAmount, t.USDCAmount, t.GasCost*p.Price); ok {
+						t.RealizedPnL = &profit
+					}
+					level.Filled = true
+					ts := p.Timestamp
+					level.FilledAt = &ts
+					strategy.ResetOppositeLevel(grid, level)
+					res.Trades = append(res.Trades, t)
+					cooldownUntil = p.Timestamp.Add(cfg.PostTradeCooldown)
+				}
+			}
+		}
+
+		res.Equity = append(res.Equity, EquityPoint{
+			Timestamp: p.Timestamp,
+			Price:     p.Price,
+			ETH:       wallet.ETHBalance,
+			USDC:      wallet.USDCBalance,
+			ValueUSD:  wallet.ETHBalance*p.Price + wallet.USDCBalance,
+		})
+	}
+
+	res.Grid = grid
+	res.Summary = summarize(res, prices, wallet, failed)
+	return res, nil
+}
+
+// splitWarmup separates the S/R warm-up window from the traded series and
+// returns the grid center it implies. A configured CenterPrice always wins.
+func splitWarmup(prices []models.PricePoint, cfg Config) ([]models.PricePoint, float64, error) {
+	if cfg.SRMethod == "" || len(prices) == 0 {
+		return prices, cfg.CenterPrice, nil
+	}
+
+	cutoff := prices[0].Timestamp.Add(cfg.SRLookback)
+	i := 0
+	for i < len(prices) && prices[i].Timestamp.Before(cutoff) {
+		i++
+	}
+	if i == 0 {
+		return nil, 0, fmt.Errorf("S/R method %q needs a warm-up window (SRLookback > 0)", cfg.SRMethod)
+	}
+
+	mid, err := warmupMidpoint(prices[:i], cfg.SRMethod, cutoff)
+	if err != nil {
+		return nil, 0, fmt.Errorf("warm-up S/R: %w", err)
+	}
+	center := cfg.CenterPrice
+	if center <= 0 {
+		center = mid
+	}
+	return prices[i:], center, nil
+}
+
+// warmupMidpoint is the S/R midpoint of the warm-up window as of now. simple
+// and percentile run over every price; pivot, pivot-fib and fractal run over
+// candles built from the window by the live providers' code.
+func warmupMidpoint(warmup []models.PricePoint, method string, now time.Time) (float64, error) {
+	if method == "simple" || method == "percentile" {
+		closes := make([]float64, len(warmup))
+		for i, p := range warmup {
+			closes[i] = p.Price
+		}
+		sr, err := strategy.CalculateSR(closes, method)
+		if err != nil {
+			return 0, err
+		}
+		return sr.Midpoint, nil
+	}
+	sr, err := levels.FromPrices(warmup, method, now)
+	if err != nil {
+		return 0, err
+	}
+	return sr.Midpoint, nil
+}
+
+func fill(ctx context.Context, wallet *bot.PaperWallet, rng *rand.Rand, cfg Config, level *strategy.GridLevel, p models.PricePoint) (Trade, error) {
+	ethAmount := level.Quantity
+	usdcAmount := ethAmount * p.Price
+	slip := rng.Float64() * cfg.SlippagePercent / 100
+
+	f, err := wallet.Fill(ctx, level.Side, ethAmount, usdcAmount, slip, cfg.GasCostETH)
+	if err != nil {
+		return Trade{}, err
+	}
+	return Trade{
+		Timestamp:      p.Timestamp,
+		Side:           level.Side,
+		GridLevel:      level.Index,
+		TriggerPrice:   level.Price,
+		ExecutionPrice: p.Price,
+		ETHAmount:      f.ETHAmount,
+		USDCAmount:     f.USDCAmount,
+		SlippagePct:    f.SlippagePct,
+		GasCost:        f.GasCost,
+	}, nil
+}
+
+func summarize(res *Result, prices []models.PricePoint, wallet *bot.PaperWallet, failed int) Summary {
+	first, last := prices[0], prices[len(prices)-1]
+	cfg := res.Config
+
+	initialVal := cfg.InitialETH*first.Price + cfg.InitialUSDC
+	finalVal := wallet.ETHBalance*last.Price + wallet.USDCBalance
+	holdVal := cfg.InitialETH*last.Price + cfg.InitialUSDC
+
+	s := Summary{
+		Start:           first.Timestamp,
+		End:             last.Timestamp,
+		Ticks:           len(prices),
+		InitialValueUSD: initialVal,
+		FinalValueUSD:   finalVal,
+		NetPnL:          finalVal - initialVal,
+		MaxDrawdownPct:  maxDrawdownPct(res.Equity),
+		TotalTrades:     len(res.Trades),
+		FailedFills:     failed,
+		GasSpentETH:     wallet.TotalGas,
+		GasSpentUSD:     wallet.TotalGas * last.Price,
+	}
+	if initialVal > 0 {
+		s.NetPnLPct = s.NetPnL / initialVal * 100
+		s.BuyAndHoldPnLPct = (holdVal - initialVal) / initialVal * 100
+	}
+	for _, t := range res.Trades {
+		if t.Side == "buy" {
+			s.BuyTrades++
+		} else {
+			s.SellTrades++
+		}
+		if t.RealizedPnL != nil {
+			s.RealizedPnL += *t.RealizedPnL
+			s.RoundTrips++
+		}
+	}
+	return s
+}
+
+// maxDrawdownPct returns the largest peak-to-trough decline in portfolio
+// value, as a positive percentage.
+func maxDrawdownPct(equity []EquityPoint) float64 {
+	var peak, worst float64
+	for _, e := range equity {
+		if e.ValueUSD > peak {
+			peak = e.ValueUSD
+		}
+		if peak > 0 {
+			if dd := (peak - e.ValueUSD) / peak * 100; dd > worst {
+				worst = dd
+			}
+		}
+	}
+	return worst
+}
@@ -0,0 +1,187 @@
+package backtest
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/kjannette/trahn-backend/internal/models"
+)
+
+// series builds one price point per minute starting at a fixed time.
+func series(prices ...float64) []models.PricePoint {
+	start := time.Date(2024, 1, 15, 18, 0, 0, 0, time.UTC)
+	out := make([]models.PricePoint, len(prices))
+	for i, p := range prices {
+		out[i] = models.PricePoint{Timestamp: start.Add(time.Duration(i) * time.Minute), Price: p}
+	}
+	return out
+}
+
+func testConfig() Config {
+	return Config{
+		GridLevels:         4,
+		GridSpacingPercent: 2,
+		AmountPerGrid:      100,
+		InitialETH:         1,
+		InitialUSDC:        1000,
+		GasCostETH:         0.001,
+		Seed:               1,
+	}
+}
+
+func TestRun_RoundTrip(t *testing.T) {
+	// Grid around 2000: buys ~1922/1961, sells ~2040/2081.
+	prices := series(2000, 1955, 2000, 2045, 2000)
+
+	res, err := Run(context.Background(), prices, testConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.Summary.BuyTrades != 1 || res.Summary.SellTrades != 1 {
+		t.Fatalf("expected 1 buy + 1 sell, got %d buys + %d sells",
+			res.Summary.BuyTrades, res.Summary.SellTrades)
+	}
+	if res.Trades[0].Side != "buy" || res.Trades[1].Side != "sell" {
+		t.Fatalf("unexpected trade order: %s, %s", res.Trades[0].Side, res.Trades[1].Side)
+	}
+	if len(res.Equity) != len(prices) {
+		t.Fatalf("expected %d equity points, got %d", len(prices), len(res.Equity))
+	}
+	if math.Abs(res.Summary.GasSpentETH-0.002) > 1e-12 {
+		t.Fatalf("expected 0.002 ETH gas, got %f", res.Summary.GasSpentETH)
+	}
+	if res.Summary.RoundTrips != 1 || res.Trades[1].RealizedPnL == nil {
+		t.Fatalf("expected the sell to close one round trip, got %d", res.Summary.RoundTrips)
+	}
+	// No slippage: buy q1 @ 1955 with gas, sell q2 @ 2045 with gas.
+	q1, q2 := 100/(2000/1.02), 100/(2000*1.02)
+	basis := (q1*1955 + 0.001*1955) / q1
+	want := q2*2045 - 0.001*2045 - q2*basis
+	if math.Abs(res.Summary.RealizedPnL-want) > 1e-9 {
+		t.Fatalf("expected realized $%.4f, got $%.4f", want, res.Summary.RealizedPnL)
+	}
+	t.Logf("Net P&L: $%.2f (%.3f%%), max DD %.3f%%",
+		res.Summary.NetPnL, res.Summary.NetPnLPct, res.Summary.MaxDrawdownPct)
+}
+
+func TestRun_BuyReArmsSellLevel(t *testing.T) {
+	// Sell first (inventory), then buy back the level below — the sell
+	// should be re-armed and trigger again on the next rally.
+	prices := series(2000, 2045, 2000, 1955, 2000, 2045)
+
+	res, err := Run(context.Background(), prices, testConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Summary.SellTrades != 2 {
+		t.Fatalf("expected sell level to trade twice, got %d sells", res.Summary.SellTrades)
+	}
+}
+
+func TestRun_Cooldown(t *testing.T) {
+	cfg := testConfig()
+	cfg.PostTradeCooldown = 10 * time.Minute
+
+	// Second buy level is crossed one minute after the first fill.
+	prices := series(2000, 1955, 1915, 1915)
+
+	res, err := Run(context.Background(), prices, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Summary.TotalTrades != 1 {
+		t.Fatalf("expected cooldown to suppress second fill, got %d trades", res.Summary.TotalTrades)
+	}
+}
+
+func TestRun_InsufficientFunds(t *testing.T) {
+	cfg := testConfig()
+	cfg.InitialUSDC = 10
+
+	res, err := Run(context.Background(), series(2000, 1955), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Summary.TotalTrades != 0 || res.Summary.FailedFills != 1 {
+		t.Fatalf("expected 1 failed fill, got trades=%d failed=%d",
+			res.Summary.TotalTrades, res.Summary.FailedFills)
+	}
+}
+
+func TestRun_Validation(t *testing.T) {
+	if _, err := Run(context.Background(), series(2000), testConfig()); err == nil {
+		t.Fatal("expected error for single price point")
+	}
+
+	cfg := testConfig()
+	cfg.GridLevels = 1
+	if _, err := Run(context.Background(), series(2000, 2001), cfg); err == nil {
+		t.Fatal("expected grid validation error")
+	}
+}
+
+func TestMaxDrawdownPct(t *testing.T) {
+	eq := []EquityPoint{{ValueUSD: 100}, {ValueUSD: 120}, {ValueUSD: 90}, {ValueUSD: 130}, {ValueUSD: 117}}
+	got := maxDrawdownPct(eq)
+	if math.Abs(got-25) > 1e-9 {
+		t.Fatalf("expected 25%% drawdown, got %.4f", got)
+	}
+}
+
+func TestRun_SRWarmup(t *testing.T) {
+	cfg := testConfig()
+	cfg.SRMethod = "simple"
+	cfg.SRLookback = 3 * time.Minute
+
+	// Warm-up range 1900..2100 centers the grid at 2000; the first traded
+	// tick is the fourth point.
+	prices := series(1900, 2100, 2000, 2000, 1955)
+
+	res, err := Run(context.Background(), prices, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Equity) != 2 {
+		t.Fatalf("expected 2 traded ticks after warm-up, got %d", len(res.Equity))
+	}
+	if res.Summary.BuyTrades != 1 {
+		t.Fatalf("expected 1 buy at 1955 around center 2000, got %d", res.Summary.BuyTrades)
+	}
+
+	cfg.SRLookback = 0
+	if _, err := Run(context.Background(), prices, cfg); err == nil {
+		t.Fatal("expected error when S/R method has no warm-up window")
+	}
+}
+
+func TestRun_SRWarmupPivot(t *testing.T) {
+	cfg := testConfig()
+	cfg.SRMethod = "pivot"
+	cfg.SRLookback = 48 * time.Hour
+
+	// One complete day in the warm-up, H 2100, L 1900, C 2000: pivot 2000.
+	day := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	prices := []models.PricePoint{
+		{Timestamp: day.Add(-time.Hour), Price: 2500}, // partial day before
+		{Timestamp: day.Add(time.Hour), Price: 2100},
+		{Timestamp: day.Add(2 * time.Hour), Price: 1900},
+		{Timestamp: day.Add(23 * time.Hour), Price: 2000},
+		{Timestamp: day.Add(47 * time.Hour), Price: 2000},
+		{Timestamp: day.Add(48 * time.Hour), Price: 1955},
+	}
+
+	res, err := Run(context.Background(), prices, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Summary.BuyTrades != 1 {
+		t.Fatalf("expected 1 buy at 1955 around pivot 2000, got %d", res.Summary.BuyTrades)
+	}
+
+	if m := SRMethod("volume-profile"); m != "percentile" {
+		t.Fatalf("expected volume-profile to run as percentile, got %q", m)
+	}
+}
@@ -0,0 +1,217 @@
+package backtest
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/kjannette/trahn-backend/internal/models"
+)
+
+// SweepSpec lists the values to try for each swept parameter. An empty list
+// keeps the Base value for that parameter.
+type SweepSpec struct {
+	Base               Config
+	GridLevels         []int
+	GridSpacingPercent []float64
+	AmountPerGrid      []float64
+	PostTradeCooldown  []time.Duration
+	SRMethods          []string
+
+	Workers int    // 0 = one per CPU
+	RankBy  string // "pnl" (default), "drawdown", "trades" or "gas"
+}
+
+// SweepRun is one ranked combination. Err is set when the run could not be
+// completed (e.g. an invalid grid), in which case Summary is zero.
+type SweepRun struct {
+	Rank    int     `json:"rank"`
+	Params  Config  `json:"params"`
+	Summary Summary `json:"summary"`
+	Err     string  `json:"error,omitempty"`
+}
+
+// Combinations expands the spec into one Config per parameter combination.
+func (s SweepSpec) Combinations() []Config {
+	levels := s.GridLevels
+	if len(levels) == 0 {
+		levels = []int{s.Base.GridLevels}
+	}
+	spacings := s.GridSpacingPercent
+	if len(spacings) == 0 {
+		spacings = []float64{s.Base.GridSpacingPercent}
+	}
+	amounts := s.AmountPerGrid
+	if len(amounts) == 0 {
+		amounts = []float64{s.Base.AmountPerGrid}
+	}
+	cooldowns := s.PostTradeCooldown
+	if len(cooldowns) == 0 {
+		cooldowns = []time.Duration{s.Base.PostTradeCooldown}
+	}
+	methods := s.SRMethods
+	if len(methods) == 0 {
+		methods = []string{s.Base.SRMethod}
+	}
+
+	var out []Config
+	for _, l := range levels {
+		for _, sp := range spacings {
+			for _, a := range amounts {
+				for _, cd := range cooldowns {
+					for _, m := range methods {
+						c := s.Base
+						c.GridLevels = l
+						c.GridSpacingPercent = sp
+						c.AmountPerGrid = a
+						c.PostTradeCooldown = cd
+						c.SRMethod = m
+						out = append(out, c)
+					}
+				}
+			}
+		}
+	}
+	return out
+}
+
+// warmup is the leading history the sweep needs: the S/R lookback if any
+// combination centers its grid on S/R, otherwise none.
+func (s SweepSpec) warmup() time.Duration {
+	for _, c := range s.Combinations() {
+		if c.SRMethod != "" {
+			return s.Base.SRLookback
+		}
+	}
+	return 0
+}
+
+// LoadRange fetches [from, to) for the sweep, plus the S/R warm-up window
+// when any combination needs it.
+func (s SweepSpec) LoadRange(ctx context.Context, src PriceSource, from, to time.Time) ([]models.PricePoint, error) {
+	return LoadRange(ctx, src, from, to, s.warmup())
+}
+
+// Sweep runs every combination in the spec over the same series in parallel
+// and returns the runs ranked best-first. Failed runs are ranked last.
+//
+// When any combination uses S/R, prices must start with an SRLookback
+// warm-up window (see SweepSpec.LoadRange); runs without an S/R method skip
+// it so every combination trades over the same ticks.
+func Sweep(ctx context.Context, prices []models.PricePoint, spec SweepSpec) ([]SweepRun, error) {
+	combos := spec.Combinations()
+	if len(combos) == 0 {
+		return nil, fmt.Errorf("sweep has no combinations")
+	}
+	less, err := rankFunc(spec.RankBy)
+	if err != nil {
+		return nil, err
+	}
+
+	lookback := spec.warmup()
+
+	workers := spec.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	runs := make([]SweepRun, len(combos))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				cfg := combos[i]
+				runs[i].Params = cfg
+				res, err := Run(ctx, alignWarmup(prices, cfg, lookback), cfg)
+				if err != nil {
+					runs[i].Err = err.Error()
+					continue
+				}
+				runs[i].Summary = res.Summary
+			}
+		}()
+	}
+
+	for i := range combos {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			close(jobs)
+			wg.Wait()
+			return nil, ctx.Err()
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	sort.SliceStable(runs, func(i, j int) bool {
+		if (runs[i].Err == "") != (runs[j].Err == "") {
+			return runs[i].Err == ""
+		}
+		return less(runs[i].Summary, runs[j].Summary)
+	})
+	for i := range runs {
+		runs[i].Rank = i + 1
+	}
+	return runs, nil
+}
+
+// alignWarmup drops the leading warm-up window for runs that don't use S/R,
+// so they start trading at the same tick as the S/R runs.
+func alignWarmup(prices []models.PricePoint, cfg Config, lookback time.Duration) []models.PricePoint {
+	if cfg.SRMethod != "" || lookback <= 0 || len(prices) == 0 {
+		return prices
+	}
+	cutoff := prices[0].Timestamp.Add(lookback)
+	i := sort.Search(len(prices), func(i int) bool {
+		return !prices[i].Timestamp.Before(cutoff)
+	})
+	return prices[i:]
+}
+
+func rankFunc(by string) (func(a, b Summary) bool, error) {
+	switch by {
+	case "", "pnl":
+		return func(a, b Summary) bool { return a.NetPnL > b.NetPnL }, nil
+	case "drawdown":
+		return func(a, b Summary) bool { return a.MaxDrawdownPct < b.MaxDrawdownPct }, nil
+	case "trades":
+		return func(a, b Summary) bool { return a.TotalTrades > b.TotalTrades }, nil
+	case "gas":
+		return func(a, b Summary) bool { return a.GasSpentETH < b.GasSpentETH }, nil
+	default:
+		return nil, fmt.Errorf("invalid rank %q, expected pnl|drawdown|trades|gas", by)
+	}
+}
+
+// RunModels converts ranked sweep runs into rows for BacktestRepo.
+func RunModels(runs []SweepRun) []models.BacktestRun {
+	out := make([]models.BacktestRun, len(runs))
+	for i, r := range runs {
+		out[i] = models.BacktestRun{
+			Rank:               r.Rank,
+			GridLevels:         r.Params.GridLevels,
+			GridSpacingPercent: r.Params.GridSpacingPercent,
+			AmountPerGrid:      r.Params.AmountPerGrid,
+			CooldownSeconds:    int(r.Params.PostTradeCooldown.Seconds()),
+			SRMethod:           r.Params.SRMethod,
+			NetPnL:             r.Summary.NetPnL,
+			NetPnLPercent:      r.Summary.NetPnLPct,
+			MaxDrawdownPercent: r.Summary.MaxDrawdownPct,
+			TradeCount:         r.Summary.TotalTrades,
+			GasSpentETH:        r.Summary.GasSpentETH,
+		}
+		if r.Err != "" {
+			e := r.Err
+			out[i].Error = &e
+		}
+	}
+	return out
+}
@@ -0,0 +1,110 @@
+package backtest
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSweepSpec_Combinations(t *testing.T) {
+	spec := SweepSpec{
+		Base:               testConfig(),
+		GridLevels:         []int{4, 6, 8},
+		GridSpacingPercent: []float64{1, 2},
+		SRMethods:          []string{"", "simple"},
+	}
+	combos := spec.Combinations()
+	if len(combos) != 12 {
+		t.Fatalf("expected 3*2*2 = 12 combinations, got %d", len(combos))
+	}
+	for _, c := range combos {
+		if c.AmountPerGrid != spec.Base.AmountPerGrid {
+			t.Fatalf("unswept parameter should keep base value, got %.2f", c.AmountPerGrid)
+		}
+	}
+
+	if n := len(SweepSpec{Base: testConfig()}.Combinations()); n != 1 {
+		t.Fatalf("empty spec should yield the base config only, got %d", n)
+	}
+}
+
+func TestSweep_RankedByPnL(t *testing.T) {
+	// Oscillate between 1960 and 2040 around a 2000 center.
+	var raw []float64
+	for range 20 {
+		raw = append(raw, 2000, 1960, 2000, 2040)
+	}
+	prices := series(raw...)
+
+	spec := SweepSpec{
+		Base:               testConfig(),
+		GridLevels:         []int{1, 4},
+		GridSpacingPercent: []float64{1, 1.5, 5},
+		Workers:            2,
+	}
+	spec.Base.CenterPrice = 2000
+
+	runs, err := Sweep(context.Background(), prices, spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 6 {
+		t.Fatalf("expected 6 runs, got %d", len(runs))
+	}
+
+	for i, r := range runs {
+		if r.Rank != i+1 {
+			t.Fatalf("rank mismatch at %d: %d", i, r.Rank)
+		}
+	}
+	// GridLevels=1 is invalid — those runs fail and sort last.
+	for _, r := range runs[:3] {
+		if r.Err != "" {
+			t.Fatalf("expected successful runs first, got error %q", r.Err)
+		}
+	}
+	for _, r := range runs[3:] {
+		if r.Err == "" {
+			t.Fatal("expected failed runs last")
+		}
+	}
+	for i := 1; i < 3; i++ {
+		if runs[i].Summary.NetPnL > runs[i-1].Summary.NetPnL {
+			t.Fatalf("runs not ranked by P&L at %d", i)
+		}
+	}
+	// 5% spacing never triggers in a ±2% market.
+	for _, r := range runs[:3] {
+		if r.Params.GridSpacingPercent == 5 && r.Summary.TotalTrades != 0 {
+			t.Fatalf("expected 5%% grid to stay idle, got %d trades", r.Summary.TotalTrades)
+		}
+	}
+}
+
+func TestSweep_AlignsWarmup(t *testing.T) {
+	spec := SweepSpec{
+		Base:      testConfig(),
+		SRMethods: []string{"", "simple"},
+	}
+	spec.Base.SRLookback = 3 * time.Minute
+
+	runs, err := Sweep(context.Background(), series(1900, 2100, 2000, 2000, 1955, 2000), spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range runs {
+		if r.Err != "" {
+			t.Fatalf("%q: %s", r.Params.SRMethod, r.Err)
+		}
+		if r.Summary.Ticks != 3 {
+			t.Fatalf("%q: expected 3 traded ticks, got %d", r.Params.SRMethod, r.Summary.Ticks)
+		}
+	}
+}
+
+func TestSweep_InvalidRank(t *testing.T) {
+	_, err := Sweep(context.Background(), series(2000, 2001), SweepSpec{Base: testConfig(), RankBy: "sharpe"})
+	if err == nil {
+		t.Fatal("expected error for unknown rank")
+	}
+}
@@ -0,0 +1,189 @@
+package backtest
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/kjannette/trahn-backend/internal/models"
+)
+
+// WalkForwardSpec optimizes Sweep over a rolling in-sample window and
+// evaluates the winner on the out-of-sample window that follows it.
+type WalkForwardSpec struct {
+	Sweep       SweepSpec
+	InSample    time.Duration
+	OutOfSample time.Duration
+	Step        time.Duration // 0 = OutOfSample, i.e. back-to-back OOS windows
+}
+
+type WalkForwardWindow struct {
+	InSampleStart  time.Time `json:"inSampleStart"`
+	OutSampleStart time.Time `json:"outSampleStart"`
+	OutSampleEnd   time.Time `json:"outSampleEnd"`
+	Best           Config    `json:"best"`
+	InSample       Summary   `json:"inSample"`
+	OutOfSample    Summary   `json:"outOfSample"`
+	Err            string    `json:"error,omitempty"`
+}
+
+type WalkForwardResult struct {
+	Windows []WalkForwardWindow `json:"windows"`
+	Equity  []EquityPoint       `json:"equity"`  // stitched OOS equity
+	Summary Summary             `json:"summary"` // of the stitched OOS equity
+
+	// Efficiency is the mean ratio of OOS to IS return per unit time across
+	// windows with a profitable in-sample run. Near 1 means the optimized
+	// parameters held up out of sample; near 0 (or negative) means overfit.
+	Efficiency float64 `json:"efficiency"`
+}
+
+// WalkForward runs the walk-forward analysis over prices. When any swept
+// combination uses S/R, prices must start with an SRLookback warm-up window,
+// exactly as for Sweep.
+//
+// Each OOS window starts from the base wallet; the stitched equity curve
+// compounds window returns so the summary reads as one continuous run.
+func WalkForward(ctx context.Context, prices []models.PricePoint, spec WalkForwardSpec) (*WalkForwardResult, error) {
+	if spec.InSample <= 0 || spec.OutOfSample <= 0 {
+		return nil, fmt.Errorf("in-sample and out-of-sample windows must be positive")
+	}
+	step := spec.Step
+	if step <= 0 {
+		step = spec.OutOfSample
+	}
+	if len(prices) < 2 {
+		return nil, fmt.Errorf("need at least 2 price points, got %d", len(prices))
+	}
+
+	warmup := spec.Sweep.warmup()
+	end := prices[len(prices)-1].Timestamp.Add(time.Nanosecond)
+	res := &WalkForwardResult{}
+
+	for start := prices[0].Timestamp.Add(warmup); !start.Add(spec.InSample + spec.OutOfSample).After(end); start = start.Add(step) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		oosStart := start.Add(spec.InSample)
+		w := WalkForwardWindow{
+			InSampleStart:  start,
+			OutSampleStart: oosStart,
+			OutSampleEnd:   oosStart.Add(spec.OutOfSample),
+		}
+
+		runs, err := Sweep(ctx, sliceRange(prices, start.Add(-warmup), oosStart), spec.Sweep)
+		if err != nil {
+			return nil, fmt.Errorf("in-sample sweep %s: %w", start.Format(time.RFC3339), err)
+		}
+		if runs[0].Err != "" {
+			w.Err = "no valid in-sample run: " + runs[0].Err
+			res.Windows = append(res.Windows, w)
+			continue
+		}
+		w.Best = runs[0].Params
+		w.InSample = runs[0].Summary
+
+		oos, err := Run(ctx, sliceRange(prices, oosStart.Add(-w.Best.warmup()), w.OutSampleEnd), w.Best)
+		if err != nil {
+			w.Err = "out-of-sample: " + err.Error()
+			res.Windows = append(res.Windows, w)
+			continue
+		}
+		w.OutOfSample = oos.Summary
+		res.Windows = append(res.Windows, w)
+		res.Equity = stitch(res.Equity, oos.Equity)
+	}
+
+	if len(res.Windows) == 0 {
+		return nil, fmt.Errorf("series too short for one %s + %s window", spec.InSample, spec.OutOfSample)
+	}
+
+	res.Summary = stitchedSummary(res, spec.Sweep.Base)
+	res.Efficiency = efficiency(res.Windows, spec.InSample, spec.OutOfSample)
+	return res, nil
+}
+
+// sliceRange returns the points with from <= timestamp < to.
+func sliceRange(prices []models.PricePoint, from, to time.Time) []models.PricePoint {
+	lo := sort.Search(len(prices), func(i int) bool { return !prices[i].Timestamp.Before(from) })
+	hi := sort.Search(len(prices), func(i int) bool { return !prices[i].Timestamp.Before(to) })
+	return prices[lo:hi]
+}
+
+// stitch appends a window's equity curve, rescaled so it continues from the
+// last stitched value.
+func stitch(acc, window []EquityPoint) []EquityPoint {
+	if len(window) == 0 {
+		return acc
+	}
+	scale := 1.0
+	if len(acc) > 0 && window[0].ValueUSD > 0 {
+		scale = acc[len(acc)-1].ValueUSD / window[0].ValueUSD
+	}
+	for _, e := range window {
+		e.ValueUSD *= scale
+		e.ETH *= scale
+		e.USDC *= scale
+		acc = append(acc, e)
+	}
+	return acc
+}
+
+func stitchedSummary(res *WalkForwardResult, base Config) Summary {
+	var s Summary
+	for _, w := range res.Windows {
+		if w.Err != "" {
+			continue
+		}
+		o := w.OutOfSample
+		s.Ticks += o.Ticks
+		s.TotalTrades += o.TotalTrades
+		s.BuyTrades += o.BuyTrades
+		s.SellTrades += o.SellTrades
+		s.FailedFills += o.FailedFills
+		s.GasSpentETH += o.GasSpentETH
+		s.GasSpentUSD += o.GasSpentUSD
+		s.RealizedPnL += o.RealizedPnL
+		s.RoundTrips += o.RoundTrips
+	}
+	if len(res.Equity) == 0 {
+		return s
+	}
+
+	first, last := res.Equity[0], res.Equity[len(res.Equity)-1]
+	s.Start = first.Timestamp
+	s.End = last.Timestamp
+	s.MaxDrawdownPct = maxDrawdownPct(res.Equity)
+	if first.ValueUSD <= 0 {
+		// An empty starting wallet has no value to scale the curve by.
+		return s
+	}
+	s.InitialValueUSD = base.InitialETH*first.Price + base.InitialUSDC
+	s.FinalValueUSD = last.ValueUSD * s.InitialValueUSD / first.ValueUSD
+	s.NetPnL = s.FinalValueUSD - s.InitialValueUSD
+	if s.InitialValueUSD > 0 {
+		s.NetPnLPct = s.NetPnL / s.InitialValueUSD * 100
+		hold := base.InitialETH*last.Price + base.InitialUSDC
+		s.BuyAndHoldPnLPct = (hold - s.InitialValueUSD) / s.InitialValueUSD * 100
+	}
+	return s
+}
+
+func efficiency(windows []WalkForwardWindow, in, out time.Duration) float64 {
+	var sum float64
+	var n int
+	for _, w := range windows {
+		if w.Err != "" || w.InSample.NetPnLPct <= 0 {
+			continue
+		}
+		isRate := w.InSample.NetPnLPct / in.Hours()
+		oosRate := w.OutOfSample.NetPnLPct / out.Hours()
+		sum += oosRate / isRate
+		n++
+	}
+	if n == 0 {
+		return 0
+	}
+	return sum / float64(n)
+}
@@ -0,0 +1,99 @@
+package backtest
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+)
+
+func oscillating(n int) []float64 {
+	out := make([]float64, n)
+	for i := range out {
+		out[i] = 2000 + 60*math.Sin(float64(i)/5)
+	}
+	return out
+}
+
+func TestWalkForward_Windows(t *testing.T) {
+	prices := series(oscillating(180)...) // 3 hours of 1-minute ticks
+
+	spec := WalkForwardSpec{
+		Sweep: SweepSpec{
+			Base:               testConfig(),
+			GridSpacingPercent: []float64{0.5, 1, 2},
+			Workers:            2,
+		},
+		InSample:    60 * time.Minute,
+		OutOfSample: 30 * time.Minute,
+	}
+
+	res, err := WalkForward(context.Background(), prices, spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Starts at 0, 30, 60 minutes; the window starting at 90 would need a
+	// tick at 180, one past the end of the series.
+	if len(res.Windows) != 3 {
+		t.Fatalf("expected 3 windows, got %d", len(res.Windows))
+	}
+	for i, w := range res.Windows {
+		if w.Err != "" {
+			t.Fatalf("window %d: %s", i, w.Err)
+		}
+		if w.OutSampleStart.Sub(w.InSampleStart) != spec.InSample {
+			t.Fatalf("window %d: in-sample length %s", i, w.OutSampleStart.Sub(w.InSampleStart))
+		}
+		if w.OutOfSample.Ticks != 30 {
+			t.Fatalf("window %d: expected 30 OOS ticks, got %d", i, w.OutOfSample.Ticks)
+		}
+		if i > 0 && !w.OutSampleStart.Equal(res.Windows[i-1].OutSampleEnd) {
+			t.Fatalf("window %d: OOS windows should be back-to-back", i)
+		}
+	}
+
+	if len(res.Equity) != 90 {
+		t.Fatalf("expected 90 stitched OOS points, got %d", len(res.Equity))
+	}
+	if res.Summary.Ticks != 90 {
+		t.Fatalf("expected summary over 90 ticks, got %d", res.Summary.Ticks)
+	}
+	t.Logf("OOS P&L %.3f%%, max DD %.3f%%, efficiency %.2f",
+		res.Summary.NetPnLPct, res.Summary.MaxDrawdownPct, res.Efficiency)
+}
+
+func TestWalkForward_TooShort(t *testing.T) {
+	spec := WalkForwardSpec{
+		Sweep:       SweepSpec{Base: testConfig()},
+		InSample:    time.Hour,
+		OutOfSample: time.Hour,
+	}
+	if _, err := WalkForward(context.Background(), series(oscillating(60)...), spec); err == nil {
+		t.Fatal("expected error when series is shorter than one window")
+	}
+}
+
+func TestStitch(t *testing.T) {
+	a := []EquityPoint{{ValueUSD: 100}, {ValueUSD: 110}}
+	b := []EquityPoint{{ValueUSD: 50}, {ValueUSD: 45}}
+
+	got := stitch(stitch(nil, a), b)
+	if len(got) != 4 {
+		t.Fatalf("expected 4 points, got %d", len(got))
+	}
+	// Second window continues from 110 and loses 10%.
+	if math.Abs(got[2].ValueUSD-110) > 1e-9 || math.Abs(got[3].ValueUSD-99) > 1e-9 {
+		t.Fatalf("unexpected stitched values: %.2f, %.2f", got[2].ValueUSD, got[3].ValueUSD)
+	}
+}
+
+func TestStitchedSummary_ZeroStart(t *testing.T) {
+	res := &WalkForwardResult{Equity: []EquityPoint{{Price: 2000}, {Price: 2100}}}
+	s := stitchedSummary(res, Config{})
+	for _, v := range []float64{s.InitialValueUSD, s.FinalValueUSD, s.NetPnL, s.NetPnLPct, s.BuyAndHoldPnLPct} {
+		if v != 0 {
+			t.Fatalf("expected zero values for an empty wallet, got %+v", s)
+		}
+	}
+}
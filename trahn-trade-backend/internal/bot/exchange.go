@@ -0,0 +1,101 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+)
+
+// Exchange is where GridBot trades. The bot loop only talks to this
+// interface, so paper trading, live venues and test doubles are
+// interchangeable. PaperExchange and UniswapExchange implement it.
+type Exchange interface {
+	// IsPaper reports whether fills are simulated; trades are recorded with it.
+	IsPaper() bool
+
+	// Init connects to the venue and loads or records the starting balances
+	// that portfolio P&L is measured against.
+	Init(ctx context.Context, currentPrice float64) error
+
+	// Quote returns the price per ETH the venue would fill ethAmount at.
+	Quote(ctx context.Context, side string, ethAmount, refPrice float64) (float64, error)
+
+	// Buy and Sell return a *PendingSwapError when the swap was sent but not
+	// confirmed in time.
+	Buy(ctx context.Context, o Order) (Fill, error)
+	Sell(ctx context.Context, o Order) (Fill, error)
+
+	// Settle checks once on a swap that Buy or Sell left pending. done is
+	// false while it is still unconfirmed; once done, a nil error means it
+	// filled.
+	Settle(ctx context.Context, side, txHash string) (fill Fill, done bool, err error)
+
+	// Balances returns the wallet's current ETH and quote-token balances.
+	Balances(ctx context.Context) (eth, usdc float64, err error)
+
+	// GasEstimateETH is the expected gas cost of one swap.
+	GasEstimateETH(ctx context.Context) (float64, error)
+
+	// CanTrade returns an error when the venue should not be traded right
+	// now (e.g. network fees above the ceiling); the trade is deferred.
+	CanTrade(ctx context.Context) error
+
+	// Portfolio refreshes balances and returns P&L against the starting
+	// balances, valued at currentPrice.
+	Portfolio(ctx context.Context, currentPrice float64) (PortfolioStats, error)
+
+	// Snapshot persists the balances last read by Portfolio.
+	Snapshot(ctx context.Context, currentPrice float64) error
+
+	// Poll does per-tick housekeeping, such as replacing stuck transactions.
+	Poll(ctx context.Context)
+
+	Close()
+}
+
+// Order is a grid level's swap request. Amounts are nominal: ETHAmount at
+// Price for USDCAmount.
+type Order struct {
+	GridLevel    int
+	TriggerPrice float64 // the grid level's price
+	Price        float64 // reference price when the level triggered
+	ETHAmount    float64
+	USDCAmount   float64
+}
+
+// Fill is what a swap actually did: ETH and USDC moved after slippage,
+// plus the costs to record with the trade. When Executed is set the amounts
+// were read from the chain and are recorded in place of the requested ones;
+// paper trades record the requested amounts plus their slippage.
+type Fill struct {
+	TxHash      string
+	ETHAmount   float64
+	USDCAmount  float64
+	SlippagePct *float64
+	GasCostETH  *float64
+	Executed    bool
+}
+
+// PendingSwapError means a swap was broadcast but not confirmed before Buy
+// or Sell gave up waiting. It may still fill, so its level must not trade
+// again until Settle resolves it.
+type PendingSwapError struct {
+	TxHash string
+	Err    error
+}
+
+func (e *PendingSwapError) Error() string {
+	return fmt.Sprintf("swap %s not confirmed: %v", e.TxHash, e.Err)
+}
+
+func (e *PendingSwapError) Unwrap() error { return e.Err }
+
+type PortfolioStats struct {
+	ETHBalance       float64
+	USDCBalance      float64
+	InitialValueUSD  float64
+	CurrentValueUSD  float64
+	UnrealizedPnL    float64
+	UnrealizedPnLPct float64
+	GasSpentETH      float64 // tracked by paper trading only
+	RunningTimeHours float64
+}
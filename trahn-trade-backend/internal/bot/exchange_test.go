@@ -0,0 +1,61 @@
+package bot
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestPaperExchange(t *testing.T) {
+	ctx := context.Background()
+	ex := NewPaperExchange(nil, 1, 1000, 0)
+	if err := ex.Init(ctx, 2000); err != nil {
+		t.Fatal(err)
+	}
+
+	buy, err := ex.Buy(ctx, Order{GridLevel: 1, TriggerPrice: 2000, Price: 2000, ETHAmount: 0.25, USDCAmount: 500})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if buy.ETHAmount != 0.25 || buy.USDCAmount != 500 || buy.Executed {
+		t.Fatalf("unexpected buy fill: %+v", buy)
+	}
+	if !strings.HasPrefix(buy.TxHash, "0xPAPER_buy_") {
+		t.Fatalf("expected paper tx hash, got %q", buy.TxHash)
+	}
+	if buy.GasCostETH == nil || *buy.GasCostETH != DefaultPaperGasCost {
+		t.Fatalf("expected gas %.4f, got %v", DefaultPaperGasCost, buy.GasCostETH)
+	}
+
+	eth, usdc, err := ex.Balances(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(eth-(1.25-DefaultPaperGasCost)) > 1e-9 || usdc != 500 {
+		t.Fatalf("unexpected balances after buy: %f ETH / %f USDC", eth, usdc)
+	}
+
+	if _, err := ex.Buy(ctx, Order{ETHAmount: 1, USDCAmount: 2000, Price: 2000}); err == nil {
+		t.Fatal("expected insufficient USDC error")
+	}
+	if _, err := ex.Sell(ctx, Order{ETHAmount: 0.5, USDCAmount: 1000, Price: 2000}); err != nil {
+		t.Fatal(err)
+	}
+
+	ps, err := ex.Portfolio(ctx, 2000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Two fills at the reference price: only gas is lost.
+	wantPnL := -2 * DefaultPaperGasCost * 2000
+	if math.Abs(ps.UnrealizedPnL-wantPnL) > 1e-6 {
+		t.Fatalf("expected P&L %.2f, got %.2f", wantPnL, ps.UnrealizedPnL)
+	}
+	if math.Abs(ps.GasSpentETH-2*DefaultPaperGasCost) > 1e-9 {
+		t.Fatalf("expected gas spent %.4f, got %.4f", 2*DefaultPaperGasCost, ps.GasSpentETH)
+	}
+	if ps.ETHBalance != eth-0.5-DefaultPaperGasCost || ps.USDCBalance != 1500 {
+		t.Fatalf("unexpected portfolio balances: %+v", ps)
+	}
+}
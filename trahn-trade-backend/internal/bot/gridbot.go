@@ -3,11 +3,11 @@ package bot
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/kjannette/trahn-backend/internal/config"
-	"github.com/kjannette/trahn-backend/internal/ethereum"
 	"github.com/kjannette/trahn-backend/internal/external"
 	"github.com/kjannette/trahn-backend/internal/models"
 	"github.com/kjannette/trahn-backend/internal/notifications"
@@ -17,16 +17,19 @@ import (
 )
 
 type GridBot struct {
-	cfg       *config.Config
-	coingecko *external.CoinGeckoClient
-	dune      *external.DuneClient
-	priceRepo *repository.PriceRepo
-	tradeRepo *repository.TradeRepo
-	gridRepo  *repository.GridStateRepo
-	notify    *notifications.Sender
+	cfg        *config.Config
+	prices     *priceFeeds
+	srSource   external.SRProvider
+	priceRepo  *repository.PriceRepo
+	candleRepo *repository.CandleRepo
+	tradeRepo  *repository.TradeRepo
+	gridRepo   *repository.GridStateRepo
+	lotRepo    *repository.LotRepo
+	notify     *notifications.Sender
 
 	Grid             []strategy.GridLevel
 	LastETHPrice     float64
+	LastPriceAt      time.Time // when LastETHPrice was observed at its sources
 	BasePrice        float64
 	TradesExecuted   int
 	TotalProfit      float64
@@ -34,10 +37,9 @@ type GridBot struct {
 	LastStatusReport time.Time
 	LastSRRefresh    *time.Time
 
-	guardian     *risk.Guardian
-	paperWallet *PaperWallet
-	uniswap     *ethereum.UniswapV2
-	ethClient   *ethereum.Client
+	guardian   *risk.Guardian
+	exchange   Exchange
+	priceStale bool // a stale-price halt has been announced
 
 	running bool
 	stopCh  chan struct{}
@@ -46,32 +48,47 @@ type GridBot struct {
 func NewGridBot(
 	cfg *config.Config,
 	priceRepo *repository.PriceRepo,
+	candleRepo *repository.CandleRepo,
 	tradeRepo *repository.TradeRepo,
 	gridRepo *repository.GridStateRepo,
+	lotRepo *repository.LotRepo,
+	portRepo *repository.PortfolioRepo,
+	txRepo *repository.PendingTxRepo,
 	notify *notifications.Sender,
-	dune *external.DuneClient,
+	srSource external.SRProvider,
 ) *GridBot {
 	b := &GridBot{
-		cfg:       cfg,
-		coingecko: external.NewCoinGeckoClient(),
-		dune:      dune,
-		priceRepo: priceRepo,
-		tradeRepo: tradeRepo,
-		gridRepo:  gridRepo,
-		notify:    notify,
-		stopCh:    make(chan struct{}),
+		cfg:        cfg,
+		srSource:   srSource,
+		priceRepo:  priceRepo,
+		candleRepo: candleRepo,
+		tradeRepo:  tradeRepo,
+		gridRepo:   gridRepo,
+		lotRepo:    lotRepo,
+		notify:     notify,
+		stopCh:     make(chan struct{}),
 		guardian: risk.NewGuardian(risk.Limits{
 			MaxDailyTrades:     cfg.MaxDailyTrades,
 			MaxPositionSizeUSD: cfg.MaxPositionSizeUSD,
 			StopLossPercent:    cfg.StopLossPercent,
 			TakeProfitPercent:  cfg.TakeProfitPercent,
+			MaxPriceAge:        time.Duration(cfg.MaxPriceAgeSeconds) * time.Second,
+			MaxTWAPDeviation:   cfg.TWAPMaxDeviationPercent,
 		}, tradeRepo),
 	}
 
-	if dune != nil {
-		fmt.Printf("[S/R] Dune Analytics configured: %s method, %d-day lookback\n", cfg.SRMethod, cfg.SRLookbackDays)
+	b.prices = newPriceFeeds(cfg)
+
+	if cfg.PaperTradingEnabled {
+		b.exchange = NewPaperExchange(gridRepo, cfg.PaperInitialETH, cfg.PaperInitialUSDC, cfg.PaperSlippagePercent)
+	} else {
+		b.exchange = NewUniswapExchange(cfg, portRepo, txRepo, notify)
+	}
+
+	if srSource != nil {
+		fmt.Printf("[S/R] %s provider configured: %s method, %d-day lookback\n", cfg.SRSource(), cfg.SRMethod, cfg.SRLookbackDays)
 	} else {
-		fmt.Println("[S/R] Dune API key not set - using fallback (current price as midpoint)")
+		fmt.Println("[S/R] No S/R provider - using fallback (current price as midpoint)")
 	}
 
 	return b
@@ -81,41 +98,9 @@ func (b *GridBot) Init(ctx context.Context) error {
 	if err := b.loadState(ctx); err != nil {
 		fmt.Printf("Warning: failed to load state: %v\n", err)
 	}
+	b.syncLots(ctx)
 
-	if b.cfg.PaperTradingEnabled {
-		b.paperWallet = NewPaperWallet(b.gridRepo, b.cfg.PaperInitialETH, b.cfg.PaperInitialUSDC)
-		if err := b.paperWallet.Init(ctx); err != nil {
-			return fmt.Errorf("paper wallet init: %w", err)
-		}
-	} else {
-		ethC, err := ethereum.NewClient(
-			b.cfg.EthereumAPIEndpoint,
-			b.cfg.PrivateKey,
-			int64(b.cfg.ChainID),
-			b.cfg.GasLimit,
-			b.cfg.GasMultiplier,
-		)
-		if err != nil {
-			return fmt.Errorf("ethereum client: %w", err)
-		}
-		b.ethClient = ethC
-
-		uni, err := ethereum.NewUniswapV2(
-			ethC,
-			b.cfg.UniswapRouterAddress,
-			b.cfg.WETHAddress,
-			b.cfg.QuoteTokenAddress,
-			b.cfg.QuoteTokenSymbol,
-			b.cfg.QuoteTokenDecimals,
-			b.cfg.SlippageTolerance,
-		)
-		if err != nil {
-			return fmt.Errorf("uniswap client: %w", err)
-		}
-		b.uniswap = uni
-		fmt.Printf("[LIVE] Ethereum client connected, wallet %s\n", ethC.WalletAddress().Hex())
-	}
-	return nil
+	return b.exchange.Init(ctx, b.fetchETHPrice(ctx))
 }
 
 // --- state management ---
@@ -144,7 +129,12 @@ func (b *GridBot) loadState(ctx context.Context) error {
 	return nil
 }
 
+// saveState persists the grid. Like the paper wallet, a bot without repos
+// (as in tests) keeps its state in memory only.
 func (b *GridBot) saveState(ctx context.Context) {
+	if b.gridRepo == nil {
+		return
+	}
 	levelsJSON, err := json.Marshal(b.Grid)
 	if err != nil {
 		fmt.Printf("[STATE] Failed to marshal grid levels: %v\n", err)
@@ -162,21 +152,66 @@ func (b *GridBot) saveState(ctx context.Context) {
 	}
 }
 
+// recordTrade appends a fill to trade_history.
+func (b *GridBot) recordTrade(ctx context.Context, t *models.Trade) {
+	if b.tradeRepo == nil {
+		return
+	}
+	if _, err := b.tradeRepo.Record(ctx, t); err != nil {
+		fmt.Printf("[STATE] Failed to record trade: %v\n", err)
+	}
+}
+
+// syncLots brings the cost basis lots up to date with trade_history.
+func (b *GridBot) syncLots(ctx context.Context) {
+	if b.lotRepo == nil {
+		return
+	}
+	n, err := b.lotRepo.Sync(ctx, b.cfg.CostBasisMethod)
+	if err != nil {
+		fmt.Printf("[LOTS] Failed to sync cost basis lots: %v\n", err)
+		return
+	}
+	if n > 0 {
+		fmt.Printf("[LOTS] Applied %d trade(s) to %s lots\n", n, b.cfg.CostBasisMethod)
+	}
+}
+
+// syncCandles folds newly recorded prices into the stored candles, which the
+// API and the S/R providers read.
+func (b *GridBot) syncCandles(ctx context.Context) {
+	if b.candleRepo == nil {
+		return
+	}
+	if err := b.candleRepo.SyncAll(ctx); err != nil {
+		fmt.Printf("[PRICE] Failed to sync candles: %v\n", err)
+	}
+}
+
 // --- price ---
 
 func (b *GridBot) fetchETHPrice(ctx context.Context) float64 {
-	price, err := b.coingecko.GetETHPrice(ctx)
+	res, err := b.prices.agg.Price(ctx)
+	for _, r := range res.Failed {
+		fmt.Printf("[PRICE] %s failed: %v\n", r.Source, r.Err)
+	}
+	for _, r := range res.Rejected {
+		fmt.Printf("[PRICE] %s rejected: $%.2f is more than %.1f%% from the other sources\n",
+			r.Source, r.Price, b.cfg.PriceMaxSourceDeviation)
+	}
 	if err != nil {
 		fmt.Printf("Failed to fetch ETH price: %v\n", err)
 		return b.LastETHPrice
 	}
+	price := res.Price
 	if price < 100 || price > 100000 {
 		fmt.Printf("ETH price %.2f failed sanity check\n", price)
 		return b.LastETHPrice
 	}
 	b.LastETHPrice = price
+	b.LastPriceAt = res.Time
 
-	_, _ = b.priceRepo.Record(ctx, price, time.Now())
+	_, _ = b.priceRepo.Record(ctx, price, time.Now(), res.Source())
 	return price
 }
 
@@ -185,40 +220,123 @@ func (b *GridBot) fetchETHPrice(ctx context.Context) float64 {
 func (b *GridBot) InitializeGrid(ctx context.Context) error {
 	sr := b.fetchSR(ctx)
 
-	center := b.BasePrice
-	if center == 0 {
-		center = sr.Midpoint
-	}
-	b.BasePrice = center
-
 	price := b.fetchETHPrice(ctx)
 	if price <= 0 {
 		return fmt.Errorf("cannot initialize grid: invalid ETH price")
 	}
 
+	center := b.BasePrice
+	if len(b.cfg.GridLadder) > 0 {
+		// A fixed ladder splits buys from sells at the market, not at an
+		// S/R midpoint that moves on every refresh.
+		center = price
+	} else if center == 0 {
+		center = sr.Midpoint
+	}
+	b.BasePrice = center
+
 	b.notify.Send(fmt.Sprintf("S/R Analysis (%s, %dd): Support $%.2f | Resistance $%.2f | Midpoint $%.2f",
 		sr.Method, sr.LookbackDays, sr.Support, sr.Resistance, sr.Midpoint))
 
-	grid, err := strategy.CalculateGridLevels(strategy.GridParams{
-		CenterPrice:    center,
-		LevelCount:     b.cfg.GridLevels,
-		SpacingPercent: b.cfg.GridSpacingPercent,
-		AmountPerGrid:  b.cfg.AmountPerGrid,
-	})
+	params := b.gridParams(ctx, center, sr)
+	layout := ""
+	if len(params.Ladder) > 0 {
+		layout = "custom ladder"
+	} else if params.Bounded() {
+		layout = fmt.Sprintf("%s between support and resistance", params.Layout)
+	} else {
+		spacing, err := strategy.EffectiveSpacing(params)
+		if err != nil {
+			fmt.Printf("[GRID] %s spacing unavailable, using fixed %.2f%%: %v\n", params.SpacingMode, b.cfg.GridSpacingPercent, err)
+			params.SpacingMode = strategy.SpacingFixed
+			spacing = b.cfg.GridSpacingPercent
+		}
+		layout = fmt.Sprintf("spacing %.2f%% (%s)", spacing, params.SpacingMode)
+	}
+	grid, err := strategy.CalculateGridLevels(params)
 	if err != nil {
 		return fmt.Errorf("calculate grid: %w", err)
 	}
 	b.Grid = grid
 	b.saveState(ctx)
 
-	b.notify.Send(fmt.Sprintf("Grid initialized: %d levels from $%.2f to $%.2f, center at $%.2f",
-		len(grid), grid[0].Price, grid[len(grid)-1].Price, center))
+	b.notify.Send(fmt.Sprintf("Grid initialized: %d levels from $%.2f to $%.2f, center at $%.2f, %s",
+		len(grid), grid[0].Price, grid[len(grid)-1].Price, center, layout))
 
 	return nil
 }
 
+// gridParams builds the grid parameters from config. Levels are capped at
+// the guardian's max position size, and balance sizing reads the wallet
+// through the exchange. A GRID_LADDER is used as is, and in bounded mode the
+// grid spans sr's support to resistance. Otherwise, for the atr and
+// volatility spacing modes it loads hourly bars over
+// GRID_VOLATILITY_LOOKBACK_HOURS; if they cannot be read, Bars is left empty
+// and EffectiveSpacing reports it.
+func (b *GridBot) gridParams(ctx context.Context, center float64, sr *external.SRResult) strategy.GridParams {
+	p := strategy.GridParams{
+		CenterPrice:       center,
+		LevelCount:        b.cfg.GridLevels,
+		SpacingPercent:    b.cfg.GridSpacingPercent,
+		AmountPerGrid:     b.cfg.AmountPerGrid,
+		SpacingMode:       b.cfg.GridSpacingMode,
+		SpacingMultiplier: b.cfg.GridSpacingMultiplier,
+		MinSpacingPercent: b.cfg.GridSpacingMinPercent,
+		MaxSpacingPercent: b.cfg.GridSpacingMaxPercent,
+		Layout:            b.cfg.GridLayout,
+		BuyLevels:         b.cfg.GridBuyLevels,
+		SellLevels:        b.cfg.GridSellLevels,
+		Ladder:            b.cfg.GridLadder,
+
+		Sizing:               b.cfg.SizingMode,
+		PyramidMultiplier:    b.cfg.SizingPyramidMultiplier,
+		PyramidMaxMultiplier: b.cfg.SizingPyramidMaxMultiplier,
+		BalancePercent:       b.cfg.SizingBalancePercent,
+		MaxLevelUSD:          b.guardian.MaxPositionUSD(),
+	}
+	if p.Sizing == strategy.SizingBalance {
+		eth, usdc, err := b.exchange.Balances(ctx)
+		if err != nil {
+			fmt.Printf("[GRID] Failed to read balances, sizing at $%.0f/level: %v\n", b.cfg.AmountPerGrid, err)
+			p.Sizing = strategy.SizingQuote
+		}
+		if err == nil && usdc <= 0 {
+			fmt.Printf("[GRID] No USDC balance, sizing buys at $%.0f/level\n", b.cfg.AmountPerGrid)
+		}
+		if err == nil && eth <= 0 {
+			fmt.Printf("[GRID] No ETH balance, sizing sells at $%.0f/level\n", b.cfg.AmountPerGrid)
+		}
+		p.BalanceETH, p.BalanceUSDC = eth, usdc
+	}
+	if len(p.Ladder) > 0 {
+		return p
+	}
+	if b.cfg.GridMode == "bounded" {
+		p.LowerPrice, p.UpperPrice = sr.Support, sr.Resistance
+		return p
+	}
+	if p.SpacingMode == strategy.SpacingFixed || b.candleRepo == nil {
+		return p
+	}
+
+	if _, err := b.candleRepo.Sync(ctx, "1h"); err != nil {
+		fmt.Printf("[GRID] Failed to sync hourly candles: %v\n", err)
+		return p
+	}
+	now := time.Now()
+	candles, err := b.candleRepo.GetRange(ctx, "1h", now.Add(-time.Duration(b.cfg.GridVolatilityLookbackHours)*time.Hour), now)
+	if err != nil {
+		fmt.Printf("[GRID] Failed to load hourly candles: %v\n", err)
+		return p
+	}
+	for _, c := range candles {
+		p.Bars = append(p.Bars, strategy.Bar{High: c.High, Low: c.Low, Close: c.Close})
+	}
+	return p
+}
+
 func (b *GridBot) fetchSR(ctx context.Context) *external.SRResult {
-	if b.dune == nil {
+	if b.srSource == nil {
 		price := b.fetchETHPrice(ctx)
 		fb := strategy.CreateFallbackSR(price)
 		return &external.SRResult{
@@ -227,9 +345,9 @@ func (b *GridBot) fetchSR(ctx context.Context) *external.SRResult {
 		}
 	}
 
-	sr, err := b.dune.FetchSupportResistance(ctx, false)
+	sr, err := b.srSource.FetchSupportResistance(ctx, false)
 	if err != nil {
-		fmt.Printf("[S/R] Dune fetch failed: %v — falling back to current price\n", err)
+		fmt.Printf("[S/R] %s fetch failed: %v — falling back to current price\n", b.cfg.SRSource(), err)
 		price := b.fetchETHPrice(ctx)
 		fb := strategy.CreateFallbackSR(price)
 		return &external.SRResult{
@@ -245,167 +363,215 @@ func (b *GridBot) fetchSR(ctx context.Context) *external.SRResult {
 // --- trading ---
 
 func (b *GridBot) executeTrade(ctx context.Context, level *strategy.GridLevel, currentPrice float64) error {
-	tradeUSD := level.Quantity * currentPrice
-	if err := b.guardian.PreTradeCheck(ctx, tradeUSD); err != nil {
+	if err := b.checkPriceFresh(); err != nil {
+		return err
+	}
+	ethAmount := b.tradeQuantity(level, currentPrice)
+	if err := b.guardian.PreTradeCheck(ctx, ethAmount*currentPrice); err != nil {
 		b.notify.Send(fmt.Sprintf("[RISK] %v", err))
 		return err
 	}
+	if err := b.exchange.CanTrade(ctx); err != nil {
+		fmt.Printf("[GAS] Deferring %s at grid level %d: %v\n", level.Side, level.Index, err)
+		return err
+	}
+	if err := b.checkTWAP(ctx, level, ethAmount, currentPrice); err != nil {
+		fmt.Printf("[TWAP] Deferring %s at grid level %d: %v\n", level.Side, level.Index, err)
+		return err
+	}
 
 	if level.Side == "buy" {
-		return b.executeBuy(ctx, level, currentPrice)
+		return b.executeBuy(ctx, level, ethAmount, currentPrice)
 	}
-	return b.executeSell(ctx, level, currentPrice)
+	return b.executeSell(ctx, level, ethAmount, currentPrice)
+}
+
+// tradeQuantity is the ETH to trade at level. Levels are sized within the
+// max position size at their own price, but a sell triggers at or above it,
+// so the amount is trimmed to keep the trade at currentPrice within the
+// limit instead of having PreTradeCheck block it on every tick.
+func (b *GridBot) tradeQuantity(level *strategy.GridLevel, currentPrice float64) float64 {
+	maxUSD := b.guardian.MaxPositionUSD()
+	if maxUSD <= 0 || level.Quantity*currentPrice <= maxUSD {
+		return level.Quantity
+	}
+	qty := maxUSD / currentPrice
+	fmt.Printf("[RISK] Trimming %s at grid level %d from %.6f to %.6f ETH to stay within max position $%.2f\n",
+		level.Side, level.Index, level.Quantity, qty, maxUSD)
+	return qty
 }
 
-func (b *GridBot) executeBuy(ctx context.Context, level *strategy.GridLevel, currentPrice float64) error {
-	return b.executeSwap(ctx, level, currentPrice, "buy")
+func (b *GridBot) executeBuy(ctx context.Context, level *strategy.GridLevel, ethAmount, currentPrice float64) error {
+	return b.executeSwap(ctx, level, ethAmount, currentPrice, "buy")
 }
 
-func (b *GridBot) executeSell(ctx context.Context, level *strategy.GridLevel, currentPrice float64) error {
-	return b.executeSwap(ctx, level, currentPrice, "sell")
+func (b *GridBot) executeSell(ctx context.Context, level *strategy.GridLevel, ethAmount, currentPrice float64) error {
+	return b.executeSwap(ctx, level, ethAmount, currentPrice, "sell")
 }
 
-func (b *GridBot) executeSwap(ctx context.Context, level *strategy.GridLevel, currentPrice float64, side string) error {
-	ethAmount := level.Quantity
+func (b *GridBot) executeSwap(ctx context.Context, level *strategy.GridLevel, ethAmount, currentPrice float64, side string) error {
 	usdcAmount := ethAmount * currentPrice
 	prefix := ""
-	if b.cfg.PaperTradingEnabled {
+	if b.exchange.IsPaper() {
 		prefix = "[PAPER] "
 	}
 
 	b.notify.Send(fmt.Sprintf("%sExecuting %s at grid level %d: ~%.6f ETH for ~%.2f USDC (@ $%.2f/ETH)",
 		prefix, side, level.Index, ethAmount, usdcAmount, currentPrice))
 
-	var txHash string
-	var slippagePct, gasCost *float64
-
-	if b.cfg.PaperTradingEnabled {
-		hash, slip, gas, err := b.executePaperSwap(ctx, level, currentPrice, side, ethAmount, usdcAmount)
-		if err != nil {
-			return err
-		}
-		txHash = hash
-		slippagePct = slip
-		gasCost = gas
+	order := Order{
+		GridLevel:    level.Index,
+		TriggerPrice: level.Price,
+		Price:        currentPrice,
+		ETHAmount:    ethAmount,
+		USDCAmount:   usdcAmount,
+	}
+	var fill Fill
+	var err error
+	if side == "buy" {
+		fill, err = b.exchange.Buy(ctx, order)
 	} else {
-		hash, gas, err := b.executeLiveSwap(ctx, side, ethAmount, usdcAmount)
-		if err != nil {
-			return err
+		fill, err = b.exchange.Sell(ctx, order)
+	}
+	if err != nil {
+		var pending *PendingSwapError
+		if errors.As(err, &pending) {
+			level.PendingTx = &pending.TxHash
+			b.saveState(ctx)
 		}
-		txHash = hash
-		gasCost = gas
+		return err
+	}
+	b.applyFill(ctx, level, ethAmount, currentPrice, side, fill)
+	return nil
+}
+
+// applyFill marks level filled by fill and records the trade. ethAmount and
+// currentPrice are what was ordered, recorded when the fill carries no
+// on-chain amounts.
+func (b *GridBot) applyFill(ctx context.Context, level *strategy.GridLevel, ethAmount, currentPrice float64, side string, fill Fill) {
+	usdcAmount := ethAmount * currentPrice
+	var gasUSD float64
+	if fill.GasCostETH != nil {
+		gasUSD = *fill.GasCostETH * currentPrice
+	}
+	var realized *float64
+	if profit, ok := strategy.SettleFill(b.Grid, level, fill.ETHAmount, fill.USDCAmount, gasUSD); ok {
+		realized = &profit
+		b.TotalProfit += profit
+		fmt.Printf("Round trip closed at grid level %d: realized $%.2f (total $%.2f)\n",
+			level.Index, profit, b.TotalProfit)
 	}
 
 	level.Filled = true
+	level.PendingTx = nil
 	now := time.Now()
 	level.FilledAt = &now
-	level.TxHash = &txHash
+	level.TxHash = &fill.TxHash
 	b.TradesExecuted++
 	b.saveState(ctx)
 
+	price, qty, usd := currentPrice, ethAmount, usdcAmount
+	if fill.Executed && fill.ETHAmount > 0 {
+		price, qty, usd = fill.USDCAmount/fill.ETHAmount, fill.ETHAmount, fill.USDCAmount
+	}
+
 	gridLevel := level.Index
-	_, _ = b.tradeRepo.Record(ctx, &models.Trade{
+	b.recordTrade(ctx, &models.Trade{
 		Timestamp:       now,
 		Side:            side,
-		Price:           currentPrice,
-		Quantity:        ethAmount,
-		USDValue:        usdcAmount,
+		Price:           price,
+		Quantity:        qty,
+		USDValue:        usd,
 		GridLevel:       &gridLevel,
-		TxHash:          &txHash,
-		IsPaperTrade:    b.cfg.PaperTradingEnabled,
-		SlippagePercent: slippagePct,
-		GasCostETH:      gasCost,
+		TxHash:          &fill.TxHash,
+		IsPaperTrade:    b.exchange.IsPaper(),
+		SlippagePercent: fill.SlippagePct,
+		GasCostETH:      fill.GasCostETH,
+		RealizedPnL:     realized,
 	})
+	b.syncLots(ctx)
 
 	b.resetOppositeLevel(ctx, level)
-	return nil
 }
 
-func (b *GridBot) executePaperSwap(ctx context.Context, level *strategy.GridLevel, currentPrice float64, side string, ethAmount, usdcAmount float64) (txHash string, slippagePct, gasCost *float64, err error) {
-	slip := randomSlippage(b.cfg.PaperSlippagePercent)
-	gas := defaultPaperGasCost
-
-	if side == "buy" {
-		actualETH := ethAmount * (1 - slip)
-		if err := b.paperWallet.ExecuteBuy(ctx, usdcAmount, actualETH); err != nil {
-			return "", nil, nil, err
+// settlePending resolves swaps that were sent but not confirmed when the
+// exchange returned. A swap that filled completes its level as if it had
+// confirmed in time; one that did not re-arms the level.
+func (b *GridBot) settlePending(ctx context.Context, currentPrice float64) {
+	for i := range b.Grid {
+		level := &b.Grid[i]
+		if level.PendingTx == nil {
+			continue
 		}
-		ethAmount = actualETH
-	} else {
-		if b.paperWallet.ETHBalance < ethAmount+gas {
-			return "", nil, nil, fmt.Errorf("insufficient ETH: have %.6f, need %.6f", b.paperWallet.ETHBalance, ethAmount+gas)
+		fill, done, err := b.exchange.Settle(ctx, level.Side, *level.PendingTx)
+		if !done {
+			continue
 		}
-		usdcAmount = usdcAmount * (1 - slip)
-		if err := b.paperWallet.ExecuteSell(ctx, ethAmount, usdcAmount); err != nil {
-			return "", nil, nil, err
+		if err != nil {
+			fmt.Printf("Pending %s at grid level %d did not fill: %v\n", level.Side, level.Index, err)
+			level.PendingTx = nil
+			b.saveState(ctx)
+			continue
 		}
+		b.applyFill(ctx, level, fill.ETHAmount, currentPrice, level.Side, fill)
 	}
-
-	b.paperWallet.DeductGas(ctx, gas)
-	b.paperWallet.RecordTrade(ctx, PaperTrade{
-		Side: side, GridLevel: level.Index,
-		TriggerPrice: level.Price, ExecutionPrice: currentPrice,
-		ETHAmount: ethAmount, USDCAmount: usdcAmount,
-		SlippagePct: slip * 100, GasCost: gas,
-	})
-
-	txHash = fmt.Sprintf("0xPAPER_%s_%x", side, time.Now().UnixNano())
-	s := slip * 100
-	slippagePct = &s
-	gasCost = &gas
-	fmt.Printf("[PAPER] %s executed: %.6f ETH for %.2f USDC (slippage: %.3f%%, gas: %.6f ETH)\n",
-		side, ethAmount, usdcAmount, s, gas)
-	return txHash, slippagePct, gasCost, nil
 }
 
-func (b *GridBot) executeLiveSwap(ctx context.Context, side string, ethAmount, usdcAmount float64) (txHash string, gasCost *float64, err error) {
-	var hash string
-	var swapErr error
-
-	if side == "buy" {
-		b.notify.Send(fmt.Sprintf("Broadcasting BUY TX: %.6f ETH for %.2f USDC...", ethAmount, usdcAmount))
-		hash, swapErr = b.uniswap.SwapUSDCForETH(ctx, usdcAmount, ethAmount)
-	} else {
-		b.notify.Send(fmt.Sprintf("Broadcasting SELL TX: %.6f ETH for ~%.2f USDC...", ethAmount, usdcAmount))
-		hash, swapErr = b.uniswap.SwapETHForUSDC(ctx, ethAmount)
-	}
-	if swapErr != nil {
-		b.notify.Send(fmt.Sprintf("%s TX failed: %v", side, swapErr))
-		return "", nil, fmt.Errorf("swap failed (%s): %w", side, swapErr)
+func (b *GridBot) resetOppositeLevel(ctx context.Context, filled *strategy.GridLevel) {
+	if idx, ok := strategy.ResetOppositeLevel(b.Grid, filled); ok {
+		b.saveState(ctx)
+		fmt.Printf("Reset grid level %d for opposite trade\n", idx)
 	}
+}
+
+// --- risk ---
 
-	b.notify.Send(fmt.Sprintf("%s TX confirmed: %s", side, b.uniswap.ExplorerURL(hash)))
-	gas, _ := b.uniswap.GasCostETH(ctx)
-	gasCost = &gas
-	return hash, gasCost, nil
+// checkPriceFresh returns an error while the last price is older than
+// MAX_PRICE_AGE_SECONDS, e.g. because every source is failing and
+// fetchETHPrice keeps returning LastETHPrice. It notifies once when trading
+// halts and again when fresh prices resume it.
+func (b *GridBot) checkPriceFresh() error {
+	err := b.guardian.PriceCheck(b.LastPriceAt, time.Now())
+	switch {
+	case err != nil && !b.priceStale:
+		b.priceStale = true
+		b.notify.Send(fmt.Sprintf("[STALE] Trading halted: %v", err))
+	case err == nil && b.priceStale:
+		b.priceStale = false
+		b.notify.Send(fmt.Sprintf("[STALE] Fresh price $%.2f received - trading resumed", b.LastETHPrice))
+	}
+	return err
 }
 
-func (b *GridBot) resetOppositeLevel(ctx context.Context, filled *strategy.GridLevel) {
-	idx := strategy.GetOppositeLevelIndex(filled, len(b.Grid))
-	if idx == nil {
-		return
+// checkTWAP compares the price the venue would execute at with the pool's
+// TWAP. It is a no-op unless TWAP_MAX_DEVIATION_PERCENT is set and a TWAP
+// is available; while the TWAP is still warming up, trades are deferred.
+func (b *GridBot) checkTWAP(ctx context.Context, level *strategy.GridLevel, ethAmount, currentPrice float64) error {
+	if b.cfg.TWAPMaxDeviationPercent <= 0 || b.prices.twap == nil {
+		return nil
 	}
-	adj := &b.Grid[*idx]
-	if adj.Filled {
-		adj.Filled = false
-		adj.FilledAt = nil
-		adj.TxHash = nil
-		b.saveState(ctx)
-		fmt.Printf("Reset grid level %d for opposite trade\n", *idx)
+	twap, _, err := b.prices.twap.Price(ctx)
+	if err != nil {
+		return fmt.Errorf("TWAP unavailable: %w", err)
 	}
+	exec, err := b.exchange.Quote(ctx, level.Side, ethAmount, currentPrice)
+	if err != nil {
+		return fmt.Errorf("quote: %w", err)
+	}
+	return b.guardian.TWAPCheck(exec, twap)
 }
 
-// --- risk ---
-
 // portfolioPnLPercent returns the current unrealized P&L as a percentage.
 // The second return value is false when P&L cannot be determined (e.g. live
-// mode without balance tracking), in which case the caller should skip the
-// portfolio-level check.
-func (b *GridBot) portfolioPnLPercent(currentPrice float64) (float64, bool) {
-	if b.cfg.PaperTradingEnabled && b.paperWallet != nil {
-		return b.paperWallet.Stats(currentPrice).UnrealizedPnLPct, true
+// balances could not be read this tick), in which case the caller should
+// skip the portfolio-level check.
+func (b *GridBot) portfolioPnLPercent(ctx context.Context, currentPrice float64) (float64, bool) {
+	ps, err := b.exchange.Portfolio(ctx, currentPrice)
+	if err != nil {
+		fmt.Printf("[RISK] Skipping portfolio check: %v\n", err)
+		return 0, false
 	}
-	return 0, false
+	return ps.UnrealizedPnLPct, true
 }
 
 // --- main loop ---
@@ -413,17 +579,21 @@ func (b *GridBot) portfolioPnLPercent(currentPrice float64) (float64, bool) {
 func (b *GridBot) Run(ctx context.Context) {
 	b.running = true
 
-	b.notify.Send(fmt.Sprintf("Starting ETH grid trader with %d levels, %.1f%% spacing",
-		b.cfg.GridLevels, b.cfg.GridSpacingPercent))
+	// The layout depends on mode, spacing and S/R, so it is reported by
+	// InitializeGrid; a grid restored from the database is described here.
+	b.notify.Send("Starting ETH grid trader")
 
 	if len(b.Grid) == 0 {
 		if err := b.InitializeGrid(ctx); err != nil {
 			fmt.Printf("Failed to initialize grid: %v\n", err)
 			return
 		}
+	} else {
+		b.notify.Send(fmt.Sprintf("Resuming grid: %d levels from $%.2f to $%.2f, center at $%.2f",
+			len(b.Grid), b.Grid[0].Price, b.Grid[len(b.Grid)-1].Price, b.BasePrice))
 	}
 
-	display := strategy.FormatGridDisplay(b.Grid, b.BasePrice, b.cfg.AmountPerGrid)
+	display := strategy.FormatGridDisplay(b.Grid, b.BasePrice)
 	fmt.Println("\n" + display + "\n")
 
 	ticker := time.NewTicker(time.Duration(b.cfg.PriceCheckIntervalSeconds) * time.Second)
@@ -455,8 +625,18 @@ func (b *GridBot) tick(ctx context.Context) {
 		fmt.Println("Could not fetch ETH price, skipping tick")
 		return
 	}
+	b.syncCandles(ctx)
 
-	if pnl, ok := b.portfolioPnLPercent(price); ok {
+	b.exchange.Poll(ctx)
+	b.settlePending(ctx, price)
+	_ = b.checkPriceFresh()
+	if b.prices.twap != nil {
+		if err := b.prices.twap.Observe(ctx); err != nil {
+			fmt.Printf("[TWAP] Observation failed: %v\n", err)
+		}
+	}
+
+	if pnl, ok := b.portfolioPnLPercent(ctx, price); ok {
 		if err := b.guardian.PortfolioCheck(pnl); err != nil {
 			b.notify.Send(fmt.Sprintf("CIRCUIT BREAKER: %v — halting trading", err))
 			fmt.Printf("[RISK] %v\n", err)
@@ -493,41 +673,43 @@ func (b *GridBot) maybeReportStatus(ctx context.Context, currentPrice float64) {
 	}
 
 	stats := strategy.GetGridStats(b.Grid)
-	prefix := ""
-	if b.cfg.PaperTradingEnabled {
-		prefix = "[PAPER] "
+	prefix, label := "", "LIVE"
+	if b.exchange.IsPaper() {
+		prefix, label = "[PAPER] ", "PAPER"
 	}
 
-	var ethBal, usdcBal float64
-	if b.cfg.PaperTradingEnabled && b.paperWallet != nil {
-		ethBal = b.paperWallet.ETHBalance
-		usdcBal = b.paperWallet.USDCBalance
-	} else if b.uniswap != nil {
-		ethBal, _ = b.uniswap.ETHBalance(ctx)
-		usdcBal, _ = b.uniswap.TokenBalance(ctx)
+	ps, err := b.exchange.Portfolio(ctx, currentPrice)
+	if err != nil {
+		fmt.Printf("[%s] Failed to read balances for status: %v\n", label, err)
 	}
 
 	b.notify.Send(fmt.Sprintf(
-		"%sStatus: ETH @ $%.2f | ETH: %.4f ($%.2f) | USDC: %.2f | Grid: %d/%d buys, %d/%d sells | Checks: %d | Trades: %d",
+		"%sStatus: ETH @ $%.2f | ETH: %.4f ($%.2f) | USDC: %.2f | Grid: %d/%d buys, %d/%d sells | Checks: %d | Trades: %d | Realized: $%.2f",
 		prefix, currentPrice,
-		ethBal, ethBal*currentPrice, usdcBal,
+		ps.ETHBalance, ps.ETHBalance*currentPrice, ps.USDCBalance,
 		stats.FilledBuys, stats.FilledBuys+stats.PendingBuys,
 		stats.FilledSells, stats.FilledSells+stats.PendingSells,
-		b.PriceChecks, b.TradesExecuted,
+		b.PriceChecks, b.TradesExecuted, b.TotalProfit,
 	))
 
-	if b.cfg.PaperTradingEnabled && b.paperWallet != nil {
-		ps := b.paperWallet.Stats(currentPrice)
+	if err == nil {
 		sign := "+"
 		if ps.UnrealizedPnL < 0 {
 			sign = ""
 		}
+		gas := ""
+		if ps.GasSpentETH > 0 {
+			gas = fmt.Sprintf(" | Gas: %.6f ETH ($%.2f)", ps.GasSpentETH, ps.GasSpentETH*currentPrice)
+		}
 		b.notify.Send(fmt.Sprintf(
-			"[PAPER P&L] Initial: $%.2f -> Current: $%.2f | P&L: %s$%.2f (%s%.2f%%) | Gas: %.6f ETH ($%.2f) | Running: %.1fh",
-			ps.InitialValueUSD, ps.CurrentValueUSD,
+			"[%s P&L] Initial: $%.2f -> Current: $%.2f | P&L: %s$%.2f (%s%.2f%%)%s | Running: %.1fh",
+			label, ps.InitialValueUSD, ps.CurrentValueUSD,
 			sign, ps.UnrealizedPnL, sign, ps.UnrealizedPnLPct,
-			ps.TotalGasSpent, ps.GasSpentUSD, ps.RunningTimeHours,
+			gas, ps.RunningTimeHours,
 		))
+		if err := b.exchange.Snapshot(ctx, currentPrice); err != nil {
+			fmt.Printf("[%s] Failed to record portfolio snapshot: %v\n", label, err)
+		}
 	}
 
 	b.LastStatusReport = time.Now()
@@ -537,9 +719,8 @@ func (b *GridBot) Shutdown() {
 	if b.running {
 		close(b.stopCh)
 	}
-	if b.ethClient != nil {
-		b.ethClient.Close()
-	}
+	b.exchange.Close()
+	b.prices.Close()
 	fmt.Println("[BOT] Shutting down gracefully")
 }
 
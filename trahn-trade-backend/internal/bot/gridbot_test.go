@@ -0,0 +1,140 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/kjannette/trahn-backend/internal/config"
+	"github.com/kjannette/trahn-backend/internal/notifications"
+	"github.com/kjannette/trahn-backend/internal/risk"
+	"github.com/kjannette/trahn-backend/internal/strategy"
+)
+
+func TestCheckPriceFresh(t *testing.T) {
+	b := &GridBot{
+		notify:   notifications.NewSender("", "test"),
+		guardian: risk.NewGuardian(risk.Limits{MaxPriceAge: time.Minute}, nil),
+	}
+
+	// No price yet: halted.
+	if err := b.checkPriceFresh(); !errors.Is(err, risk.ErrStalePrice) {
+		t.Fatalf("expected ErrStalePrice before any price, got %v", err)
+	}
+	if !b.priceStale {
+		t.Fatal("expected halt to be recorded")
+	}
+
+	// A fresh observation resumes trading.
+	b.LastETHPrice, b.LastPriceAt = 2000, time.Now()
+	if err := b.checkPriceFresh(); err != nil {
+		t.Fatalf("expected fresh price to pass, got %v", err)
+	}
+	if b.priceStale {
+		t.Fatal("expected halt to be cleared")
+	}
+
+	// Sources failing: the last price ages out.
+	b.LastPriceAt = time.Now().Add(-2 * time.Minute)
+	if err := b.checkPriceFresh(); !errors.Is(err, risk.ErrStalePrice) {
+		t.Fatalf("expected ErrStalePrice for an old price, got %v", err)
+	}
+}
+
+func TestExecuteTrade_CappedSellAbovePrice(t *testing.T) {
+	ctx := context.Background()
+	ex := NewPaperExchange(nil, 1, 1000, 0)
+	if err := ex.Init(ctx, 2500); err != nil {
+		t.Fatal(err)
+	}
+	b := &GridBot{
+		cfg:      &config.Config{CostBasisMethod: "fifo"},
+		notify:   notifications.NewSender("", "test"),
+		guardian: risk.NewGuardian(risk.Limits{MaxPositionSizeUSD: 150}, nil),
+		exchange: ex,
+		Grid: []strategy.GridLevel{
+			{Index: 0, Price: 2400, Side: "buy", Quantity: 150.0 / 2400},
+			{Index: 1, Price: 2500, Side: "sell", Quantity: 150.0 / 2500}, // sized at the cap
+		},
+	}
+
+	// Price has moved past the level: the full quantity would be $153.
+	if err := b.executeTrade(ctx, &b.Grid[1], 2550); err != nil {
+		t.Fatalf("expected capped sell to fill, got %v", err)
+	}
+	if !b.Grid[1].Filled {
+		t.Fatal("expected sell level to be filled")
+	}
+	eth, usdc, _ := ex.Balances(ctx)
+	if sold := 1 - DefaultPaperGasCost - eth; math.Abs(sold-150.0/2550) > 1e-9 {
+		t.Fatalf("expected %.6f ETH sold, got %.6f", 150.0/2550, sold)
+	}
+	if math.Abs(usdc-1150) > 1e-9 {
+		t.Fatalf("expected $150 received, got $%.2f", usdc-1000)
+	}
+}
+
+// pendingExchange leaves every buy unconfirmed until settled is set.
+type pendingExchange struct {
+	*PaperExchange
+	settled *Fill
+}
+
+func (p *pendingExchange) Buy(context.Context, Order) (Fill, error) {
+	return Fill{}, &PendingSwapError{TxHash: "0xpending", Err: context.DeadlineExceeded}
+}
+
+func (p *pendingExchange) Settle(_ context.Context, _, txHash string) (Fill, bool, error) {
+	if p.settled == nil {
+		return Fill{}, false, nil
+	}
+	return *p.settled, true, nil
+}
+
+func TestExecuteTrade_PendingSwapHoldsLevel(t *testing.T) {
+	ctx := context.Background()
+	ex := &pendingExchange{PaperExchange: NewPaperExchange(nil, 1, 1000, 0)}
+	if err := ex.Init(ctx, 2500); err != nil {
+		t.Fatal(err)
+	}
+	b := &GridBot{
+		cfg:      &config.Config{CostBasisMethod: "fifo"},
+		notify:   notifications.NewSender("", "test"),
+		guardian: risk.NewGuardian(risk.Limits{}, nil),
+		exchange: ex,
+		Grid: []strategy.GridLevel{
+			{Index: 0, Price: 2400, Side: "buy", Quantity: 0.05},
+			{Index: 1, Price: 2500, Side: "sell", Quantity: 0.05, Filled: true},
+		},
+	}
+
+	var pending *PendingSwapError
+	if err := b.executeTrade(ctx, &b.Grid[0], 2390); !errors.As(err, &pending) {
+		t.Fatalf("expected PendingSwapError, got %v", err)
+	}
+	if b.Grid[0].PendingTx == nil || *b.Grid[0].PendingTx != "0xpending" || b.Grid[0].Filled {
+		t.Fatalf("expected level held with its tx, got %+v", b.Grid[0])
+	}
+	if l := strategy.FindTriggeredLevel(2390, b.Grid); l != nil {
+		t.Fatalf("expected held level not to trigger again, got index %d", l.Index)
+	}
+
+	// Still unconfirmed: nothing changes.
+	b.settlePending(ctx, 2390)
+	if b.Grid[0].PendingTx == nil || b.TradesExecuted != 0 {
+		t.Fatal("expected level to stay pending")
+	}
+
+	// The swap mines: the fill is recorded and the opposite level re-armed.
+	gas := 0.001
+	ex.settled = &Fill{TxHash: "0xpending", ETHAmount: 0.05, USDCAmount: 120, GasCostETH: &gas, Executed: true}
+	b.settlePending(ctx, 2390)
+	if !b.Grid[0].Filled || b.Grid[0].PendingTx != nil || b.TradesExecuted != 1 {
+		t.Fatalf("expected level filled by the settled swap, got %+v", b.Grid[0])
+	}
+	if b.Grid[1].Filled {
+		t.Fatal("expected opposite sell level to be re-armed")
+	}
+}
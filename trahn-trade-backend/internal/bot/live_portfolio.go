@@ -0,0 +1,127 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kjannette/trahn-backend/internal/models"
+	"github.com/kjannette/trahn-backend/internal/repository"
+)
+
+// balanceReader is the part of the swap client the live portfolio needs.
+type balanceReader interface {
+	ETHBalance(ctx context.Context) (float64, error)
+	TokenBalance(ctx context.Context) (float64, error)
+}
+
+// LivePortfolio tracks the real wallet's ETH/USDC balances against a
+// persisted baseline, so portfolio circuit breakers work in live mode.
+// P&L is measured the same way as PaperWallet.Stats: the starting balances
+// valued at the current price versus the current balances.
+//
+// Deposits or withdrawals show up as P&L; delete the wallet's baseline row
+// in portfolio_snapshots to re-baseline after moving funds.
+type LivePortfolio struct {
+	repo     *repository.PortfolioRepo
+	balances balanceReader
+	wallet   string
+
+	InitialETH  float64
+	InitialUSDC float64
+	ETHBalance  float64
+	USDCBalance float64
+	StartTime   time.Time
+	LastRefresh time.Time
+}
+
+// NewLivePortfolio creates a tracker for wallet. A nil repo keeps the
+// baseline in memory only.
+func NewLivePortfolio(repo *repository.PortfolioRepo, balances balanceReader, wallet string) *LivePortfolio {
+	return &LivePortfolio{repo: repo, balances: balances, wallet: wallet}
+}
+
+// Init loads the wallet's baseline, snapshotting the current balances as the
+// baseline on first run.
+func (lp *LivePortfolio) Init(ctx context.Context, currentPrice float64) error {
+	if lp.repo != nil {
+		base, err := lp.repo.GetBaseline(ctx, lp.wallet)
+		if err != nil {
+			return fmt.Errorf("load portfolio baseline: %w", err)
+		}
+		if base != nil {
+			lp.InitialETH = base.ETHBalance
+			lp.InitialUSDC = base.USDCBalance
+			lp.StartTime = base.Timestamp
+			fmt.Printf("[LIVE] Portfolio baseline from %s: %.6f ETH, %.2f USDC\n",
+				base.Timestamp.Format(time.RFC3339), base.ETHBalance, base.USDCBalance)
+			return lp.Refresh(ctx)
+		}
+	}
+
+	if err := lp.Refresh(ctx); err != nil {
+		return err
+	}
+	lp.InitialETH = lp.ETHBalance
+	lp.InitialUSDC = lp.USDCBalance
+	lp.StartTime = lp.LastRefresh
+	fmt.Printf("[LIVE] Portfolio baseline recorded: %.6f ETH, %.2f USDC\n", lp.InitialETH, lp.InitialUSDC)
+	return lp.record(ctx, currentPrice, true)
+}
+
+// Refresh reads the current balances. On error the previous balances are kept.
+func (lp *LivePortfolio) Refresh(ctx context.Context) error {
+	eth, err := lp.balances.ETHBalance(ctx)
+	if err != nil {
+		return fmt.Errorf("read ETH balance: %w", err)
+	}
+	usdc, err := lp.balances.TokenBalance(ctx)
+	if err != nil {
+		return fmt.Errorf("read token balance: %w", err)
+	}
+	lp.ETHBalance = eth
+	lp.USDCBalance = usdc
+	lp.LastRefresh = time.Now()
+	return nil
+}
+
+// RecordSnapshot persists the current balances as a periodic snapshot.
+func (lp *LivePortfolio) RecordSnapshot(ctx context.Context, currentPrice float64) error {
+	return lp.record(ctx, currentPrice, false)
+}
+
+func (lp *LivePortfolio) record(ctx context.Context, currentPrice float64, baseline bool) error {
+	if lp.repo == nil {
+		return nil
+	}
+	value := lp.ETHBalance*currentPrice + lp.USDCBalance
+	_, err := lp.repo.Record(ctx, &models.PortfolioSnapshot{
+		Timestamp:     lp.LastRefresh,
+		WalletAddress: lp.wallet,
+		ETHBalance:    lp.ETHBalance,
+		USDCBalance:   lp.USDCBalance,
+		ETHPrice:      &currentPrice,
+		ValueUSD:      &value,
+		IsBaseline:    baseline,
+	})
+	return err
+}
+
+func (lp *LivePortfolio) Stats(currentETHPrice float64) PortfolioStats {
+	initialVal := lp.InitialETH*currentETHPrice + lp.InitialUSDC
+	currentVal := lp.ETHBalance*currentETHPrice + lp.USDCBalance
+	pnl := currentVal - initialVal
+	pnlPct := 0.0
+	if initialVal > 0 {
+		pnlPct = pnl / initialVal * 100
+	}
+	return PortfolioStats{
+		ETHBalance:       lp.ETHBalance,
+		USDCBalance:      lp.USDCBalance,
+		InitialValueUSD:  initialVal,
+		CurrentValueUSD:  currentVal,
+		UnrealizedPnL:    pnl,
+		UnrealizedPnLPct: pnlPct,
+		RunningTimeHours: time.Since(lp.StartTime).Hours(),
+	}
+}
@@ -0,0 +1,48 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+)
+
+type fakeBalances struct {
+	eth, usdc float64
+	err       error
+}
+
+func (f *fakeBalances) ETHBalance(context.Context) (float64, error)   { return f.eth, f.err }
+func (f *fakeBalances) TokenBalance(context.Context) (float64, error) { return f.usdc, f.err }
+
+func TestLivePortfolio(t *testing.T) {
+	ctx := context.Background()
+	bal := &fakeBalances{eth: 1, usdc: 1000}
+	lp := NewLivePortfolio(nil, bal, "0xabc")
+
+	if err := lp.Init(ctx, 2000); err != nil {
+		t.Fatal(err)
+	}
+	if lp.InitialETH != 1 || lp.InitialUSDC != 1000 {
+		t.Fatalf("expected baseline 1 ETH / 1000 USDC, got %f / %f", lp.InitialETH, lp.InitialUSDC)
+	}
+
+	// 0.5 ETH sold for only $700: down $300 of $3000.
+	bal.eth, bal.usdc = 0.5, 1700
+	if err := lp.Refresh(ctx); err != nil {
+		t.Fatal(err)
+	}
+	s := lp.Stats(2000)
+	if math.Abs(s.UnrealizedPnLPct-(-10)) > 1e-9 {
+		t.Fatalf("expected -10%%, got %.4f%%", s.UnrealizedPnLPct)
+	}
+
+	// Failed reads keep the last good balances.
+	bal.err = errors.New("rpc down")
+	if err := lp.Refresh(ctx); err == nil {
+		t.Fatal("expected refresh error")
+	}
+	if lp.ETHBalance != 0.5 || lp.USDCBalance != 1700 {
+		t.Fatal("balances should be unchanged after a failed refresh")
+	}
+}
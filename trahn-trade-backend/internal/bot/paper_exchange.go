@@ -0,0 +1,115 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kjannette/trahn-backend/internal/repository"
+)
+
+var _ Exchange = (*PaperExchange)(nil)
+
+// PaperExchange simulates fills against a PaperWallet: random slippage up
+// to maxSlippagePct on the side received and a flat gas charge per swap.
+type PaperExchange struct {
+	wallet         *PaperWallet
+	maxSlippagePct float64
+	gasCostETH     float64
+}
+
+// NewPaperExchange creates a paper venue. A nil gridRepo keeps the wallet
+// in memory only.
+func NewPaperExchange(gridRepo *repository.GridStateRepo, initialETH, initialUSDC, maxSlippagePct float64) *PaperExchange {
+	return &PaperExchange{
+		wallet:         NewPaperWallet(gridRepo, initialETH, initialUSDC),
+		maxSlippagePct: maxSlippagePct,
+		gasCostETH:     DefaultPaperGasCost,
+	}
+}
+
+func (p *PaperExchange) IsPaper() bool { return true }
+
+func (p *PaperExchange) Init(ctx context.Context, _ float64) error {
+	if err := p.wallet.Init(ctx); err != nil {
+		return fmt.Errorf("paper wallet init: %w", err)
+	}
+	return nil
+}
+
+// Quote fills at the reference price before slippage.
+func (p *PaperExchange) Quote(_ context.Context, _ string, _, refPrice float64) (float64, error) {
+	return refPrice, nil
+}
+
+func (p *PaperExchange) Buy(ctx context.Context, o Order) (Fill, error) {
+	return p.fill(ctx, "buy", o)
+}
+
+func (p *PaperExchange) Sell(ctx context.Context, o Order) (Fill, error) {
+	return p.fill(ctx, "sell", o)
+}
+
+func (p *PaperExchange) fill(ctx context.Context, side string, o Order) (Fill, error) {
+	slip := randomSlippage(p.maxSlippagePct)
+	gas := p.gasCostETH
+
+	fill, err := p.wallet.Fill(ctx, side, o.ETHAmount, o.USDCAmount, slip, gas)
+	if err != nil {
+		return Fill{}, err
+	}
+
+	p.wallet.RecordTrade(ctx, PaperTrade{
+		Side: side, GridLevel: o.GridLevel,
+		TriggerPrice: o.TriggerPrice, ExecutionPrice: o.Price,
+		ETHAmount: fill.ETHAmount, USDCAmount: fill.USDCAmount,
+		SlippagePct: fill.SlippagePct, GasCost: gas,
+	})
+
+	s := fill.SlippagePct
+	fmt.Printf("[PAPER] %s executed: %.6f ETH for %.2f USDC (slippage: %.3f%%, gas: %.6f ETH)\n",
+		side, fill.ETHAmount, fill.USDCAmount, s, gas)
+	return Fill{
+		TxHash:      fmt.Sprintf("0xPAPER_%s_%x", side, time.Now().UnixNano()),
+		ETHAmount:   fill.ETHAmount,
+		USDCAmount:  fill.USDCAmount,
+		SlippagePct: &s,
+		GasCostETH:  &gas,
+	}, nil
+}
+
+func (p *PaperExchange) Balances(context.Context) (float64, float64, error) {
+	return p.wallet.ETHBalance, p.wallet.USDCBalance, nil
+}
+
+func (p *PaperExchange) GasEstimateETH(context.Context) (float64, error) {
+	return p.gasCostETH, nil
+}
+
+func (p *PaperExchange) CanTrade(context.Context) error { return nil }
+
+// Settle is never needed: paper fills are immediate.
+func (p *PaperExchange) Settle(_ context.Context, _, txHash string) (Fill, bool, error) {
+	return Fill{}, true, fmt.Errorf("paper exchange has no pending swap %s", txHash)
+}
+
+func (p *PaperExchange) Portfolio(_ context.Context, currentPrice float64) (PortfolioStats, error) {
+	ps := p.wallet.Stats(currentPrice)
+	return PortfolioStats{
+		ETHBalance:       ps.CurrentETH,
+		USDCBalance:      ps.CurrentUSDC,
+		InitialValueUSD:  ps.InitialValueUSD,
+		CurrentValueUSD:  ps.CurrentValueUSD,
+		UnrealizedPnL:    ps.UnrealizedPnL,
+		UnrealizedPnLPct: ps.UnrealizedPnLPct,
+		GasSpentETH:      ps.TotalGasSpent,
+		RunningTimeHours: ps.RunningTimeHours,
+	}, nil
+}
+
+// Snapshot is a no-op: the paper wallet is persisted on every fill.
+func (p *PaperExchange) Snapshot(context.Context, float64) error { return nil }
+
+func (p *PaperExchange) Poll(context.Context) {}
+
+func (p *PaperExchange) Close() {}
@@ -41,6 +41,8 @@ type BalSnap struct {
 	USDC float64 `json:"usdc"`
 }
 
+// NewPaperWallet creates a simulated wallet. A nil gridRepo yields a purely
+// in-memory wallet that never persists, which is what backtests use.
 func NewPaperWallet(gridRepo *repository.GridStateRepo, initialETH, initialUSDC float64) *PaperWallet {
 	return &PaperWallet{
 		gridRepo:    gridRepo,
@@ -53,6 +55,9 @@ func NewPaperWallet(gridRepo *repository.GridStateRepo, initialETH, initialUSDC
 }
 
 func (pw *PaperWallet) Init(ctx context.Context) error {
+	if pw.gridRepo == nil {
+		return nil // in-memory wallet
+	}
 	state, err := pw.gridRepo.GetPaperWallet(ctx)
 	if err != nil {
 		return fmt.Errorf("load paper state: %w", err)
@@ -83,6 +88,9 @@ func (pw *PaperWallet) Init(ctx context.Context) error {
 }
 
 func (pw *PaperWallet) save(ctx context.Context) {
+	if pw.gridRepo == nil {
+		return // in-memory wallet (e.g. backtests)
+	}
 	tradesJSON, _ := json.Marshal(pw.Trades)
 	err := pw.gridRepo.UpdatePaperWallet(ctx, &models.PaperWallet{
 		ETHBalance:    pw.ETHBalance,
@@ -121,6 +129,41 @@ func (pw *PaperWallet) DeductGas(ctx context.Context, gasETH float64) {
 	pw.save(ctx)
 }
 
+// PaperFill is the outcome of a simulated swap after slippage and gas.
+type PaperFill struct {
+	ETHAmount   float64
+	USDCAmount  float64
+	SlippagePct float64
+	GasCost     float64
+}
+
+// Fill simulates a swap against the wallet. slip is a fraction (0.005 = 0.5%)
+// taken from the side being received; gas is always deducted from ETH.
+func (pw *PaperWallet) Fill(ctx context.Context, side string, ethAmount, usdcAmount, slip, gas float64) (PaperFill, error) {
+	if side == "buy" {
+		ethAmount = ethAmount * (1 - slip)
+		if err := pw.ExecuteBuy(ctx, usdcAmount, ethAmount); err != nil {
+			return PaperFill{}, err
+		}
+	} else {
+		if pw.ETHBalance < ethAmount+gas {
+			return PaperFill{}, fmt.Errorf("insufficient ETH: have %.6f, need %.6f", pw.ETHBalance, ethAmount+gas)
+		}
+		usdcAmount = usdcAmount * (1 - slip)
+		if err := pw.ExecuteSell(ctx, ethAmount, usdcAmount); err != nil {
+			return PaperFill{}, err
+		}
+	}
+
+	pw.DeductGas(ctx, gas)
+	return PaperFill{
+		ETHAmount:   ethAmount,
+		USDCAmount:  usdcAmount,
+		SlippagePct: slip * 100,
+		GasCost:     gas,
+	}, nil
+}
+
 func (pw *PaperWallet) RecordTrade(ctx context.Context, t PaperTrade) {
 	t.ID = len(pw.Trades) + 1
 	t.Timestamp = time.Now().UTC().Format(time.RFC3339)
@@ -184,4 +227,6 @@ func randomSlippage(maxPct float64) float64 {
 	return rand.Float64() * maxPct / 100
 }
 
-const defaultPaperGasCost = 0.005
+// DefaultPaperGasCost is the flat per-swap gas charge (in ETH) used by the
+// paper wallet and backtests.
+const DefaultPaperGasCost = 0.005
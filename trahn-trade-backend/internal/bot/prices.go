@@ -0,0 +1,120 @@
+package bot
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kjannette/trahn-backend/internal/config"
+	"github.com/kjannette/trahn-backend/internal/ethereum"
+	"github.com/kjannette/trahn-backend/internal/external"
+	"github.com/kjannette/trahn-backend/internal/oracle"
+)
+
+// chainlinkMaxAge refuses Chainlink rounds older than twice the ETH/USD
+// feed's one-hour heartbeat.
+const chainlinkMaxAge = 2 * time.Hour
+
+// priceFeeds are the bot's price inputs: the aggregated ETH price and, when
+// configured, the pool TWAP used as a reference for execution prices.
+type priceFeeds struct {
+	agg  *oracle.Aggregator
+	twap *ethereum.TWAP   // nil unless listed in PRICE_SOURCES or the TWAP guard is on
+	rpc  *ethereum.Client // read-only client shared by on-chain sources
+}
+
+func (p *priceFeeds) Close() {
+	if p.rpc != nil {
+		p.rpc.Close()
+	}
+}
+
+// newPriceFeeds builds the aggregator over PRICE_SOURCES. On-chain sources
+// share one read-only RPC client and are skipped when no
+// ETHEREUM_API_ENDPOINT is configured. A single TWAP instance serves both as
+// a source and for the execution guard, so it accumulates one history.
+func newPriceFeeds(cfg *config.Config) *priceFeeds {
+	var sources []oracle.PriceSource
+	var rpc *ethereum.Client
+	feeds := &priceFeeds{}
+
+	onChain := func(name string) *ethereum.Client {
+		if rpc != nil {
+			return rpc
+		}
+		if cfg.EthereumAPIEndpoint == "" {
+			fmt.Printf("[PRICE] Skipping %s: ETHEREUM_API_ENDPOINT not set\n", name)
+			return nil
+		}
+		c, err := ethereum.NewReadClient(cfg.EthereumAPIEndpoint)
+		if err != nil {
+			fmt.Printf("[PRICE] Skipping %s: %v\n", name, err)
+			return nil
+		}
+		rpc = c
+		return rpc
+	}
+
+	twap := func(name string) *ethereum.TWAP {
+		if feeds.twap != nil {
+			return feeds.twap
+		}
+		c := onChain(name)
+		if c == nil {
+			return nil
+		}
+		pair, err := ethereum.NewV2Pair(c, cfg.UniswapV2FactoryAddress, cfg.WETHAddress, cfg.QuoteTokenAddress, cfg.QuoteTokenDecimals)
+		if err != nil {
+			fmt.Printf("[PRICE] Skipping %s: %v\n", name, err)
+			return nil
+		}
+		feeds.twap = ethereum.NewTWAP(pair, time.Duration(cfg.TWAPWindowMinutes)*time.Minute)
+		return feeds.twap
+	}
+
+	for _, name := range cfg.PriceSources {
+		switch name {
+		case "coingecko":
+			sources = append(sources, external.NewCoinGeckoClient())
+		case "chainlink":
+			if c := onChain(name); c != nil {
+				feed, err := ethereum.NewChainlinkFeed(c, cfg.ChainlinkETHUSDAddress, chainlinkMaxAge)
+				if err != nil {
+					fmt.Printf("[PRICE] Skipping %s: %v\n", name, err)
+					continue
+				}
+				sources = append(sources, feed)
+			}
+		case "uniswap":
+			if c := onChain(name); c != nil {
+				pair, err := ethereum.NewV2Pair(c, cfg.UniswapV2FactoryAddress, cfg.WETHAddress, cfg.QuoteTokenAddress, cfg.QuoteTokenDecimals)
+				if err != nil {
+					fmt.Printf("[PRICE] Skipping %s: %v\n", name, err)
+					continue
+				}
+				sources = append(sources, pair)
+			}
+		case "twap":
+			if t := twap(name); t != nil {
+				sources = append(sources, t)
+			}
+		}
+	}
+	if cfg.TWAPMaxDeviationPercent > 0 && twap("twap guard") == nil {
+		fmt.Println("[PRICE] TWAP guard disabled: no pool TWAP available")
+	}
+	if len(sources) == 0 {
+		fmt.Println("[PRICE] No usable price source configured - falling back to CoinGecko")
+		sources = append(sources, external.NewCoinGeckoClient())
+	}
+
+	minSources := cfg.PriceMinSources
+	if minSources > len(sources) {
+		fmt.Printf("[PRICE] PRICE_MIN_SOURCES=%d but only %d source(s) available - requiring %d\n",
+			minSources, len(sources), len(sources))
+		minSources = len(sources)
+	}
+	feeds.agg = oracle.NewAggregator(sources, cfg.PriceMaxSourceDeviation, minSources)
+	feeds.rpc = rpc
+	fmt.Printf("[PRICE] Price sources: %v\n", feeds.agg.Sources())
+	return feeds
+}
@@ -24,10 +24,14 @@ func NewService() *Service {
 
 func (s *Service) Start(ctx context.Context, cfg *config.Config,
 	priceRepo *repository.PriceRepo,
+	candleRepo *repository.CandleRepo,
 	tradeRepo *repository.TradeRepo,
 	gridRepo *repository.GridStateRepo,
+	lotRepo *repository.LotRepo,
+	portRepo *repository.PortfolioRepo,
+	txRepo *repository.PendingTxRepo,
 	notify *notifications.Sender,
-	dune *external.DuneClient,
+	srSource external.SRProvider,
 ) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -43,7 +47,7 @@ func (s *Service) Start(ctx context.Context, cfg *config.Config,
 	}
 	notify.Send(fmt.Sprintf("Starting ETH Grid Trader (ETH/%s) - %s", cfg.QuoteTokenSymbol, mode))
 
-	b := NewGridBot(cfg, priceRepo, tradeRepo, gridRepo, notify, dune)
+	b := NewGridBot(cfg, priceRepo, candleRepo, tradeRepo, gridRepo, lotRepo, portRepo, txRepo, notify, srSource)
 	if err := b.Init(ctx); err != nil {
 		return fmt.Errorf("bot init: %w", err)
 	}
@@ -0,0 +1,311 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kjannette/trahn-backend/internal/config"
+	"github.com/kjannette/trahn-backend/internal/ethereum"
+	"github.com/kjannette/trahn-backend/internal/notifications"
+	"github.com/kjannette/trahn-backend/internal/repository"
+)
+
+var _ Exchange = (*UniswapExchange)(nil)
+
+// UniswapExchange trades the configured wallet on Uniswap (V2 or V3 per
+// UNISWAP_VERSION), waiting for each swap to confirm and reporting the
+// amounts decoded from its receipt.
+type UniswapExchange struct {
+	cfg      *config.Config
+	portRepo *repository.PortfolioRepo
+	txRepo   *repository.PendingTxRepo
+	notify   *notifications.Sender
+
+	client    *ethereum.Client
+	nonces    *ethereum.NonceManager
+	dex       ethereum.Swapper
+	portfolio *LivePortfolio
+}
+
+// NewUniswapExchange creates a live venue; Init connects it. Nil repos keep
+// the portfolio baseline and nonce state in memory only.
+func NewUniswapExchange(cfg *config.Config, portRepo *repository.PortfolioRepo, txRepo *repository.PendingTxRepo, notify *notifications.Sender) *UniswapExchange {
+	return &UniswapExchange{cfg: cfg, portRepo: portRepo, txRepo: txRepo, notify: notify}
+}
+
+func (u *UniswapExchange) IsPaper() bool { return false }
+
+func (u *UniswapExchange) Init(ctx context.Context, currentPrice float64) error {
+	signer, err := u.newSigner(ctx)
+	if err != nil {
+		return err
+	}
+	ethC, err := ethereum.NewClient(
+		u.cfg.EthereumAPIEndpoint,
+		signer,
+		int64(u.cfg.ChainID),
+		u.cfg.GasLimit,
+		u.cfg.GasMultiplier,
+		u.cfg.GasLimitMarginPercent,
+		u.cfg.EIP1559Enabled,
+		u.cfg.MaxFeePerGasGwei,
+	)
+	if err != nil {
+		return fmt.Errorf("ethereum client: %w", err)
+	}
+	u.client = ethC
+
+	var store ethereum.TxStore
+	if u.txRepo != nil {
+		store = u.txRepo
+	}
+	u.nonces = ethereum.NewNonceManager(ethC, store, uint64(u.cfg.TxStuckBlocks), u.cfg.TxMaxReplacements)
+	if err := u.nonces.Load(ctx); err != nil {
+		return fmt.Errorf("nonce manager: %w", err)
+	}
+
+	dex, err := u.newSwapper(ethC)
+	if err != nil {
+		return err
+	}
+	u.dex = dex
+	fmt.Printf("[LIVE] Ethereum client connected, wallet %s, trading on %s\n", ethC.WalletAddress().Hex(), dex.Name())
+
+	u.portfolio = NewLivePortfolio(u.portRepo, dex, ethC.WalletAddress().Hex())
+	if err := u.portfolio.Init(ctx, currentPrice); err != nil {
+		return fmt.Errorf("live portfolio init: %w", err)
+	}
+	return nil
+}
+
+// newSigner builds the transaction signer selected by SIGNER_TYPE and checks
+// that it signs as WALLET_ADDRESS.
+func (u *UniswapExchange) newSigner(ctx context.Context) (ethereum.Signer, error) {
+	s, err := u.openSigner(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := ethereum.CheckSignerAddress(s, u.cfg.WalletAddress); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (u *UniswapExchange) openSigner(ctx context.Context) (ethereum.Signer, error) {
+	switch u.cfg.SignerType {
+	case "keystore":
+		pass, err := ethereum.ReadPassphrase(u.cfg.KeystorePassFile)
+		if err != nil {
+			return nil, err
+		}
+		s, err := ethereum.NewKeystoreSigner(u.cfg.KeystorePath, pass)
+		if err != nil {
+			return nil, fmt.Errorf("keystore signer: %w", err)
+		}
+		return s, nil
+	case "remote":
+		s, err := ethereum.NewRemoteSigner(ctx, u.cfg.RemoteSignerURL, u.cfg.WalletAddress)
+		if err != nil {
+			return nil, fmt.Errorf("remote signer: %w", err)
+		}
+		return s, nil
+	default:
+		s, err := ethereum.NewKeySigner(u.cfg.PrivateKey)
+		if err != nil {
+			return nil, fmt.Errorf("ethereum client: %w", err)
+		}
+		return s, nil
+	}
+}
+
+// newSwapper builds the execution venue selected by UNISWAP_VERSION.
+func (u *UniswapExchange) newSwapper(ethC *ethereum.Client) (ethereum.Swapper, error) {
+	if u.cfg.UniswapVersion == "v3" {
+		uni, err := ethereum.NewUniswapV3(
+			ethC,
+			u.cfg.UniswapV3RouterAddress,
+			u.cfg.UniswapV3QuoterAddress,
+			u.cfg.WETHAddress,
+			u.cfg.QuoteTokenAddress,
+			u.cfg.QuoteTokenSymbol,
+			u.cfg.QuoteTokenDecimals,
+			uint32(u.cfg.UniswapV3FeeTier),
+			u.cfg.SlippageTolerance,
+			u.cfg.MaxPriceDeviationPercent,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("uniswap v3 client: %w", err)
+		}
+		return uni, nil
+	}
+
+	uni, err := ethereum.NewUniswapV2(
+		ethC,
+		u.cfg.UniswapRouterAddress,
+		u.cfg.WETHAddress,
+		u.cfg.QuoteTokenAddress,
+		u.cfg.QuoteTokenSymbol,
+		u.cfg.QuoteTokenDecimals,
+		u.cfg.SlippageTolerance,
+		u.cfg.MaxPriceDeviationPercent,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("uniswap client: %w", err)
+	}
+	return uni, nil
+}
+
+// Quote asks the pool what it would pay. refPrice sizes the USDC side of a buy.
+func (u *UniswapExchange) Quote(ctx context.Context, side string, ethAmount, refPrice float64) (float64, error) {
+	var q *ethereum.Quote
+	var err error
+	if side == "buy" {
+		q, err = u.dex.QuoteBuy(ctx, ethAmount*refPrice)
+	} else {
+		q, err = u.dex.QuoteSell(ctx, ethAmount)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return q.Price, nil
+}
+
+func (u *UniswapExchange) Buy(ctx context.Context, o Order) (Fill, error) {
+	u.notify.Send(fmt.Sprintf("Broadcasting BUY TX: %.6f ETH for %.2f USDC...", o.ETHAmount, o.USDCAmount))
+	hash, err := u.dex.SwapUSDCForETH(ctx, o.USDCAmount, o.Price)
+	return u.settle(ctx, "buy", hash, err)
+}
+
+func (u *UniswapExchange) Sell(ctx context.Context, o Order) (Fill, error) {
+	u.notify.Send(fmt.Sprintf("Broadcasting SELL TX: %.6f ETH for ~%.2f USDC...", o.ETHAmount, o.USDCAmount))
+	hash, err := u.dex.SwapETHForUSDC(ctx, o.ETHAmount, o.Price)
+	return u.settle(ctx, "sell", hash, err)
+}
+
+// settle waits for a broadcast swap to confirm and returns the amounts
+// decoded from the receipt.
+func (u *UniswapExchange) settle(ctx context.Context, side, hash string, swapErr error) (Fill, error) {
+	var rev *ethereum.RevertError
+	if errors.As(swapErr, &rev) {
+		// Nothing was broadcast, so no gas was spent and the level stays armed.
+		// A moved price is routine; wallet and pool problems need a human.
+		if errors.Is(rev, ethereum.ErrInsufficientOutput) || errors.Is(rev, ethereum.ErrDeadlineExpired) {
+			fmt.Printf("[SIM] %s not sent, retrying next tick: %v\n", side, rev)
+		} else {
+			u.notify.Send(fmt.Sprintf("%s not sent, simulation failed: %v", side, rev))
+		}
+		return Fill{}, fmt.Errorf("swap %s: %w", side, swapErr)
+	}
+	if swapErr != nil {
+		u.notify.Send(fmt.Sprintf("%s TX failed: %v", side, swapErr))
+		return Fill{}, fmt.Errorf("swap failed (%s): %w", side, swapErr)
+	}
+
+	url := u.dex.ExplorerURL(hash)
+	fmt.Printf("[LIVE] %s TX sent: %s, waiting for %d confirmation(s)\n", side, url, u.cfg.TxConfirmations)
+
+	res, err := u.dex.WaitForSwap(ctx, hash, side, ethereum.ReceiptOptions{
+		Confirmations: uint64(u.cfg.TxConfirmations),
+		Timeout:       time.Duration(u.cfg.TxReceiptTimeoutSeconds) * time.Second,
+	})
+	if err != nil {
+		if errors.Is(err, ethereum.ErrTxReverted) && res != nil {
+			u.notify.Send(fmt.Sprintf("%s TX REVERTED: %s (gas burned: %.6f ETH)", side, url, res.GasCostETH))
+		} else if errors.Is(err, ethereum.ErrTxCancelled) && res != nil {
+			u.notify.Send(fmt.Sprintf("%s TX stuck and cancelled: %s (gas burned: %.6f ETH)", side, url, res.GasCostETH))
+		} else if errors.Is(err, ethereum.ErrTxDropped) {
+			u.notify.Send(fmt.Sprintf("%s TX dropped, its nonce was used by another transaction: %s", side, url))
+		} else {
+			// Timed out or lost the RPC: the swap may still fill, so the
+			// level is held until Settle sees it resolve.
+			u.notify.Send(fmt.Sprintf("%s TX not confirmed yet: %v — holding the level until it settles: %s", side, err, url))
+			return Fill{}, &PendingSwapError{TxHash: hash, Err: err}
+		}
+		return Fill{}, fmt.Errorf("swap %s (%s): %w", hash, side, err)
+	}
+	return u.confirmed(side, url, res), nil
+}
+
+// Settle checks once on a swap that settle left pending. Errors reading the
+// receipt count as still pending, so the check is retried on the next poll.
+func (u *UniswapExchange) Settle(ctx context.Context, side, hash string) (Fill, bool, error) {
+	url := u.dex.ExplorerURL(hash)
+	res, err := u.dex.WaitForSwap(ctx, hash, side, ethereum.ReceiptOptions{
+		Confirmations: uint64(u.cfg.TxConfirmations),
+		Once:          true,
+	})
+	switch {
+	case err == nil:
+		return u.confirmed(side, url, res), true, nil
+	case errors.Is(err, ethereum.ErrTxReverted), errors.Is(err, ethereum.ErrTxCancelled), errors.Is(err, ethereum.ErrTxDropped):
+		u.notify.Send(fmt.Sprintf("Pending %s TX did not fill: %v — level re-armed: %s", side, err, url))
+		return Fill{}, true, fmt.Errorf("swap %s (%s): %w", hash, side, err)
+	case !errors.Is(err, ethereum.ErrTxPending):
+		fmt.Printf("[LIVE] Checking pending %s TX %s: %v\n", side, hash, err)
+	}
+	return Fill{}, false, nil
+}
+
+// confirmed reports a confirmed swap and returns its fill.
+func (u *UniswapExchange) confirmed(side, url string, res *ethereum.SwapResult) Fill {
+	u.notify.Send(fmt.Sprintf("%s TX confirmed in block %d: %.6f ETH for %.2f USDC (@ $%.2f/ETH, gas %.6f ETH): %s",
+		side, res.BlockNumber, res.ETHAmount, res.TokenAmount, res.Price(), res.GasCostETH, url))
+	gas := res.GasCostETH
+	return Fill{
+		TxHash:     res.TxHash,
+		ETHAmount:  res.ETHAmount,
+		USDCAmount: res.TokenAmount,
+		GasCostETH: &gas,
+		Executed:   true,
+	}
+}
+
+func (u *UniswapExchange) Balances(ctx context.Context) (float64, float64, error) {
+	eth, err := u.dex.ETHBalance(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	usdc, err := u.dex.TokenBalance(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	return eth, usdc, nil
+}
+
+func (u *UniswapExchange) GasEstimateETH(ctx context.Context) (float64, error) {
+	return u.dex.GasCostETH(ctx)
+}
+
+// CanTrade also holds trading while the nonce manager has an unresolved
+// transaction, so a new swap never queues behind one that may still fill.
+func (u *UniswapExchange) CanTrade(ctx context.Context) error {
+	if n := u.nonces.Pending(); n > 0 {
+		return fmt.Errorf("%d transaction(s) still pending", n)
+	}
+	return u.client.CheckFeeCap(ctx)
+}
+
+func (u *UniswapExchange) Portfolio(ctx context.Context, currentPrice float64) (PortfolioStats, error) {
+	if err := u.portfolio.Refresh(ctx); err != nil {
+		return PortfolioStats{}, err
+	}
+	return u.portfolio.Stats(currentPrice), nil
+}
+
+func (u *UniswapExchange) Snapshot(ctx context.Context, currentPrice float64) error {
+	return u.portfolio.RecordSnapshot(ctx, currentPrice)
+}
+
+func (u *UniswapExchange) Poll(ctx context.Context) {
+	if err := u.nonces.Check(ctx); err != nil {
+		fmt.Printf("[NONCE] Check failed: %v\n", err)
+	}
+}
+
+func (u *UniswapExchange) Close() {
+	if u.client != nil {
+		u.client.Close()
+	}
+}
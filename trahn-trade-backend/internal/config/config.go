@@ -20,6 +20,12 @@ type Config struct {
 	APIKey              string
 	CORSAllowOrigin     string
 
+	// Transaction signing (live mode)
+	SignerType       string // key, keystore or remote
+	KeystorePath     string
+	KeystorePassFile string
+	RemoteSignerURL  string
+
 	// Database
 	DBHost     string
 	DBPort     int
@@ -35,7 +41,23 @@ type Config struct {
 	WETHAddress          string
 	UniswapRouterAddress string
 
+	// Execution venue
+	UniswapVersion         string // v2 or v3
+	UniswapV3RouterAddress string
+	UniswapV3QuoterAddress string
+	UniswapV3FeeTier       int // pool fee in hundredths of a bip; 0 = auto
+
+	// Price oracle
+	PriceSources            []string // coingecko, chainlink, uniswap, twap
+	PriceMaxSourceDeviation float64  // percent from the median before a source is rejected
+	PriceMinSources         int
+	ChainlinkETHUSDAddress  string
+	UniswapV2FactoryAddress string
+	TWAPWindowMinutes       int
+	TWAPMaxDeviationPercent float64 // execution price vs TWAP before a trade is deferred; 0 disables
+
 	// Support/Resistance
+	SRProvider     string // dune, local, or auto (dune when DUNE_API_KEY is set)
 	SRMethod       string
 	SRRefreshHours int
 	SRLookbackDays int
@@ -45,6 +67,7 @@ type Config struct {
 	MaxPositionSizeUSD float64
 	StopLossPercent    float64
 	TakeProfitPercent  float64
+	MaxPriceAgeSeconds int // trading halts while the last price is older; 0 disables
 
 	// Paper Trading
 	PaperTradingEnabled  bool
@@ -53,17 +76,48 @@ type Config struct {
 	PaperSlippagePercent float64
 	PaperSimulateGas     bool
 
+	// Accounting
+	CostBasisMethod string // fifo, lifo or hifo
+
 	// Grid Configuration
 	GridLevels         int
 	GridSpacingPercent float64
 	GridBasePrice      float64
 	AmountPerGrid      float64
+	GridMode           string // centered (on the S/R midpoint) or bounded (by support/resistance)
+	GridLayout         string // geometric (equal %) or arithmetic (equal $) steps
+	GridBuyLevels      int    // with GridSellLevels, replaces the even split of GridLevels
+	GridSellLevels     int
+	GridLadder         []float64 // explicit level prices; overrides levels, spacing and mode
+
+	// Position sizing per level (AmountPerGrid is the base size)
+	SizingMode                 string // quote, base, pyramid or balance
+	SizingPyramidMultiplier    float64
+	SizingPyramidMaxMultiplier float64
+	SizingBalancePercent       float64
+
+	// Volatility-adaptive spacing (GridSpacingPercent is used in fixed mode)
+	GridSpacingMode             string // fixed, atr or volatility
+	GridSpacingMultiplier       float64
+	GridSpacingMinPercent       float64
+	GridSpacingMaxPercent       float64
+	GridVolatilityLookbackHours int
 
 	// Trading Parameters
-	SlippageTolerance float64
-	GasMultiplier     float64
-	MinProfitPercent  float64
-	GasLimit          int
+	SlippageTolerance        float64
+	MaxPriceDeviationPercent float64
+	GasMultiplier            float64
+	MinProfitPercent         float64
+	GasLimit                 int
+	GasLimitMarginPercent    float64
+	EIP1559Enabled           bool
+	MaxFeePerGasGwei         float64
+
+	// Transaction confirmation (live mode)
+	TxConfirmations         int
+	TxReceiptTimeoutSeconds int
+	TxStuckBlocks           int
+	TxMaxReplacements       int
 
 	// Timing
 	PriceCheckIntervalSeconds  int
@@ -85,6 +139,12 @@ func Load() (*Config, error) {
 		APIKey:              envStr("API_KEY", ""),
 		CORSAllowOrigin:     envStr("CORS_ALLOW_ORIGIN", "*"),
 
+		// Transaction signing
+		SignerType:       envStr("SIGNER_TYPE", "key"),
+		KeystorePath:     envStr("KEYSTORE_PATH", ""),
+		KeystorePassFile: envStr("KEYSTORE_PASSWORD_FILE", ""),
+		RemoteSignerURL:  envStr("REMOTE_SIGNER_URL", ""),
+
 		// Database
 		DBHost:     envStr("DB_HOST", "localhost"),
 		DBPort:     envInt("DB_PORT", 5432),
@@ -100,7 +160,23 @@ func Load() (*Config, error) {
 		WETHAddress:          "0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2",
 		UniswapRouterAddress: "0x7a250d5630B4cF539739dF2C5dAcb4c659F2488D",
 
+		// Execution venue
+		UniswapVersion:         strings.ToLower(envStr("UNISWAP_VERSION", "v2")),
+		UniswapV3RouterAddress: "0xE592427A0AEce92De3Edee1F18E0157C05861564",
+		UniswapV3QuoterAddress: "0x61fFE014bA17989E743c5F6cB21bF9697530B21e",
+		UniswapV3FeeTier:       envInt("UNISWAP_V3_FEE_TIER", 0),
+
+		// Price oracle
+		PriceSources:            envList("PRICE_SOURCES", "coingecko,chainlink,uniswap"),
+		PriceMaxSourceDeviation: envFloat("PRICE_MAX_SOURCE_DEVIATION_PERCENT", 1),
+		PriceMinSources:         envInt("PRICE_MIN_SOURCES", 1),
+		ChainlinkETHUSDAddress:  envStr("CHAINLINK_ETH_USD_ADDRESS", "0x5f4eC3Df9cbd43714FE2740f5E3616155c5b8419"),
+		UniswapV2FactoryAddress: "0x5C69bEe701ef814a2B6a3EDD4B1652CB9cc5aA6f",
+		TWAPWindowMinutes:       envInt("TWAP_WINDOW_MINUTES", 30),
+		TWAPMaxDeviationPercent: envFloat("TWAP_MAX_DEVIATION_PERCENT", 0),
+
 		// Support/Resistance
+		SRProvider:     strings.ToLower(envStr("SR_PROVIDER", "auto")),
 		SRMethod:       envStr("SR_METHOD", "simple"),
 		SRRefreshHours: envInt("SR_REFRESH_HOURS", 48),
 		SRLookbackDays: envInt("SR_LOOKBACK_DAYS", 14),
@@ -110,6 +186,7 @@ func Load() (*Config, error) {
 		MaxPositionSizeUSD: envFloat("MAX_POSITION_SIZE_USD", 10000),
 		StopLossPercent:    envFloat("STOP_LOSS_PERCENT", 0),
 		TakeProfitPercent:  envFloat("TAKE_PROFIT_PERCENT", 0),
+		MaxPriceAgeSeconds: envInt("MAX_PRICE_AGE_SECONDS", 180),
 
 		// Paper Trading
 		PaperTradingEnabled:  envBool("PAPER_TRADING_ENABLED", true),
@@ -118,17 +195,45 @@ func Load() (*Config, error) {
 		PaperSlippagePercent: envFloat("PAPER_SLIPPAGE_PERCENT", 0.5),
 		PaperSimulateGas:     envBool("PAPER_SIMULATE_GAS", true),
 
+		// Accounting
+		CostBasisMethod: strings.ToLower(envStr("COST_BASIS_METHOD", "fifo")),
+
 		// Grid
 		GridLevels:         envInt("GRID_LEVELS", 10),
 		GridSpacingPercent: envFloat("GRID_SPACING_PERCENT", 2),
 		GridBasePrice:      envFloat("GRID_BASE_PRICE", 0),
 		AmountPerGrid:      envFloat("AMOUNT_PER_GRID", 100),
+		GridMode:           strings.ToLower(envStr("GRID_MODE", "centered")),
+		GridLayout:         strings.ToLower(envStr("GRID_LAYOUT", "geometric")),
+		GridBuyLevels:      envInt("GRID_BUY_LEVELS", 0),
+		GridSellLevels:     envInt("GRID_SELL_LEVELS", 0),
+
+		SizingMode:                 strings.ToLower(envStr("SIZING_MODE", "quote")),
+		SizingPyramidMultiplier:    envFloat("SIZING_PYRAMID_MULTIPLIER", 1.5),
+		SizingPyramidMaxMultiplier: envFloat("SIZING_PYRAMID_MAX_MULTIPLIER", 4),
+		SizingBalancePercent:       envFloat("SIZING_BALANCE_PERCENT", 90),
+
+		GridSpacingMode:             strings.ToLower(envStr("GRID_SPACING_MODE", "fixed")),
+		GridSpacingMultiplier:       envFloat("GRID_SPACING_MULTIPLIER", 1),
+		GridSpacingMinPercent:       envFloat("GRID_SPACING_MIN_PERCENT", 0.5),
+		GridSpacingMaxPercent:       envFloat("GRID_SPACING_MAX_PERCENT", 5),
+		GridVolatilityLookbackHours: envInt("GRID_VOLATILITY_LOOKBACK_HOURS", 48),
 
 		// Trading Parameters
-		SlippageTolerance: envFloat("SLIPPAGE_TOLERANCE", 1.5),
-		GasMultiplier:     envFloat("GAS_MULTIPLIER", 1.2),
-		MinProfitPercent:  envFloat("MIN_PROFIT_PERCENT", 0.5),
-		GasLimit:          envInt("GAS_LIMIT", 250000),
+		SlippageTolerance:        envFloat("SLIPPAGE_TOLERANCE", 1.5),
+		MaxPriceDeviationPercent: envFloat("MAX_PRICE_DEVIATION_PERCENT", 2),
+		GasMultiplier:            envFloat("GAS_MULTIPLIER", 1.2),
+		MinProfitPercent:         envFloat("MIN_PROFIT_PERCENT", 0.5),
+		GasLimit:                 envInt("GAS_LIMIT", 250000),
+		GasLimitMarginPercent:    envFloat("GAS_LIMIT_MARGIN_PERCENT", 20),
+		EIP1559Enabled:           envBool("EIP1559_ENABLED", true),
+		MaxFeePerGasGwei:         envFloat("MAX_FEE_PER_GAS_GWEI", 100),
+
+		// Transaction confirmation
+		TxConfirmations:         envInt("TX_CONFIRMATIONS", 2),
+		TxReceiptTimeoutSeconds: envInt("TX_RECEIPT_TIMEOUT_SECONDS", 600),
+		TxStuckBlocks:           envInt("TX_STUCK_BLOCKS", 10),
+		TxMaxReplacements:       envInt("TX_MAX_REPLACEMENTS", 3),
 
 		// Timing
 		PriceCheckIntervalSeconds:   envInt("PRICE_CHECK_INTERVAL_SECONDS", 30),
@@ -136,6 +241,12 @@ func Load() (*Config, error) {
 		PostTradeCooldownSeconds:    envInt("POST_TRADE_COOLDOWN_SECONDS", 60),
 	}
 
+	ladder, err := envFloatList("GRID_LADDER")
+	if err != nil {
+		return nil, err
+	}
+	cfg.GridLadder = ladder
+
 	return cfg, nil
 }
 
@@ -145,11 +256,126 @@ func (c *Config) Validate() error {
 	if c.WalletAddress == "" {
 		errs = append(errs, "WALLET_ADDRESS is required")
 	}
-	if !c.PaperTradingEnabled && c.PrivateKey == "" {
-		errs = append(errs, "PRIVATE_KEY is required for live trading")
+	switch c.SignerType {
+	case "key":
+		if !c.PaperTradingEnabled && c.PrivateKey == "" {
+			errs = append(errs, "PRIVATE_KEY is required for live trading")
+		}
+	case "keystore":
+		if !c.PaperTradingEnabled && c.KeystorePath == "" {
+			errs = append(errs, "KEYSTORE_PATH is required with SIGNER_TYPE=keystore")
+		}
+	case "remote":
+		if !c.PaperTradingEnabled && c.RemoteSignerURL == "" {
+			errs = append(errs, "REMOTE_SIGNER_URL is required with SIGNER_TYPE=remote")
+		}
+	default:
+		errs = append(errs, fmt.Sprintf("SIGNER_TYPE must be key, keystore or remote (got %q)", c.SignerType))
+	}
+	switch c.GridMode {
+	case "centered", "bounded":
+	default:
+		errs = append(errs, fmt.Sprintf("GRID_MODE must be centered or bounded (got %q)", c.GridMode))
+	}
+	switch c.GridLayout {
+	case "geometric", "arithmetic":
+	default:
+		errs = append(errs, fmt.Sprintf("GRID_LAYOUT must be geometric or arithmetic (got %q)", c.GridLayout))
+	}
+	if c.GridBuyLevels != 0 || c.GridSellLevels != 0 {
+		if c.GridBuyLevels < 1 || c.GridSellLevels < 1 {
+			errs = append(errs, fmt.Sprintf("GRID_BUY_LEVELS and GRID_SELL_LEVELS must both be at least 1 when either is set (got %d and %d)", c.GridBuyLevels, c.GridSellLevels))
+		}
+	}
+	if len(c.GridLadder) == 1 {
+		errs = append(errs, "GRID_LADDER must list at least 2 prices")
+	}
+	switch c.SizingMode {
+	case "quote", "base":
+	case "pyramid":
+		if c.SizingPyramidMultiplier < 1 {
+			errs = append(errs, fmt.Sprintf("SIZING_PYRAMID_MULTIPLIER must be at least 1 (got %.2f)", c.SizingPyramidMultiplier))
+		}
+		if c.SizingPyramidMaxMultiplier < 1 {
+			errs = append(errs, fmt.Sprintf("SIZING_PYRAMID_MAX_MULTIPLIER must be at least 1 (got %.2f)", c.SizingPyramidMaxMultiplier))
+		}
+	case "balance":
+		if c.SizingBalancePercent <= 0 || c.SizingBalancePercent > 100 {
+			errs = append(errs, fmt.Sprintf("SIZING_BALANCE_PERCENT must be between 0 and 100 (got %.2f)", c.SizingBalancePercent))
+		}
+	default:
+		errs = append(errs, fmt.Sprintf("SIZING_MODE must be quote, base, pyramid or balance (got %q)", c.SizingMode))
+	}
+	switch c.GridSpacingMode {
+	case "fixed":
+	case "atr", "volatility":
+		if c.GridSpacingMultiplier <= 0 {
+			errs = append(errs, fmt.Sprintf("GRID_SPACING_MULTIPLIER must be positive (got %.2f)", c.GridSpacingMultiplier))
+		}
+		if c.GridSpacingMinPercent <= 0 || c.GridSpacingMaxPercent < c.GridSpacingMinPercent {
+			errs = append(errs, fmt.Sprintf("GRID_SPACING_MIN_PERCENT must be positive and at most GRID_SPACING_MAX_PERCENT (got %.2f-%.2f)",
+				c.GridSpacingMinPercent, c.GridSpacingMaxPercent))
+		}
+		if c.GridVolatilityLookbackHours < 3 {
+			errs = append(errs, fmt.Sprintf("GRID_VOLATILITY_LOOKBACK_HOURS must be at least 3 (got %d)", c.GridVolatilityLookbackHours))
+		}
+	default:
+		errs = append(errs, fmt.Sprintf("GRID_SPACING_MODE must be fixed, atr or volatility (got %q)", c.GridSpacingMode))
+	}
+	switch c.CostBasisMethod {
+	case "fifo", "lifo", "hifo":
+	default:
+		errs = append(errs, fmt.Sprintf("COST_BASIS_METHOD must be fifo, lifo or hifo (got %q)", c.CostBasisMethod))
+	}
+	switch c.UniswapVersion {
+	case "v2", "v3":
+	default:
+		errs = append(errs, fmt.Sprintf("UNISWAP_VERSION must be v2 or v3 (got %q)", c.UniswapVersion))
+	}
+	switch c.UniswapV3FeeTier {
+	case 0, 100, 500, 3000, 10000:
+	default:
+		errs = append(errs, fmt.Sprintf("UNISWAP_V3_FEE_TIER must be 0 (auto), 100, 500, 3000 or 10000 (got %d)", c.UniswapV3FeeTier))
+	}
+	if len(c.PriceSources) == 0 {
+		errs = append(errs, "PRICE_SOURCES must list at least one of coingecko, chainlink, uniswap, twap")
+	}
+	for _, src := range c.PriceSources {
+		switch src {
+		case "coingecko", "chainlink", "uniswap", "twap":
+		default:
+			errs = append(errs, fmt.Sprintf("PRICE_SOURCES: unknown source %q (want coingecko, chainlink, uniswap or twap)", src))
+		}
+	}
+	if c.PriceMinSources < 1 || c.PriceMinSources > len(c.PriceSources) {
+		errs = append(errs, fmt.Sprintf("PRICE_MIN_SOURCES must be between 1 and the number of PRICE_SOURCES (got %d)", c.PriceMinSources))
+	}
+	if c.TWAPWindowMinutes < 1 {
+		errs = append(errs, fmt.Sprintf("TWAP_WINDOW_MINUTES must be at least 1 (got %d)", c.TWAPWindowMinutes))
+	}
+	if c.TWAPMaxDeviationPercent < 0 {
+		errs = append(errs, fmt.Sprintf("TWAP_MAX_DEVIATION_PERCENT must not be negative (got %.2f)", c.TWAPMaxDeviationPercent))
+	}
+	switch c.SRProvider {
+	case "auto", "dune", "local":
+	default:
+		errs = append(errs, fmt.Sprintf("SR_PROVIDER must be auto, dune or local (got %q)", c.SRProvider))
+	}
+	switch c.SRMethod {
+	case "simple", "percentile":
+	case "pivot", "pivot-fib", "fractal":
+		if c.SRProvider == "dune" {
+			errs = append(errs, fmt.Sprintf("SR_METHOD=%s is computed locally, set SR_PROVIDER=local or auto", c.SRMethod))
+		}
+	case "volume-profile":
+		if c.SRProvider == "local" {
+			errs = append(errs, "SR_METHOD=volume-profile needs DEX trade volume from Dune, set SR_PROVIDER=dune or auto")
+		}
+	default:
+		errs = append(errs, fmt.Sprintf("SR_METHOD must be simple, percentile, pivot, pivot-fib, fractal or volume-profile (got %q)", c.SRMethod))
 	}
-	if c.DuneAPIKey == "" {
-		fmt.Println("[WARN] DUNE_API_KEY not set — will use current price for grid center (fallback mode)")
+	if c.SRSource() == "dune" && c.DuneAPIKey == "" {
+		errs = append(errs, fmt.Sprintf("SR_METHOD=%s with SR_PROVIDER=%s requires DUNE_API_KEY", c.SRMethod, c.SRProvider))
 	}
 	if c.StopLossPercent == 0 && c.TakeProfitPercent == 0 {
 		fmt.Println("[WARN] STOP_LOSS_PERCENT and TAKE_PROFIT_PERCENT are both 0 — no portfolio circuit breakers active")
@@ -181,6 +407,28 @@ func (c *Config) Print() {
 		fmt.Printf("Paper Gas Simulation: %v\n", c.PaperSimulateGas)
 	} else {
 		fmt.Println("  LIVE TRADING MODE")
+		if c.UniswapVersion == "v3" {
+			tier := "auto"
+			if c.UniswapV3FeeTier != 0 {
+				tier = fmt.Sprintf("%.2f%%", float64(c.UniswapV3FeeTier)/10000)
+			}
+			fmt.Printf("Venue: Uniswap V3 (fee tier %s)\n", tier)
+		} else {
+			fmt.Println("Venue: Uniswap V2")
+		}
+		switch c.SignerType {
+		case "keystore":
+			fmt.Printf("Signer: keystore %s\n", c.KeystorePath)
+		case "remote":
+			fmt.Printf("Signer: remote %s\n", c.RemoteSignerURL)
+		default:
+			fmt.Println("Signer: PRIVATE_KEY")
+		}
+		fmt.Printf("TX Confirmations: %d (timeout %ds)\n", c.TxConfirmations, c.TxReceiptTimeoutSeconds)
+		fmt.Printf("Max Quote Deviation: %.1f%%\n", c.MaxPriceDeviationPercent)
+		fmt.Printf("EIP-1559: %v, Max Fee: %.1f gwei\n", c.EIP1559Enabled, c.MaxFeePerGasGwei)
+		fmt.Printf("Gas Limit: simulated + %.0f%%\n", c.GasLimitMarginPercent)
+		fmt.Printf("Stuck TX: replace after %d blocks, cancel after %d replacements\n", c.TxStuckBlocks, c.TxMaxReplacements)
 	}
 
 	fmt.Println("--------------------------------------")
@@ -190,20 +438,73 @@ func (c *Config) Print() {
 	}
 	fmt.Printf("Trading Pair: ETH/%s\n", c.QuoteTokenSymbol)
 	fmt.Printf("Quote Token: %s (%s...)\n", c.QuoteTokenSymbol, truncAddr(c.QuoteTokenAddress))
+	fmt.Printf("Price Sources: %s (median, reject >%.1f%% off, need %d)\n",
+		strings.Join(c.PriceSources, ", "), c.PriceMaxSourceDeviation, c.PriceMinSources)
+	if c.MaxPriceAgeSeconds > 0 {
+		fmt.Printf("Max Price Age: %ds\n", c.MaxPriceAgeSeconds)
+	}
+	if c.TWAPMaxDeviationPercent > 0 {
+		fmt.Printf("TWAP Guard: execution within %.1f%% of %dm TWAP\n", c.TWAPMaxDeviationPercent, c.TWAPWindowMinutes)
+	}
 	fmt.Println("--------------------------------------")
 	fmt.Println("Grid Configuration:")
-	fmt.Printf("  Levels: %d\n", c.GridLevels)
-	fmt.Printf("  Spacing: %.1f%%\n", c.GridSpacingPercent)
+	switch {
+	case len(c.GridLadder) > 0:
+		fmt.Printf("  Levels: %d (ladder)\n", len(c.GridLadder))
+	case c.GridBuyLevels > 0:
+		fmt.Printf("  Levels: %d buy / %d sell\n", c.GridBuyLevels, c.GridSellLevels)
+	default:
+		fmt.Printf("  Levels: %d\n", c.GridLevels)
+	}
+	if len(c.GridLadder) > 0 {
+		fmt.Printf("  Ladder: %v\n", c.GridLadder)
+	} else if c.GridMode == "bounded" {
+		fmt.Printf("  Range: support to resistance, %s\n", c.GridLayout)
+	} else if c.GridSpacingMode == "fixed" {
+		fmt.Printf("  Spacing: %.1f%% %s\n", c.GridSpacingPercent, c.GridLayout)
+	} else {
+		fmt.Printf("  Spacing: %s x%.2f over %dh, %.1f%%-%.1f%%\n", c.GridSpacingMode, c.GridSpacingMultiplier,
+			c.GridVolatilityLookbackHours, c.GridSpacingMinPercent, c.GridSpacingMaxPercent)
+	}
 	fmt.Printf("  Amount/Grid: $%.0f\n", c.AmountPerGrid)
+	switch c.SizingMode {
+	case "pyramid":
+		fmt.Printf("  Sizing: pyramid x%.2f per buy level, max x%.2f\n", c.SizingPyramidMultiplier, c.SizingPyramidMaxMultiplier)
+	case "balance":
+		fmt.Printf("  Sizing: balance, %.0f%% of wallet\n", c.SizingBalancePercent)
+	default:
+		fmt.Printf("  Sizing: %s\n", c.SizingMode)
+	}
+	fmt.Printf("  Cost Basis: %s\n", strings.ToUpper(c.CostBasisMethod))
 	fmt.Println("--------------------------------------")
 	fmt.Println("Support/Resistance Configuration:")
+	fmt.Printf("  S/R Provider: %s\n", c.SRSource())
 	fmt.Printf("  S/R Method: %s\n", c.SRMethod)
 	fmt.Printf("  S/R Refresh: every %d hours\n", c.SRRefreshHours)
 	fmt.Printf("  S/R Lookback: %d days\n", c.SRLookbackDays)
-	fmt.Printf("  Dune API: %s\n", boolLabel(c.DuneAPIKey != "", "configured", "not set (fallback mode)"))
+	fmt.Printf("  Dune API: %s\n", boolLabel(c.DuneAPIKey != "", "configured", "not set"))
 	fmt.Println("======================================")
 }
 
+// SRSource resolves SR_PROVIDER. Pivot and fractal levels are always local
+// and volume-profile always Dune; otherwise auto picks dune when a
+// DUNE_API_KEY is configured and local when not.
+func (c *Config) SRSource() string {
+	switch c.SRMethod {
+	case "pivot", "pivot-fib", "fractal":
+		return "local"
+	case "volume-profile":
+		return "dune"
+	}
+	if c.SRProvider == "auto" {
+		if c.DuneAPIKey != "" {
+			return "dune"
+		}
+		return "local"
+	}
+	return c.SRProvider
+}
+
 func (c *Config) DSN() string {
 	return fmt.Sprintf("postgres://%s:%s@%s:%d/%s?sslmode=disable",
 		c.DBUser, c.DBPassword, c.DBHost, c.DBPort, c.DBName)
@@ -236,6 +537,31 @@ func envFloat(key string, fallback float64) float64 {
 	return fallback
 }
 
+// envList splits a comma-separated value into trimmed, lower-cased items.
+func envList(key, fallback string) []string {
+	var out []string
+	for _, item := range strings.Split(envStr(key, fallback), ",") {
+		if item = strings.ToLower(strings.TrimSpace(item)); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// envFloatList parses a comma-separated list of numbers. Unlike envFloat it
+// reports bad entries, since silently dropping one would shift a ladder.
+func envFloatList(key string) ([]float64, error) {
+	var out []float64
+	for _, item := range envList(key, "") {
+		f, err := strconv.ParseFloat(item, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid number %q", key, item)
+		}
+		out = append(out, f)
+	}
+	return out, nil
+}
+
 func envBool(key string, fallback bool) bool {
 	if v := os.Getenv(key); v != "" {
 		v = strings.ToLower(v)
@@ -5,10 +5,23 @@ import (
 	"strings"
 )
 
-// Minimal ABIs for Uniswap V2 Router02 and ERC20 — only the methods we call.
+// Minimal ABIs for Uniswap V2 Router02, Pair and ERC20 — only the methods
+// we call and the events we decode.
 
 func mustRouterABI() io.Reader {
 	return strings.NewReader(`[
+		{
+			"name": "getAmountsOut",
+			"type": "function",
+			"stateMutability": "view",
+			"inputs": [
+				{"name": "amountIn", "type": "uint256"},
+				{"name": "path",     "type": "address[]"}
+			],
+			"outputs": [
+				{"name": "amounts", "type": "uint256[]"}
+			]
+		},
 		{
 			"name": "swapExactTokensForETH",
 			"type": "function",
@@ -69,6 +82,201 @@ func mustERC20ABI() io.Reader {
 				{"name": "_value",   "type": "uint256"}
 			],
 			"outputs": [{"name": "", "type": "bool"}]
+		},
+		{
+			"name": "Transfer",
+			"type": "event",
+			"anonymous": false,
+			"inputs": [
+				{"name": "from",  "type": "address", "indexed": true},
+				{"name": "to",    "type": "address", "indexed": true},
+				{"name": "value", "type": "uint256", "indexed": false}
+			]
+		}
+	]`)
+}
+
+func mustPairABI() io.Reader {
+	return strings.NewReader(`[
+		{
+			"name": "Swap",
+			"type": "event",
+			"anonymous": false,
+			"inputs": [
+				{"name": "sender",     "type": "address", "indexed": true},
+				{"name": "amount0In",  "type": "uint256", "indexed": false},
+				{"name": "amount1In",  "type": "uint256", "indexed": false},
+				{"name": "amount0Out", "type": "uint256", "indexed": false},
+				{"name": "amount1Out", "type": "uint256", "indexed": false},
+				{"name": "to",         "type": "address", "indexed": true}
+			]
+		},
+		{
+			"name": "getReserves",
+			"type": "function",
+			"stateMutability": "view",
+			"inputs": [],
+			"outputs": [
+				{"name": "reserve0",           "type": "uint112"},
+				{"name": "reserve1",           "type": "uint112"},
+				{"name": "blockTimestampLast", "type": "uint32"}
+			]
+		},
+		{
+			"name": "price0CumulativeLast",
+			"type": "function",
+			"stateMutability": "view",
+			"inputs": [],
+			"outputs": [
+				{"name": "", "type": "uint256"}
+			]
+		},
+		{
+			"name": "price1CumulativeLast",
+			"type": "function",
+			"stateMutability": "view",
+			"inputs": [],
+			"outputs": [
+				{"name": "", "type": "uint256"}
+			]
+		}
+	]`)
+}
+
+func mustFactoryABI() io.Reader {
+	return strings.NewReader(`[
+		{
+			"name": "getPair",
+			"type": "function",
+			"stateMutability": "view",
+			"inputs": [
+				{"name": "tokenA", "type": "address"},
+				{"name": "tokenB", "type": "address"}
+			],
+			"outputs": [
+				{"name": "pair", "type": "address"}
+			]
+		}
+	]`)
+}
+
+// Minimal ABI for a Chainlink price feed (AggregatorV3Interface).
+
+func mustAggregatorABI() io.Reader {
+	return strings.NewReader(`[
+		{
+			"name": "decimals",
+			"type": "function",
+			"stateMutability": "view",
+			"inputs": [],
+			"outputs": [
+				{"name": "", "type": "uint8"}
+			]
+		},
+		{
+			"name": "latestRoundData",
+			"type": "function",
+			"stateMutability": "view",
+			"inputs": [],
+			"outputs": [
+				{"name": "roundId",         "type": "uint80"},
+				{"name": "answer",          "type": "int256"},
+				{"name": "startedAt",       "type": "uint256"},
+				{"name": "updatedAt",       "type": "uint256"},
+				{"name": "answeredInRound", "type": "uint80"}
+			]
+		}
+	]`)
+}
+
+// Minimal ABIs for Uniswap V3 SwapRouter, QuoterV2 and Pool.
+
+func mustV3RouterABI() io.Reader {
+	return strings.NewReader(`[
+		{
+			"name": "exactInputSingle",
+			"type": "function",
+			"stateMutability": "payable",
+			"inputs": [
+				{"name": "params", "type": "tuple", "components": [
+					{"name": "tokenIn",           "type": "address"},
+					{"name": "tokenOut",          "type": "address"},
+					{"name": "fee",               "type": "uint24"},
+					{"name": "recipient",         "type": "address"},
+					{"name": "deadline",          "type": "uint256"},
+					{"name": "amountIn",          "type": "uint256"},
+					{"name": "amountOutMinimum",  "type": "uint256"},
+					{"name": "sqrtPriceLimitX96", "type": "uint160"}
+				]}
+			],
+			"outputs": [
+				{"name": "amountOut", "type": "uint256"}
+			]
+		},
+		{
+			"name": "unwrapWETH9",
+			"type": "function",
+			"stateMutability": "payable",
+			"inputs": [
+				{"name": "amountMinimum", "type": "uint256"},
+				{"name": "recipient",     "type": "address"}
+			],
+			"outputs": []
+		},
+		{
+			"name": "multicall",
+			"type": "function",
+			"stateMutability": "payable",
+			"inputs": [
+				{"name": "data", "type": "bytes[]"}
+			],
+			"outputs": [
+				{"name": "results", "type": "bytes[]"}
+			]
+		}
+	]`)
+}
+
+func mustV3QuoterABI() io.Reader {
+	return strings.NewReader(`[
+		{
+			"name": "quoteExactInputSingle",
+			"type": "function",
+			"stateMutability": "nonpayable",
+			"inputs": [
+				{"name": "params", "type": "tuple", "components": [
+					{"name": "tokenIn",           "type": "address"},
+					{"name": "tokenOut",          "type": "address"},
+					{"name": "amountIn",          "type": "uint256"},
+					{"name": "fee",               "type": "uint24"},
+					{"name": "sqrtPriceLimitX96", "type": "uint160"}
+				]}
+			],
+			"outputs": [
+				{"name": "amountOut",               "type": "uint256"},
+				{"name": "sqrtPriceX96After",       "type": "uint160"},
+				{"name": "initializedTicksCrossed", "type": "uint32"},
+				{"name": "gasEstimate",             "type": "uint256"}
+			]
+		}
+	]`)
+}
+
+func mustV3PoolABI() io.Reader {
+	return strings.NewReader(`[
+		{
+			"name": "Swap",
+			"type": "event",
+			"anonymous": false,
+			"inputs": [
+				{"name": "sender",       "type": "address", "indexed": true},
+				{"name": "recipient",    "type": "address", "indexed": true},
+				{"name": "amount0",      "type": "int256",  "indexed": false},
+				{"name": "amount1",      "type": "int256",  "indexed": false},
+				{"name": "sqrtPriceX96", "type": "uint160", "indexed": false},
+				{"name": "liquidity",    "type": "uint128", "indexed": false},
+				{"name": "tick",         "type": "int24",   "indexed": false}
+			]
 		}
 	]`)
 }
@@ -2,58 +2,84 @@ package ethereum
 
 import (
 	"context"
-	"crypto/ecdsa"
 	"fmt"
 	"math/big"
-	"strings"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
 )
 
 type Client struct {
 	rpc        *ethclient.Client
-	privateKey *ecdsa.PrivateKey
+	signer     Signer
 	wallet     common.Address
 	chainID    *big.Int
 	gasLimit   uint64
 	gasMul     float64
+	gasMargin  float64 // percent added to eth_estimateGas
+
+	dynamicFees bool
+	maxFee      *big.Int // ceiling per gas in wei; nil = none
+
+	nonces *NonceManager
 }
 
-func NewClient(rpcURL, privateKeyHex string, chainID int64, gasLimit int, gasMultiplier float64) (*Client, error) {
+// NewClient dials the RPC endpoint; transactions are signed by signer. With
+// dynamicFees set transactions are sent as EIP-1559 DynamicFeeTx where the
+// chain supports it. maxFeeGwei is the most a transaction may pay per gas;
+// 0 disables the ceiling.
+// gasLimit is the per-swap gas used for cost estimates; transactions are
+// sent with their simulated gas plus gasMarginPct percent.
+func NewClient(rpcURL string, signer Signer, chainID int64, gasLimit int, gasMultiplier, gasMarginPct float64, dynamicFees bool, maxFeeGwei float64) (*Client, error) {
 	rpc, err := ethclient.Dial(rpcURL)
 	if err != nil {
 		return nil, fmt.Errorf("dial RPC: %w", err)
 	}
 
-	pkHex := strings.TrimPrefix(privateKeyHex, "0x")
-	pk, err := crypto.HexToECDSA(pkHex)
-	if err != nil {
-		return nil, fmt.Errorf("parse private key: %w", err)
+	var maxFee *big.Int
+	if maxFeeGwei > 0 {
+		maxFee = gweiToWei(maxFeeGwei)
 	}
 
-	addr := crypto.PubkeyToAddress(pk.PublicKey)
-
 	return &Client{
-		rpc:        rpc,
-		privateKey: pk,
-		wallet:     addr,
-		chainID:    big.NewInt(chainID),
-		gasLimit:   uint64(gasLimit),
-		gasMul:     gasMultiplier,
+		rpc:         rpc,
+		signer:      signer,
+		wallet:      signer.Address(),
+		chainID:     big.NewInt(chainID),
+		gasLimit:    uint64(gasLimit),
+		gasMul:      gasMultiplier,
+		gasMargin:   gasMarginPct,
+		dynamicFees: dynamicFees,
+		maxFee:      maxFee,
 	}, nil
 }
 
+// NewReadClient dials the RPC endpoint for contract reads only (price
+// feeds, pool state). It has no signer and cannot send transactions.
+func NewReadClient(rpcURL string) (*Client, error) {
+	rpc, err := ethclient.Dial(rpcURL)
+	if err != nil {
+		return nil, fmt.Errorf("dial RPC: %w", err)
+	}
+	return &Client{rpc: rpc}, nil
+}
+
 func (c *Client) WalletAddress() common.Address { return c.wallet }
 func (c *Client) GasLimit() uint64              { return c.gasLimit }
-func (c *Client) Close()                         { c.rpc.Close() }
+
+func (c *Client) Close() {
+	c.rpc.Close()
+	if s, ok := c.signer.(interface{ Close() }); ok {
+		s.Close()
+	}
+}
 
 func (c *Client) ETHBalance(ctx context.Context) (*big.Int, error) {
 	return c.rpc.BalanceAt(ctx, c.wallet, nil)
 }
 
+// GasPrice is the legacy gas price, scaled by the gas multiplier.
 func (c *Client) GasPrice(ctx context.Context) (*big.Int, error) {
 	price, err := c.rpc.SuggestGasPrice(ctx)
 	if err != nil {
@@ -70,37 +96,78 @@ func (c *Client) Nonce(ctx context.Context) (uint64, error) {
 	return c.rpc.PendingNonceAt(ctx, c.wallet)
 }
 
-// SignAndSend signs a legacy transaction and broadcasts it, returning the tx hash.
+// SignAndSend signs a transaction and broadcasts it, returning the tx hash.
+// It is simulated first and never sent if it would revert (*RevertError);
+// the gas limit comes from the simulation. It refuses with ErrFeeTooHigh
+// when fees are above the configured ceiling. With a nonce manager
+// attached, nonces come from the manager and the transaction is tracked
+// until mined.
 func (c *Client) SignAndSend(ctx context.Context, to common.Address, value *big.Int, data []byte) (string, error) {
+	if c.signer == nil {
+		return "", fmt.Errorf("read-only client cannot send transactions")
+	}
+	gas, err := c.Simulate(ctx, to, value, data)
+	if err != nil {
+		return "", err
+	}
+	if c.nonces != nil {
+		return c.nonces.send(ctx, to, value, data, gas)
+	}
+
 	nonce, err := c.Nonce(ctx)
 	if err != nil {
 		return "", fmt.Errorf("get nonce: %w", err)
 	}
-	gasPrice, err := c.GasPrice(ctx)
+	fees, err := c.SuggestFees(ctx)
 	if err != nil {
 		return "", fmt.Errorf("get gas price: %w", err)
 	}
+	if err := c.checkFeeCap(fees); err != nil {
+		return "", err
+	}
 
-	tx := types.NewTx(&types.LegacyTx{
-		Nonce:    nonce,
-		To:       &to,
-		Value:    value,
-		Gas:      c.gasLimit,
-		GasPrice: gasPrice,
-		Data:     data,
-	})
+	signed, err := c.sendTx(ctx, nonce, to, value, data, gas, fees)
+	if err != nil {
+		return "", err
+	}
+	return signed.Hash().Hex(), nil
+}
+
+// sendTx builds a dynamic-fee or legacy transaction for fees, signs it and
+// broadcasts it.
+func (c *Client) sendTx(ctx context.Context, nonce uint64, to common.Address, value *big.Int, data []byte, gas uint64, fees *Fees) (*types.Transaction, error) {
+	var tx *types.Transaction
+	if fees.Dynamic {
+		tx = types.NewTx(&types.DynamicFeeTx{
+			ChainID:   c.chainID,
+			Nonce:     nonce,
+			To:        &to,
+			Value:     value,
+			Gas:       gas,
+			GasTipCap: fees.TipCap,
+			GasFeeCap: fees.FeeCap,
+			Data:      data,
+		})
+	} else {
+		tx = types.NewTx(&types.LegacyTx{
+			Nonce:    nonce,
+			To:       &to,
+			Value:    value,
+			Gas:      gas,
+			GasPrice: fees.GasPrice,
+			Data:     data,
+		})
+	}
 
-	signer := types.NewEIP155Signer(c.chainID)
-	signed, err := types.SignTx(tx, signer, c.privateKey)
+	signed, err := c.signer.SignTx(ctx, tx, c.chainID)
 	if err != nil {
-		return "", fmt.Errorf("sign tx: %w", err)
+		return nil, fmt.Errorf("sign tx: %w", err)
 	}
 
 	if err := c.rpc.SendTransaction(ctx, signed); err != nil {
-		return "", fmt.Errorf("send tx: %w", err)
+		return nil, fmt.Errorf("send tx: %w", err)
 	}
-
-	return signed.Hash().Hex(), nil
+	return signed, nil
 }
 
 // CallContract performs a read-only eth_call and returns the raw result.
@@ -0,0 +1,167 @@
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	geth "github.com/ethereum/go-ethereum"
+)
+
+const (
+	// feeHistoryBlocks is how many recent blocks the priority fee is sampled from.
+	feeHistoryBlocks = 20
+	// feeHistoryPercentile is the per-block tip percentile that is sampled.
+	feeHistoryPercentile = 50.0
+)
+
+// ErrFeeTooHigh is returned (wrapped) when the network fee is above the
+// configured ceiling. Callers should defer the trade and retry later.
+var ErrFeeTooHigh = errors.New("network fee above configured ceiling")
+
+// Fees are the gas prices a transaction sent now would use. Dynamic fees
+// fill BaseFee, TipCap and FeeCap; legacy fees fill GasPrice only.
+type Fees struct {
+	Dynamic  bool
+	GasPrice *big.Int
+	BaseFee  *big.Int // expected base fee of the next block
+	TipCap   *big.Int // maxPriorityFeePerGas
+	FeeCap   *big.Int // maxFeePerGas
+}
+
+// EffectiveGasPrice is the price per gas the transaction is expected to
+// pay: min(FeeCap, BaseFee+TipCap) for dynamic fees, GasPrice otherwise.
+func (f *Fees) EffectiveGasPrice() *big.Int {
+	if !f.Dynamic {
+		return f.GasPrice
+	}
+	p := new(big.Int).Add(f.BaseFee, f.TipCap)
+	if p.Cmp(f.FeeCap) > 0 {
+		return new(big.Int).Set(f.FeeCap)
+	}
+	return p
+}
+
+func (f *Fees) String() string {
+	if !f.Dynamic {
+		return fmt.Sprintf("gasPrice %.2f gwei", toGwei(f.GasPrice))
+	}
+	return fmt.Sprintf("baseFee %.2f gwei, tip %.2f gwei, maxFee %.2f gwei",
+		toGwei(f.BaseFee), toGwei(f.TipCap), toGwei(f.FeeCap))
+}
+
+// SuggestFees estimates fees for the next block. With EIP-1559 enabled it
+// uses eth_feeHistory: the next block's base fee and the median of recent
+// per-block median tips, scaled by the gas multiplier. Chains without a
+// base fee fall back to a legacy gas price.
+func (c *Client) SuggestFees(ctx context.Context) (*Fees, error) {
+	if c.dynamicFees {
+		hist, err := c.rpc.FeeHistory(ctx, feeHistoryBlocks, nil, []float64{feeHistoryPercentile})
+		if err != nil {
+			return nil, fmt.Errorf("fee history: %w", err)
+		}
+		if f := feesFromHistory(hist, c.gasMul); f != nil {
+			if f.TipCap.Sign() == 0 {
+				tip, err := c.rpc.SuggestGasTipCap(ctx)
+				if err != nil {
+					return nil, fmt.Errorf("suggest tip: %w", err)
+				}
+				f.TipCap = scale(tip, c.gasMul)
+				f.FeeCap = new(big.Int).Add(new(big.Int).Mul(f.BaseFee, big.NewInt(2)), f.TipCap)
+			}
+			return c.capFees(f), nil
+		}
+	}
+
+	price, err := c.GasPrice(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &Fees{GasPrice: price}, nil
+}
+
+// CheckFeeCap returns ErrFeeTooHigh when a transaction sent now would pay
+// more per gas than the configured ceiling.
+func (c *Client) CheckFeeCap(ctx context.Context) error {
+	f, err := c.SuggestFees(ctx)
+	if err != nil {
+		return err
+	}
+	return c.checkFeeCap(f)
+}
+
+func (c *Client) checkFeeCap(f *Fees) error {
+	if c.maxFee == nil {
+		return nil
+	}
+	need := f.GasPrice
+	if f.Dynamic {
+		need = new(big.Int).Add(f.BaseFee, f.TipCap)
+	}
+	if need.Cmp(c.maxFee) > 0 {
+		return fmt.Errorf("%w: %.2f gwei > %.2f gwei", ErrFeeTooHigh, toGwei(need), toGwei(c.maxFee))
+	}
+	return nil
+}
+
+// capFees lowers FeeCap to the configured ceiling so a base fee spike
+// after broadcast can never charge more than the ceiling.
+func (c *Client) capFees(f *Fees) *Fees {
+	if c.maxFee != nil && f.FeeCap.Cmp(c.maxFee) > 0 {
+		f.FeeCap = new(big.Int).Set(c.maxFee)
+	}
+	return f
+}
+
+// feesFromHistory derives dynamic fees from an eth_feeHistory response, or
+// returns nil when the chain has no base fee. The response carries one more
+// base fee than blocks requested: the last one is the next block's.
+// FeeCap leaves room for the base fee to double before inclusion.
+func feesFromHistory(h *geth.FeeHistory, gasMul float64) *Fees {
+	if h == nil || len(h.BaseFee) == 0 {
+		return nil
+	}
+	base := h.BaseFee[len(h.BaseFee)-1]
+	if base == nil || base.Sign() == 0 {
+		return nil
+	}
+
+	var tips []*big.Int
+	for _, r := range h.Reward {
+		if len(r) > 0 && r[0] != nil && r[0].Sign() > 0 {
+			tips = append(tips, r[0])
+		}
+	}
+	tip := new(big.Int)
+	if len(tips) > 0 {
+		sort.Slice(tips, func(i, j int) bool { return tips[i].Cmp(tips[j]) < 0 })
+		tip = scale(tips[len(tips)/2], gasMul)
+	}
+
+	feeCap := new(big.Int).Mul(base, big.NewInt(2))
+	feeCap.Add(feeCap, tip)
+	return &Fees{
+		Dynamic: true,
+		BaseFee: new(big.Int).Set(base),
+		TipCap:  tip,
+		FeeCap:  feeCap,
+	}
+}
+
+func scale(v *big.Int, mul float64) *big.Int {
+	f := new(big.Float).Mul(new(big.Float).SetInt(v), new(big.Float).SetFloat64(mul))
+	out, _ := f.Int(nil)
+	return out
+}
+
+func toGwei(wei *big.Int) float64 {
+	return fromWei(wei, 9)
+}
+
+func gweiToWei(gwei float64) *big.Int {
+	f := new(big.Float).Mul(new(big.Float).SetFloat64(gwei), new(big.Float).SetFloat64(1e9))
+	out, _ := f.Int(nil)
+	return out
+}
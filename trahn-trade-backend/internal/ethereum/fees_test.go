@@ -0,0 +1,55 @@
+package ethereum
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	geth "github.com/ethereum/go-ethereum"
+)
+
+func gwei(v int64) *big.Int { return new(big.Int).Mul(big.NewInt(v), big.NewInt(1e9)) }
+
+func TestFeesFromHistory(t *testing.T) {
+	h := &geth.FeeHistory{
+		BaseFee: []*big.Int{gwei(10), gwei(12), gwei(20)},
+		Reward:  [][]*big.Int{{gwei(1)}, {gwei(3)}},
+	}
+	f := feesFromHistory(h, 1)
+	if f == nil || !f.Dynamic {
+		t.Fatalf("expected dynamic fees, got %+v", f)
+	}
+	if f.BaseFee.Cmp(gwei(20)) != 0 {
+		t.Errorf("base fee = %s, want next block's 20 gwei", f.BaseFee)
+	}
+	if f.TipCap.Cmp(gwei(3)) != 0 {
+		t.Errorf("tip = %s, want median 3 gwei", f.TipCap)
+	}
+	if f.FeeCap.Cmp(gwei(43)) != 0 {
+		t.Errorf("fee cap = %s, want 2*base+tip = 43 gwei", f.FeeCap)
+	}
+	if f.EffectiveGasPrice().Cmp(gwei(23)) != 0 {
+		t.Errorf("effective = %s, want base+tip = 23 gwei", f.EffectiveGasPrice())
+	}
+
+	if f := feesFromHistory(&geth.FeeHistory{BaseFee: []*big.Int{big.NewInt(0)}}, 1); f != nil {
+		t.Errorf("chain without base fee should fall back to legacy, got %+v", f)
+	}
+}
+
+func TestCheckFeeCap(t *testing.T) {
+	c := &Client{maxFee: gwei(25)}
+	dyn := &Fees{Dynamic: true, BaseFee: gwei(20), TipCap: gwei(3), FeeCap: gwei(43)}
+	if err := c.checkFeeCap(dyn); err != nil {
+		t.Errorf("23 gwei under 25 gwei ceiling: %v", err)
+	}
+	if c.capFees(dyn).FeeCap.Cmp(gwei(25)) != 0 {
+		t.Errorf("fee cap should be lowered to the ceiling, got %s", dyn.FeeCap)
+	}
+	if err := c.checkFeeCap(&Fees{GasPrice: gwei(30)}); !errors.Is(err, ErrFeeTooHigh) {
+		t.Errorf("30 gwei legacy price: got %v, want ErrFeeTooHigh", err)
+	}
+	if err := (&Client{}).checkFeeCap(&Fees{GasPrice: gwei(1000)}); err != nil {
+		t.Errorf("no ceiling configured: %v", err)
+	}
+}
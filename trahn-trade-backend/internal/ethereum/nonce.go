@@ -0,0 +1,393 @@
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/kjannette/trahn-backend/internal/models"
+)
+
+// Tracked transaction statuses.
+const (
+	TxPending   = "pending"
+	TxMined     = "mined"
+	TxCancelled = "cancelled" // the cancel transaction took the nonce
+	TxDropped   = "dropped"   // the nonce was used by a transaction we didn't send
+)
+
+// Replacements must outbid the transaction they replace; geth requires at
+// least 10%.
+const feeBumpPercent = 12.5
+
+// resolvedRetention is how long mined transactions stay in memory so
+// receipt waiters can find the hash that was actually mined.
+const resolvedRetention = time.Hour
+
+var (
+	// ErrTxCancelled is returned (wrapped) when a stuck transaction was
+	// replaced by its cancellation.
+	ErrTxCancelled = errors.New("transaction cancelled")
+	// ErrTxDropped is returned (wrapped) when a transaction's nonce was
+	// consumed by a transaction the nonce manager didn't send.
+	ErrTxDropped = errors.New("transaction dropped")
+)
+
+// TxStore persists tracked transactions so a restart picks up where the
+// previous process left off. Implemented by repository.PendingTxRepo.
+type TxStore interface {
+	Save(ctx context.Context, tx *models.PendingTx) error
+	GetPending(ctx context.Context, wallet string) ([]models.PendingTx, error)
+}
+
+// NonceManager hands out nonces for a wallet and tracks every transaction it
+// sends until one at that nonce is mined. A transaction not mined within
+// stuckBlocks is re-sent at the same nonce with a bumped fee; after
+// maxReplacements speed-ups (or when a speed-up would exceed the fee
+// ceiling) it is cancelled with a zero-value transfer to self.
+type NonceManager struct {
+	client          *Client
+	store           TxStore // nil = in-memory only
+	stuckBlocks     uint64
+	maxReplacements int
+
+	mu  sync.Mutex
+	txs map[uint64]*models.PendingTx
+}
+
+// NewNonceManager creates a manager and attaches it to client, so every
+// SignAndSend goes through it.
+func NewNonceManager(client *Client, store TxStore, stuckBlocks uint64, maxReplacements int) *NonceManager {
+	m := &NonceManager{
+		client:          client,
+		store:           store,
+		stuckBlocks:     stuckBlocks,
+		maxReplacements: maxReplacements,
+		txs:             make(map[uint64]*models.PendingTx),
+	}
+	client.nonces = m
+	return m
+}
+
+// Load restores unresolved transactions from the store and resolves any
+// that were mined while the bot was down.
+func (m *NonceManager) Load(ctx context.Context) error {
+	if m.store == nil {
+		return nil
+	}
+	txs, err := m.store.GetPending(ctx, m.client.wallet.Hex())
+	if err != nil {
+		return fmt.Errorf("load pending transactions: %w", err)
+	}
+	m.mu.Lock()
+	for i := range txs {
+		m.txs[txs[i].Nonce] = &txs[i]
+	}
+	m.mu.Unlock()
+	if len(txs) > 0 {
+		fmt.Printf("[NONCE] Tracking %d pending transaction(s) from previous run\n", len(txs))
+	}
+	return m.Check(ctx)
+}
+
+// Pending returns the number of unresolved transactions.
+func (m *NonceManager) Pending() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n := 0
+	for _, tx := range m.txs {
+		if tx.Status == TxPending {
+			n++
+		}
+	}
+	return n
+}
+
+// send assigns the next nonce, broadcasts and starts tracking. The lock is
+// held across nonce selection and broadcast so concurrent sends (e.g. an
+// approval followed by a swap) never share a nonce.
+func (m *NonceManager) send(ctx context.Context, to common.Address, value *big.Int, data []byte, gas uint64) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	c := m.client
+	nonce, err := m.nextNonce(ctx)
+	if err != nil {
+		return "", fmt.Errorf("get nonce: %w", err)
+	}
+	fees, err := c.SuggestFees(ctx)
+	if err != nil {
+		return "", fmt.Errorf("get gas price: %w", err)
+	}
+	if err := c.checkFeeCap(fees); err != nil {
+		return "", err
+	}
+	head, err := c.rpc.BlockNumber(ctx)
+	if err != nil {
+		return "", fmt.Errorf("get block number: %w", err)
+	}
+
+	signed, err := c.sendTx(ctx, nonce, to, value, data, gas, fees)
+	if err != nil {
+		return "", err
+	}
+
+	hash := signed.Hash().Hex()
+	tx := &models.PendingTx{
+		WalletAddress: c.wallet.Hex(),
+		Nonce:         nonce,
+		OrigHash:      hash,
+		TxHash:        hash,
+		Hashes:        []string{hash},
+		ToAddress:     to.Hex(),
+		ValueWei:      value.String(),
+		Data:          data,
+		GasLimit:      gas,
+		SentBlock:     head,
+		Status:        TxPending,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+	setFees(tx, fees)
+	m.txs[nonce] = tx
+	m.save(ctx, tx)
+	return hash, nil
+}
+
+// nextNonce is the chain's pending nonce, or one past the highest nonce we
+// are still tracking if that is higher (e.g. the node dropped a tx from its
+// mempool; the gap is filled when the stuck tx is re-sent).
+func (m *NonceManager) nextNonce(ctx context.Context) (uint64, error) {
+	n, err := m.client.rpc.PendingNonceAt(ctx, m.client.wallet)
+	if err != nil {
+		return 0, err
+	}
+	for _, tx := range m.txs {
+		if tx.Status == TxPending && tx.Nonce >= n {
+			n = tx.Nonce + 1
+		}
+	}
+	return n, nil
+}
+
+// Check resolves tracked transactions whose nonce has been mined and
+// replaces those stuck for stuckBlocks or more.
+func (m *NonceManager) Check(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var pending []*models.PendingTx
+	for nonce, tx := range m.txs {
+		if tx.Status == TxPending {
+			pending = append(pending, tx)
+		} else if time.Since(tx.UpdatedAt) > resolvedRetention {
+			delete(m.txs, nonce)
+		}
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+	sort.Slice(pending, func(i, j int) bool { return pending[i].Nonce < pending[j].Nonce })
+
+	c := m.client
+	head, err := c.rpc.BlockNumber(ctx)
+	if err != nil {
+		return fmt.Errorf("get block number: %w", err)
+	}
+	mined, err := c.rpc.NonceAt(ctx, c.wallet, nil)
+	if err != nil {
+		return fmt.Errorf("get nonce: %w", err)
+	}
+
+	for _, tx := range pending {
+		if tx.Nonce < mined {
+			if err := m.resolve(ctx, tx); err != nil {
+				return err
+			}
+			continue
+		}
+		if head < tx.SentBlock+m.stuckBlocks {
+			continue
+		}
+		if err := m.replace(ctx, tx, head); err != nil {
+			fmt.Printf("[NONCE] Replacing nonce %d failed: %v\n", tx.Nonce, err)
+		}
+	}
+	return nil
+}
+
+// resolve finds which of the transaction's broadcasts was mined.
+func (m *NonceManager) resolve(ctx context.Context, tx *models.PendingTx) error {
+	tx.Status = TxDropped
+	for i := len(tx.Hashes) - 1; i >= 0; i-- {
+		h := tx.Hashes[i]
+		_, err := m.client.rpc.TransactionReceipt(ctx, common.HexToHash(h))
+		if errors.Is(err, ethereum.NotFound) {
+			continue
+		}
+		if err != nil {
+			tx.Status = TxPending
+			return fmt.Errorf("get receipt: %w", err)
+		}
+		tx.MinedHash = &h
+		tx.Status = TxMined
+		if tx.CancelHash != nil && *tx.CancelHash == h {
+			tx.Status = TxCancelled
+		}
+		break
+	}
+
+	switch tx.Status {
+	case TxCancelled:
+		fmt.Printf("[NONCE] Nonce %d cancelled by %s\n", tx.Nonce, *tx.MinedHash)
+	case TxDropped:
+		fmt.Printf("[NONCE] Nonce %d used by an untracked transaction; %s dropped\n", tx.Nonce, tx.OrigHash)
+	default:
+		if *tx.MinedHash != tx.OrigHash {
+			fmt.Printf("[NONCE] Nonce %d mined as replacement %s\n", tx.Nonce, *tx.MinedHash)
+		}
+	}
+	tx.UpdatedAt = time.Now()
+	m.save(ctx, tx)
+	return nil
+}
+
+// replace re-sends a stuck transaction at the same nonce: the original call
+// with a bumped fee, or a cancellation once speed-ups are exhausted or would
+// exceed the fee ceiling. Cancellations ignore the ceiling, since a stuck
+// nonce blocks every later trade.
+func (m *NonceManager) replace(ctx context.Context, tx *models.PendingTx, head uint64) error {
+	c := m.client
+	fresh, err := c.SuggestFees(ctx)
+	if err != nil {
+		return fmt.Errorf("get gas price: %w", err)
+	}
+	fees := bumpFees(feesOf(tx), fresh)
+
+	cancel := tx.CancelHash != nil || tx.Replacements >= m.maxReplacements
+	if !cancel && c.maxFee != nil && maxFeePerGas(fees).Cmp(c.maxFee) > 0 {
+		cancel = true
+	}
+
+	to := common.HexToAddress(tx.ToAddress)
+	value, _ := new(big.Int).SetString(tx.ValueWei, 10)
+	data := tx.Data
+	if cancel {
+		to, value, data = c.wallet, new(big.Int), nil
+	}
+
+	signed, err := c.sendTx(ctx, tx.Nonce, to, value, data, tx.GasLimit, fees)
+	if err != nil {
+		return err
+	}
+
+	hash := signed.Hash().Hex()
+	tx.TxHash = hash
+	tx.Hashes = append(tx.Hashes, hash)
+	tx.SentBlock = head
+	tx.Replacements++
+	if cancel {
+		tx.CancelHash = &hash
+	}
+	setFees(tx, fees)
+	tx.UpdatedAt = time.Now()
+	m.save(ctx, tx)
+
+	action := "Sped up"
+	if cancel {
+		action = "Cancelling"
+	}
+	fmt.Printf("[NONCE] %s stuck nonce %d: %s (%s)\n", action, tx.Nonce, hash, fees)
+	return nil
+}
+
+// current returns the hash to poll for a transaction first broadcast as
+// hash, and its status: the mined broadcast once known, otherwise the
+// latest one. ok is false for transactions the manager isn't tracking.
+func (m *NonceManager) current(hash string) (poll, status string, ok bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, tx := range m.txs {
+		if !slices.Contains(tx.Hashes, hash) {
+			continue
+		}
+		if tx.MinedHash != nil {
+			return *tx.MinedHash, tx.Status, true
+		}
+		return tx.TxHash, tx.Status, true
+	}
+	return "", "", false
+}
+
+func (m *NonceManager) save(ctx context.Context, tx *models.PendingTx) {
+	if m.store == nil {
+		return
+	}
+	if err := m.store.Save(ctx, tx); err != nil {
+		fmt.Printf("[NONCE] Failed to persist nonce %d: %v\n", tx.Nonce, err)
+	}
+}
+
+// bumpFees raises old by feeBumpPercent, or to fresh if the market has
+// moved further than that.
+func bumpFees(old, fresh *Fees) *Fees {
+	bump := func(v, f *big.Int) *big.Int {
+		b := new(big.Int).Mul(v, big.NewInt(int64(1000+feeBumpPercent*10)))
+		b.Add(b, big.NewInt(999))
+		b.Quo(b, big.NewInt(1000))
+		if f != nil && f.Cmp(b) > 0 {
+			return new(big.Int).Set(f)
+		}
+		return b
+	}
+	if !old.Dynamic {
+		return &Fees{GasPrice: bump(old.GasPrice, fresh.GasPrice)}
+	}
+	return &Fees{
+		Dynamic: true,
+		BaseFee: fresh.BaseFee,
+		TipCap:  bump(old.TipCap, fresh.TipCap),
+		FeeCap:  bump(old.FeeCap, fresh.FeeCap),
+	}
+}
+
+func maxFeePerGas(f *Fees) *big.Int {
+	if f.Dynamic {
+		return f.FeeCap
+	}
+	return f.GasPrice
+}
+
+func feesOf(tx *models.PendingTx) *Fees {
+	wei := func(s *string) *big.Int {
+		if s == nil {
+			return nil
+		}
+		v, _ := new(big.Int).SetString(*s, 10)
+		return v
+	}
+	if tx.FeeCapWei != nil {
+		return &Fees{Dynamic: true, TipCap: wei(tx.TipCapWei), FeeCap: wei(tx.FeeCapWei)}
+	}
+	return &Fees{GasPrice: wei(tx.GasPriceWei)}
+}
+
+func setFees(tx *models.PendingTx, f *Fees) {
+	str := func(v *big.Int) *string {
+		s := v.String()
+		return &s
+	}
+	tx.GasPriceWei, tx.TipCapWei, tx.FeeCapWei = nil, nil, nil
+	if f.Dynamic {
+		tx.TipCapWei, tx.FeeCapWei = str(f.TipCap), str(f.FeeCap)
+	} else {
+		tx.GasPriceWei = str(f.GasPrice)
+	}
+}
@@ -0,0 +1,65 @@
+package ethereum
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/kjannette/trahn-backend/internal/models"
+)
+
+func TestBumpFees(t *testing.T) {
+	old := &Fees{Dynamic: true, BaseFee: gwei(20), TipCap: gwei(2), FeeCap: gwei(42)}
+
+	// Market unchanged: both caps rise by 12.5%.
+	got := bumpFees(old, old)
+	if got.TipCap.Cmp(big.NewInt(2_250_000_000)) != 0 || got.FeeCap.Cmp(big.NewInt(47_250_000_000)) != 0 {
+		t.Errorf("bump = tip %s cap %s, want 2.25/47.25 gwei", got.TipCap, got.FeeCap)
+	}
+
+	// Market moved further than the bump: follow the market.
+	fresh := &Fees{Dynamic: true, BaseFee: gwei(40), TipCap: gwei(5), FeeCap: gwei(85)}
+	got = bumpFees(old, fresh)
+	if got.TipCap.Cmp(gwei(5)) != 0 || got.FeeCap.Cmp(gwei(85)) != 0 {
+		t.Errorf("bump = tip %s cap %s, want fresh 5/85 gwei", got.TipCap, got.FeeCap)
+	}
+
+	legacy := bumpFees(&Fees{GasPrice: gwei(8)}, &Fees{GasPrice: gwei(7)})
+	if legacy.Dynamic || legacy.GasPrice.Cmp(gwei(9)) != 0 {
+		t.Errorf("legacy bump = %+v, want 9 gwei", legacy)
+	}
+}
+
+func TestFeesRoundTrip(t *testing.T) {
+	var tx models.PendingTx
+	setFees(&tx, &Fees{Dynamic: true, BaseFee: gwei(20), TipCap: gwei(2), FeeCap: gwei(42)})
+	f := feesOf(&tx)
+	if !f.Dynamic || f.TipCap.Cmp(gwei(2)) != 0 || f.FeeCap.Cmp(gwei(42)) != 0 {
+		t.Errorf("dynamic round trip = %+v", f)
+	}
+
+	setFees(&tx, &Fees{GasPrice: gwei(8)})
+	f = feesOf(&tx)
+	if f.Dynamic || f.GasPrice.Cmp(gwei(8)) != 0 || tx.FeeCapWei != nil {
+		t.Errorf("legacy round trip = %+v", f)
+	}
+}
+
+func TestNonceManagerCurrent(t *testing.T) {
+	m := NewNonceManager(&Client{}, nil, 10, 3)
+	mined := "0xbbb"
+	m.txs[7] = &models.PendingTx{Nonce: 7, OrigHash: "0xaaa", TxHash: "0xccc",
+		Hashes: []string{"0xaaa", "0xbbb", "0xccc"}, Status: TxPending}
+
+	if h, status, ok := m.current("0xaaa"); !ok || h != "0xccc" || status != TxPending {
+		t.Errorf("pending: got %s %s %v, want latest replacement", h, status, ok)
+	}
+
+	m.txs[7].MinedHash, m.txs[7].Status = &mined, TxMined
+	if h, status, ok := m.current("0xaaa"); !ok || h != mined || status != TxMined {
+		t.Errorf("mined: got %s %s %v, want mined replacement", h, status, ok)
+	}
+
+	if _, _, ok := m.current("0xddd"); ok {
+		t.Error("untracked hash should not be found")
+	}
+}
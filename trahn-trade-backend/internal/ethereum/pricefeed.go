@@ -0,0 +1,216 @@
+package ethereum
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ChainlinkFeed reads a Chainlink price feed such as ETH/USD
+// (0x5f4eC3Df9cbd43714FE2740f5E3616155c5b8419 on mainnet).
+type ChainlinkFeed struct {
+	client *Client
+	addr   common.Address
+	abi    abi.ABI
+	maxAge time.Duration
+
+	// The tick loop and scheduler-driven grid recalculations read prices
+	// concurrently, so the lazily read decimals are guarded.
+	mu       sync.Mutex
+	decimals int // read from the feed on first use
+}
+
+// NewChainlinkFeed creates a feed reader. Rounds older than maxAge are
+// refused; 0 accepts any age.
+func NewChainlinkFeed(client *Client, feedAddr string, maxAge time.Duration) (*ChainlinkFeed, error) {
+	a, err := abi.JSON(mustAggregatorABI())
+	if err != nil {
+		return nil, fmt.Errorf("parse aggregator ABI: %w", err)
+	}
+	return &ChainlinkFeed{client: client, addr: common.HexToAddress(feedAddr), abi: a, maxAge: maxAge}, nil
+}
+
+func (f *ChainlinkFeed) Name() string { return "chainlink" }
+
+// Price returns the feed's latest answer. The feed only writes a new round
+// when the price moves past its deviation threshold or the heartbeat
+// expires, so a round younger than maxAge is current as of the read and is
+// reported as observed now.
+func (f *ChainlinkFeed) Price(ctx context.Context) (float64, time.Time, error) {
+	decimals, err := f.feedDecimals(ctx)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+
+	vals, err := f.call(ctx, "latestRoundData")
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	answer, updatedAt := vals[1].(*big.Int), vals[3].(*big.Int)
+	if answer.Sign() <= 0 {
+		return 0, time.Time{}, fmt.Errorf("chainlink answer %s is not positive", answer)
+	}
+	updated := time.Unix(updatedAt.Int64(), 0)
+	if f.maxAge > 0 && time.Since(updated) > f.maxAge {
+		return 0, time.Time{}, fmt.Errorf("chainlink round is stale: updated %s ago", time.Since(updated).Round(time.Second))
+	}
+	return fromWei(answer, decimals), time.Now(), nil
+}
+
+// feedDecimals reads the answer's decimals once; a failed read is retried
+// on the next call.
+func (f *ChainlinkFeed) feedDecimals(ctx context.Context) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.decimals == 0 {
+		vals, err := f.call(ctx, "decimals")
+		if err != nil {
+			return 0, err
+		}
+		f.decimals = int(vals[0].(uint8))
+	}
+	return f.decimals, nil
+}
+
+func (f *ChainlinkFeed) call(ctx context.Context, method string) ([]interface{}, error) {
+	data, err := f.abi.Pack(method)
+	if err != nil {
+		return nil, fmt.Errorf("pack %s: %w", method, err)
+	}
+	result, err := f.client.CallContract(ctx, f.addr, data)
+	if err != nil {
+		return nil, fmt.Errorf("%s call: %w", method, err)
+	}
+	vals, err := f.abi.Unpack(method, result)
+	if err != nil {
+		return nil, fmt.Errorf("decode %s: %w", method, err)
+	}
+	return vals, nil
+}
+
+// V2Pair reads the spot price of ETH from a Uniswap V2 WETH/quote pair's
+// reserves. The pair is looked up from the factory on first use.
+type V2Pair struct {
+	client      *Client
+	factoryAddr common.Address
+	wethAddr    common.Address
+	quoteAddr   common.Address
+	quoteDec    int
+	pairABI     abi.ABI
+	factoryABI  abi.ABI
+
+	mu   sync.Mutex // guards pair, looked up lazily from concurrent callers
+	pair common.Address
+}
+
+func NewV2Pair(client *Client, factoryAddr, wethAddr, quoteAddr string, quoteDecimals int) (*V2Pair, error) {
+	pABI, err := abi.JSON(mustPairABI())
+	if err != nil {
+		return nil, fmt.Errorf("parse pair ABI: %w", err)
+	}
+	fABI, err := abi.JSON(mustFactoryABI())
+	if err != nil {
+		return nil, fmt.Errorf("parse factory ABI: %w", err)
+	}
+	return &V2Pair{
+		client:      client,
+		factoryAddr: common.HexToAddress(factoryAddr),
+		wethAddr:    common.HexToAddress(wethAddr),
+		quoteAddr:   common.HexToAddress(quoteAddr),
+		quoteDec:    quoteDecimals,
+		pairABI:     pABI,
+		factoryABI:  fABI,
+	}, nil
+}
+
+func (p *V2Pair) Name() string { return "uniswap" }
+
+// Price returns quote tokens per ETH implied by the pair's reserves at the
+// latest block.
+func (p *V2Pair) Price(ctx context.Context) (float64, time.Time, error) {
+	reserveWETH, reserveQuote, err := p.Reserves(ctx)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	price, err := reservePrice(reserveWETH, reserveQuote, p.quoteDec)
+	return price, time.Now(), err
+}
+
+// Reserves returns the pair's WETH and quote-token reserves in base units.
+func (p *V2Pair) Reserves(ctx context.Context) (weth, quote *big.Int, err error) {
+	pair, err := p.Address(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	vals, err := p.callPair(ctx, pair, "getReserves")
+	if err != nil {
+		return nil, nil, err
+	}
+	r0, r1 := vals[0].(*big.Int), vals[1].(*big.Int)
+	if p.wethIsToken0() {
+		return r0, r1, nil
+	}
+	return r1, r0, nil
+}
+
+// Address returns the pair contract, looking it up on first use.
+func (p *V2Pair) Address(ctx context.Context) (common.Address, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.pair != (common.Address{}) {
+		return p.pair, nil
+	}
+	data, err := p.factoryABI.Pack("getPair", p.wethAddr, p.quoteAddr)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("pack getPair: %w", err)
+	}
+	result, err := p.client.CallContract(ctx, p.factoryAddr, data)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("getPair call: %w", err)
+	}
+	vals, err := p.factoryABI.Unpack("getPair", result)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("decode getPair: %w", err)
+	}
+	pair := vals[0].(common.Address)
+	if pair == (common.Address{}) {
+		return common.Address{}, fmt.Errorf("no Uniswap V2 pair for %s/%s", p.wethAddr.Hex(), p.quoteAddr.Hex())
+	}
+	p.pair = pair
+	return pair, nil
+}
+
+// wethIsToken0 follows the pair's ordering: token0 has the lower address.
+func (p *V2Pair) wethIsToken0() bool {
+	return bytes.Compare(p.wethAddr.Bytes(), p.quoteAddr.Bytes()) < 0
+}
+
+func (p *V2Pair) callPair(ctx context.Context, pair common.Address, method string) ([]interface{}, error) {
+	data, err := p.pairABI.Pack(method)
+	if err != nil {
+		return nil, fmt.Errorf("pack %s: %w", method, err)
+	}
+	result, err := p.client.CallContract(ctx, pair, data)
+	if err != nil {
+		return nil, fmt.Errorf("%s call: %w", method, err)
+	}
+	vals, err := p.pairABI.Unpack(method, result)
+	if err != nil {
+		return nil, fmt.Errorf("decode %s: %w", method, err)
+	}
+	return vals, nil
+}
+
+// reservePrice is quote tokens per ETH for the given reserves.
+func reservePrice(reserveWETH, reserveQuote *big.Int, quoteDec int) (float64, error) {
+	if reserveWETH.Sign() == 0 || reserveQuote.Sign() == 0 {
+		return 0, fmt.Errorf("pair has no liquidity")
+	}
+	return fromWei(reserveQuote, quoteDec) / fromWei(reserveWETH, 18), nil
+}
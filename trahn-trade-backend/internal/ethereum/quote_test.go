@@ -0,0 +1,57 @@
+package ethereum
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestApplySlippage(t *testing.T) {
+	cases := []struct {
+		amount int64
+		pct    float64
+		want   int64
+	}{
+		{1_000_000, 1.5, 985_000},
+		{1_000_000, 0, 1_000_000},
+		{999, 0.5, 994}, // rounds down
+	}
+	for _, c := range cases {
+		got := applySlippage(big.NewInt(c.amount), c.pct)
+		if got.Int64() != c.want {
+			t.Errorf("applySlippage(%d, %.2f) = %d, want %d", c.amount, c.pct, got.Int64(), c.want)
+		}
+	}
+}
+
+func TestCheckDeviation(t *testing.T) {
+	if err := checkDeviation(2030, 2000, 2); err != nil {
+		t.Errorf("1.5%% deviation under 2%% limit: %v", err)
+	}
+	if err := checkDeviation(1950, 2000, 2); !errors.Is(err, ErrQuoteDeviation) {
+		t.Errorf("2.5%% deviation: got %v, want ErrQuoteDeviation", err)
+	}
+	if err := checkDeviation(1000, 2000, 0); err != nil {
+		t.Errorf("zero limit should disable the check: %v", err)
+	}
+	if err := checkDeviation(1000, 0, 2); err != nil {
+		t.Errorf("missing reference price should skip the check: %v", err)
+	}
+}
+
+func TestReservePrice(t *testing.T) {
+	// 1,000 WETH against 2,500,000 USDC (6 decimals).
+	weth := new(big.Int).Mul(big.NewInt(1000), big.NewInt(1e18))
+	usdc := new(big.Int).Mul(big.NewInt(2_500_000), big.NewInt(1e6))
+	p, err := reservePrice(weth, usdc, 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(p-2500) > 1e-9 {
+		t.Fatalf("expected 2500, got %f", p)
+	}
+	if _, err := reservePrice(new(big.Int), usdc, 6); err == nil {
+		t.Fatal("expected empty pair to fail")
+	}
+}
@@ -0,0 +1,142 @@
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+var (
+	// ErrTxReverted is returned (wrapped) when a mined transaction has status 0.
+	ErrTxReverted = errors.New("transaction reverted")
+	// ErrTxPending is returned (wrapped) by a ReceiptOptions.Once check when
+	// the transaction is not confirmed yet.
+	ErrTxPending = errors.New("transaction pending")
+)
+
+// ReceiptOptions controls how long to wait for a transaction to be mined
+// and how deep it must be buried before it counts as confirmed.
+type ReceiptOptions struct {
+	Confirmations uint64        // blocks including the inclusion block; 0 or 1 = mined
+	PollInterval  time.Duration // default 3s
+	Timeout       time.Duration // 0 = wait until ctx is done
+	Once          bool          // check once instead of waiting
+}
+
+// WaitForReceipt polls until hash is mined with the requested confirmation
+// depth. If the transaction is reorged out while waiting, it keeps waiting
+// for it to be re-included. A mined but reverted transaction returns its
+// receipt together with an error wrapping ErrTxReverted.
+//
+// With a nonce manager attached it also replaces the transaction if it gets
+// stuck and follows the replacement. If the transaction was cancelled, the
+// cancellation's receipt is returned with an error wrapping ErrTxCancelled.
+func (c *Client) WaitForReceipt(ctx context.Context, hash string, opts ReceiptOptions) (*types.Receipt, error) {
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = 3 * time.Second
+	}
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+	txHash := common.HexToHash(hash)
+
+	ticker := time.NewTicker(opts.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		poll, cancelled := txHash, false
+		if c.nonces != nil {
+			if err := c.nonces.Check(ctx); err != nil {
+				fmt.Printf("[NONCE] Check failed: %v\n", err)
+			}
+			if h, status, ok := c.nonces.current(hash); ok {
+				if status == TxDropped {
+					return nil, fmt.Errorf("%w: %s", ErrTxDropped, hash)
+				}
+				poll, cancelled = common.HexToHash(h), status == TxCancelled
+			}
+		}
+
+		receipt, err := c.confirmedReceipt(ctx, poll, opts.Confirmations)
+		if err != nil {
+			return nil, err
+		}
+		if receipt != nil {
+			if cancelled {
+				return receipt, fmt.Errorf("%w: %s (cancelled by %s)", ErrTxCancelled, hash, poll.Hex())
+			}
+			if receipt.Status != types.ReceiptStatusSuccessful {
+				return receipt, fmt.Errorf("%w: %s (block %d, gas used %d)",
+					ErrTxReverted, hash, receipt.BlockNumber, receipt.GasUsed)
+			}
+			return receipt, nil
+		}
+		if opts.Once {
+			return nil, fmt.Errorf("%w: %s", ErrTxPending, hash)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("waiting for receipt %s: %w", hash, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// confirmedReceipt returns the receipt once it has enough confirmations, or
+// nil if the transaction is still pending or not yet deep enough.
+func (c *Client) confirmedReceipt(ctx context.Context, hash common.Hash, confirmations uint64) (*types.Receipt, error) {
+	receipt, err := c.rpc.TransactionReceipt(ctx, hash)
+	if errors.Is(err, ethereum.NotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get receipt: %w", err)
+	}
+	if confirmations <= 1 {
+		return receipt, nil
+	}
+
+	head, err := c.rpc.BlockNumber(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get block number: %w", err)
+	}
+	if head+1 < receipt.BlockNumber.Uint64()+confirmations {
+		return nil, nil
+	}
+
+	// Make sure the inclusion block is still canonical.
+	header, err := c.rpc.HeaderByNumber(ctx, receipt.BlockNumber)
+	if err != nil {
+		return nil, fmt.Errorf("get header: %w", err)
+	}
+	if header.Hash() != receipt.BlockHash {
+		return nil, nil
+	}
+	return receipt, nil
+}
+
+// ReceiptGasCostETH returns the gas actually paid: gasUsed * effectiveGasPrice.
+func ReceiptGasCostETH(r *types.Receipt) float64 {
+	if r.EffectiveGasPrice == nil {
+		return 0
+	}
+	cost := new(big.Int).Mul(new(big.Int).SetUint64(r.GasUsed), r.EffectiveGasPrice)
+	return fromWei(cost, 18)
+}
+
+func fromWei(v *big.Int, decimals int) float64 {
+	f, _ := new(big.Float).Quo(
+		new(big.Float).SetInt(v),
+		new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)),
+	).Float64()
+	return f
+}
@@ -0,0 +1,181 @@
+package ethereum
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Signer signs the transactions Client sends. Implementations hold the key
+// in memory (KeySigner) or delegate to another process (RemoteSigner).
+type Signer interface {
+	Address() common.Address
+	SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error)
+}
+
+var (
+	_ Signer = (*KeySigner)(nil)
+	_ Signer = (*RemoteSigner)(nil)
+)
+
+// CheckSignerAddress fails unless s signs as wallet, so a key or keystore
+// for another account cannot trade from it while the configured wallet is
+// the one displayed and tracked.
+func CheckSignerAddress(s Signer, wallet string) error {
+	if !common.IsHexAddress(wallet) {
+		return fmt.Errorf("invalid wallet address %q", wallet)
+	}
+	if want := common.HexToAddress(wallet); s.Address() != want {
+		return fmt.Errorf("signer account %s does not match WALLET_ADDRESS %s", s.Address().Hex(), want.Hex())
+	}
+	return nil
+}
+
+// KeySigner signs with a private key held in memory.
+type KeySigner struct {
+	key  *ecdsa.PrivateKey
+	addr common.Address
+}
+
+// NewKeySigner parses a hex private key, with or without 0x.
+func NewKeySigner(privateKeyHex string) (*KeySigner, error) {
+	pk, err := crypto.HexToECDSA(strings.TrimPrefix(privateKeyHex, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("parse private key: %w", err)
+	}
+	return &KeySigner{key: pk, addr: crypto.PubkeyToAddress(pk.PublicKey)}, nil
+}
+
+// NewKeystoreSigner decrypts a go-ethereum JSON keystore file (as written
+// by geth account new or clef newaccount).
+func NewKeystoreSigner(path, passphrase string) (*KeySigner, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read keystore: %w", err)
+	}
+	key, err := keystore.DecryptKey(data, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt keystore %s: %w", path, err)
+	}
+	return &KeySigner{key: key.PrivateKey, addr: key.Address}, nil
+}
+
+func (s *KeySigner) Address() common.Address { return s.addr }
+
+func (s *KeySigner) SignTx(_ context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	return types.SignTx(tx, types.LatestSignerForChainID(chainID), s.key)
+}
+
+// ReadPassphrase returns the keystore passphrase from file, or prompts for
+// it on standard input when file is empty.
+func ReadPassphrase(file string) (string, error) {
+	if file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return "", fmt.Errorf("read passphrase file: %w", err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	}
+	fmt.Print("Keystore passphrase: ")
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("read passphrase: %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// RemoteSigner asks an external signer such as Clef to sign over JSON-RPC
+// (account_signTransaction). The key never enters this process; the signer
+// may require each transaction to be approved by its rules or an operator.
+type RemoteSigner struct {
+	rpc  *rpc.Client
+	addr common.Address
+}
+
+// signTxArgs is the transaction argument of account_signTransaction.
+type signTxArgs struct {
+	From                 common.Address  `json:"from"`
+	To                   *common.Address `json:"to"`
+	Gas                  hexutil.Uint64  `json:"gas"`
+	GasPrice             *hexutil.Big    `json:"gasPrice,omitempty"`
+	MaxFeePerGas         *hexutil.Big    `json:"maxFeePerGas,omitempty"`
+	MaxPriorityFeePerGas *hexutil.Big    `json:"maxPriorityFeePerGas,omitempty"`
+	Value                hexutil.Big     `json:"value"`
+	Nonce                hexutil.Uint64  `json:"nonce"`
+	Data                 hexutil.Bytes   `json:"data"`
+	ChainID              *hexutil.Big    `json:"chainId"`
+}
+
+// signTxResult is the signer's reply: the RLP-encoded signed transaction.
+type signTxResult struct {
+	Raw hexutil.Bytes `json:"raw"`
+}
+
+// NewRemoteSigner connects to the signer at url, signing as address.
+func NewRemoteSigner(ctx context.Context, url, address string) (*RemoteSigner, error) {
+	if !common.IsHexAddress(address) {
+		return nil, fmt.Errorf("invalid signer account %q", address)
+	}
+	c, err := rpc.DialContext(ctx, url)
+	if err != nil {
+		return nil, fmt.Errorf("dial signer: %w", err)
+	}
+	return &RemoteSigner{rpc: c, addr: common.HexToAddress(address)}, nil
+}
+
+func (s *RemoteSigner) Address() common.Address { return s.addr }
+func (s *RemoteSigner) Close()                   { s.rpc.Close() }
+
+func (s *RemoteSigner) SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	args := signTxArgs{
+		From:    s.addr,
+		To:      tx.To(),
+		Gas:     hexutil.Uint64(tx.Gas()),
+		Value:   hexutil.Big(*tx.Value()),
+		Nonce:   hexutil.Uint64(tx.Nonce()),
+		Data:    tx.Data(),
+		ChainID: (*hexutil.Big)(chainID),
+	}
+	if tx.Type() == types.DynamicFeeTxType {
+		args.MaxFeePerGas = (*hexutil.Big)(tx.GasFeeCap())
+		args.MaxPriorityFeePerGas = (*hexutil.Big)(tx.GasTipCap())
+	} else {
+		args.GasPrice = (*hexutil.Big)(tx.GasPrice())
+	}
+
+	var res signTxResult
+	if err := s.rpc.CallContext(ctx, &res, "account_signTransaction", args); err != nil {
+		return nil, fmt.Errorf("remote sign: %w", err)
+	}
+	signed := new(types.Transaction)
+	if err := signed.UnmarshalBinary(res.Raw); err != nil {
+		return nil, fmt.Errorf("decode signed tx: %w", err)
+	}
+
+	// The signer must return what we asked for, signed by the right account.
+	if signed.Nonce() != tx.Nonce() || signed.Gas() != tx.Gas() || signed.To() == nil || *signed.To() != *tx.To() ||
+		signed.Value().Cmp(tx.Value()) != 0 || signed.GasFeeCap().Cmp(tx.GasFeeCap()) != 0 ||
+		signed.GasTipCap().Cmp(tx.GasTipCap()) != 0 || !bytes.Equal(signed.Data(), tx.Data()) {
+		return nil, fmt.Errorf("remote signer returned a different transaction")
+	}
+	from, err := types.Sender(types.LatestSignerForChainID(chainID), signed)
+	if err != nil {
+		return nil, fmt.Errorf("recover signer: %w", err)
+	}
+	if from != s.addr {
+		return nil, fmt.Errorf("remote signer signed as %s, expected %s", from.Hex(), s.addr.Hex())
+	}
+	return signed, nil
+}
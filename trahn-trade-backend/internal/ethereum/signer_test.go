@@ -0,0 +1,187 @@
+package ethereum
+
+import (
+	"context"
+	"math/big"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const testKeyHex = "0x4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318"
+
+func testTx() *types.Transaction {
+	to := common.HexToAddress("0x7a250d5630B4cF539739dF2C5dAcb4c659F2488D")
+	return types.NewTx(&types.DynamicFeeTx{
+		ChainID:   big.NewInt(1),
+		Nonce:     7,
+		To:        &to,
+		Value:     big.NewInt(1e15),
+		Gas:       180000,
+		GasTipCap: gwei(2),
+		GasFeeCap: gwei(40),
+		Data:      []byte{0xde, 0xad, 0xbe, 0xef},
+	})
+}
+
+func assertSignedBy(t *testing.T, tx *types.Transaction, want common.Address) {
+	t.Helper()
+	from, err := types.Sender(types.LatestSignerForChainID(big.NewInt(1)), tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if from != want {
+		t.Fatalf("expected signature from %s, got %s", want.Hex(), from.Hex())
+	}
+}
+
+func TestKeystoreSigner(t *testing.T) {
+	pk, _ := crypto.HexToECDSA(testKeyHex[2:])
+	key := &keystore.Key{Address: crypto.PubkeyToAddress(pk.PublicKey), PrivateKey: pk}
+	data, err := keystore.EncryptKey(key, "hunter2", keystore.LightScryptN, keystore.LightScryptP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "key.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewKeystoreSigner(path, "wrong"); err == nil {
+		t.Fatal("expected wrong passphrase to fail")
+	}
+
+	passFile := filepath.Join(dir, "pass")
+	if err := os.WriteFile(passFile, []byte("hunter2\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	pass, err := ReadPassphrase(passFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewKeystoreSigner(path, pass)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Address() != key.Address {
+		t.Fatalf("expected %s, got %s", key.Address.Hex(), s.Address().Hex())
+	}
+	signed, err := s.SignTx(context.Background(), testTx(), big.NewInt(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertSignedBy(t, signed, key.Address)
+}
+
+// clefAPI answers account_signTransaction the way Clef does, signing with
+// a local key.
+type clefAPI struct {
+	key       *KeySigner
+	tamper    bool
+	tamperTip bool
+}
+
+func (a *clefAPI) SignTransaction(ctx context.Context, args signTxArgs) (*signTxResult, error) {
+	nonce := uint64(args.Nonce)
+	if a.tamper {
+		nonce++
+	}
+	tip := (*big.Int)(args.MaxPriorityFeePerGas)
+	if a.tamperTip {
+		tip = new(big.Int).Add(tip, big.NewInt(1))
+	}
+	tx := types.NewTx(&types.DynamicFeeTx{
+		ChainID:   (*big.Int)(args.ChainID),
+		Nonce:     nonce,
+		To:        args.To,
+		Value:     (*big.Int)(&args.Value),
+		Gas:       uint64(args.Gas),
+		GasTipCap: tip,
+		GasFeeCap: (*big.Int)(args.MaxFeePerGas),
+		Data:      args.Data,
+	})
+	signed, err := a.key.SignTx(ctx, tx, (*big.Int)(args.ChainID))
+	if err != nil {
+		return nil, err
+	}
+	raw, err := signed.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return &signTxResult{Raw: hexutil.Bytes(raw)}, nil
+}
+
+func TestRemoteSigner(t *testing.T) {
+	ctx := context.Background()
+	key, err := NewKeySigner(testKeyHex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	api := &clefAPI{key: key}
+	srv := rpc.NewServer()
+	if err := srv.RegisterName("account", api); err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Stop()
+	hs := httptest.NewServer(srv)
+	defer hs.Close()
+
+	s, err := NewRemoteSigner(ctx, hs.URL, key.Address().Hex())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	tx := testTx()
+	signed, err := s.SignTx(ctx, tx, big.NewInt(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertSignedBy(t, signed, key.Address())
+	if signed.Nonce() != tx.Nonce() || signed.GasFeeCap().Cmp(tx.GasFeeCap()) != 0 {
+		t.Fatalf("signed tx does not match request: nonce %d fee cap %s", signed.Nonce(), signed.GasFeeCap())
+	}
+
+	api.tamper = true
+	if _, err := s.SignTx(ctx, tx, big.NewInt(1)); err == nil {
+		t.Fatal("expected a modified transaction to be rejected")
+	}
+	api.tamper, api.tamperTip = false, true
+	if _, err := s.SignTx(ctx, tx, big.NewInt(1)); err == nil {
+		t.Fatal("expected a modified priority fee to be rejected")
+	}
+	api.tamperTip = false
+
+	other, _ := NewRemoteSigner(ctx, hs.URL, "0x0000000000000000000000000000000000000001")
+	defer other.Close()
+	api.tamper = false
+	if _, err := other.SignTx(ctx, tx, big.NewInt(1)); err == nil {
+		t.Fatal("expected a signature from the wrong account to be rejected")
+	}
+}
+
+func TestCheckSignerAddress(t *testing.T) {
+	key, err := NewKeySigner(testKeyHex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckSignerAddress(key, strings.ToLower(key.Address().Hex())); err != nil {
+		t.Fatalf("expected matching address to pass, got %v", err)
+	}
+	if err := CheckSignerAddress(key, "0x0000000000000000000000000000000000000001"); err == nil {
+		t.Fatal("expected a different wallet address to be rejected")
+	}
+	if err := CheckSignerAddress(key, "0x..."); err == nil {
+		t.Fatal("expected an invalid wallet address to be rejected")
+	}
+}
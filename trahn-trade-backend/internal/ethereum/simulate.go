@@ -0,0 +1,126 @@
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	geth "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Classified simulation failures. A *RevertError unwraps to one of these,
+// so callers can tell a moved market from a wallet problem with errors.Is.
+var (
+	ErrReverted              = errors.New("execution reverted")
+	ErrInsufficientOutput    = errors.New("output below minimum (price moved)")
+	ErrTransferFailed        = errors.New("token transfer failed (balance or allowance)")
+	ErrInsufficientLiquidity = errors.New("insufficient pool liquidity")
+	ErrDeadlineExpired       = errors.New("swap deadline expired")
+	ErrInsufficientFunds     = errors.New("insufficient ETH for value and gas")
+)
+
+// RevertError is a transaction that failed simulation and was not sent.
+// Reason is the decoded revert string, when the node returned one.
+type RevertError struct {
+	Reason string
+	Kind   error
+}
+
+func (e *RevertError) Error() string {
+	if e.Reason == "" {
+		return fmt.Sprintf("simulation failed: %v", e.Kind)
+	}
+	return fmt.Sprintf("simulation failed: %v: %s", e.Kind, e.Reason)
+}
+
+func (e *RevertError) Unwrap() error { return e.Kind }
+
+// revertKinds maps revert reasons from the V2 router and pair, the V3
+// router and its TransferHelper to a classification. Matched by substring.
+var revertKinds = []struct {
+	match []string
+	kind  error
+}{
+	{[]string{"INSUFFICIENT_OUTPUT_AMOUNT", "Too little received"}, ErrInsufficientOutput},
+	{[]string{"TRANSFER_FROM_FAILED", "TRANSFER_FAILED", "STF", "transfer amount exceeds", "insufficient allowance"}, ErrTransferFailed},
+	{[]string{"INSUFFICIENT_LIQUIDITY", "INSUFFICIENT_INPUT_AMOUNT"}, ErrInsufficientLiquidity},
+	{[]string{"EXPIRED", "Transaction too old"}, ErrDeadlineExpired},
+	{[]string{"insufficient funds"}, ErrInsufficientFunds},
+}
+
+// Simulate runs the exact transaction with eth_call and eth_estimateGas
+// against the pending block and returns the gas limit to send it with: the
+// estimate plus the configured margin. A transaction that would revert
+// returns a *RevertError and must not be broadcast.
+func (c *Client) Simulate(ctx context.Context, to common.Address, value *big.Int, data []byte) (uint64, error) {
+	msg := geth.CallMsg{From: c.wallet, To: &to, Value: value, Data: data}
+
+	if _, err := c.rpc.PendingCallContract(ctx, msg); err != nil {
+		return 0, classifyRevert(err)
+	}
+	est, err := c.rpc.EstimateGasAtBlock(ctx, msg, big.NewInt(int64(rpc.PendingBlockNumber)))
+	if err != nil {
+		return 0, classifyRevert(err)
+	}
+	return withMargin(est, c.gasMargin), nil
+}
+
+// classifyRevert turns an eth_call or eth_estimateGas error into a
+// *RevertError. Errors that are not reverts (transport, timeouts) are
+// returned unchanged so they are not mistaken for a bad trade.
+func classifyRevert(err error) error {
+	reason, ok := revertReason(err)
+	if !ok {
+		return err
+	}
+	kind := ErrReverted
+	for _, k := range revertKinds {
+		if containsAny(reason, k.match) {
+			kind = k.kind
+			break
+		}
+	}
+	return &RevertError{Reason: reason, Kind: kind}
+}
+
+// revertReason extracts the revert string, preferring the ABI-encoded
+// Error(string) in the RPC error's data over the node's message.
+func revertReason(err error) (string, bool) {
+	var de rpc.DataError
+	if errors.As(err, &de) {
+		if hex, ok := de.ErrorData().(string); ok {
+			if reason, err := abi.UnpackRevert(common.FromHex(hex)); err == nil {
+				return reason, true
+			}
+		}
+	}
+
+	msg := err.Error()
+	if i := strings.Index(msg, "execution reverted"); i >= 0 {
+		reason := strings.TrimPrefix(msg[i+len("execution reverted"):], ":")
+		return strings.TrimSpace(reason), true
+	}
+	if strings.Contains(msg, "insufficient funds") {
+		return msg, true
+	}
+	return "", false
+}
+
+func containsAny(s string, subs []string) bool {
+	for _, sub := range subs {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
+
+// withMargin returns gas increased by pct percent.
+func withMargin(gas uint64, pct float64) uint64 {
+	return gas + uint64(float64(gas)*pct/100)
+}
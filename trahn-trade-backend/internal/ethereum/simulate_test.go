@@ -0,0 +1,73 @@
+package ethereum
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// rpcDataError mimics the JSON-RPC error a node returns for a revert.
+type rpcDataError struct {
+	msg  string
+	data interface{}
+}
+
+func (e *rpcDataError) Error() string          { return e.msg }
+func (e *rpcDataError) ErrorData() interface{} { return e.data }
+
+func revertData(t *testing.T, reason string) string {
+	t.Helper()
+	typ, _ := abi.NewType("string", "", nil)
+	packed, err := abi.Arguments{{Type: typ}}.Pack(reason)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hexutil.Encode(append([]byte{0x08, 0xc3, 0x79, 0xa0}, packed...))
+}
+
+func TestClassifyRevert(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		kind error
+	}{
+		{"v2 output from data", &rpcDataError{"execution reverted", revertData(t, "UniswapV2Router: INSUFFICIENT_OUTPUT_AMOUNT")}, ErrInsufficientOutput},
+		{"v2 transfer from message", errors.New("execution reverted: TransferHelper: TRANSFER_FROM_FAILED"), ErrTransferFailed},
+		{"v3 output", errors.New("execution reverted: Too little received"), ErrInsufficientOutput},
+		{"v3 transfer", &rpcDataError{"execution reverted", revertData(t, "STF")}, ErrTransferFailed},
+		{"liquidity", errors.New("execution reverted: UniswapV2Library: INSUFFICIENT_LIQUIDITY"), ErrInsufficientLiquidity},
+		{"deadline", errors.New("execution reverted: UniswapV2Router: EXPIRED"), ErrDeadlineExpired},
+		{"funds", errors.New("insufficient funds for gas * price + value"), ErrInsufficientFunds},
+		{"no reason", errors.New("execution reverted"), ErrReverted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := classifyRevert(tt.err)
+			var rev *RevertError
+			if !errors.As(err, &rev) {
+				t.Fatalf("expected *RevertError, got %v", err)
+			}
+			if !errors.Is(err, tt.kind) {
+				t.Fatalf("expected %v, got %v", tt.kind, rev.Kind)
+			}
+		})
+	}
+
+	// Transport failures are not reverts and must not be classified as one.
+	netErr := fmt.Errorf("dial tcp: connection refused")
+	if err := classifyRevert(netErr); err != netErr {
+		t.Fatalf("expected transport error unchanged, got %v", err)
+	}
+}
+
+func TestWithMargin(t *testing.T) {
+	if got := withMargin(150000, 20); got != 180000 {
+		t.Fatalf("expected 180000, got %d", got)
+	}
+	if got := withMargin(150000, 0); got != 150000 {
+		t.Fatalf("expected 150000, got %d", got)
+	}
+}
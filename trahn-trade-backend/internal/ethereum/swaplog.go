@@ -0,0 +1,118 @@
+package ethereum
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// SwapResult is what a mined swap actually did, decoded from its receipt.
+type SwapResult struct {
+	TxHash      string
+	BlockNumber uint64
+	ETHAmount   float64 // ETH received (buy) or sold (sell)
+	TokenAmount float64 // quote tokens spent (buy) or received (sell)
+	GasUsed     uint64
+	GasCostETH  float64 // gasUsed * effectiveGasPrice
+}
+
+// Price is the executed quote-token price per ETH.
+func (r *SwapResult) Price() float64 {
+	if r.ETHAmount == 0 {
+		return 0
+	}
+	return r.TokenAmount / r.ETHAmount
+}
+
+// WaitForSwap waits for a swap transaction to confirm and decodes the
+// amounts actually exchanged. side is "buy" (quote token -> ETH) or "sell".
+// A reverted swap returns a result carrying only the gas it burned, along
+// with an error wrapping ErrTxReverted.
+func (u *UniswapV2) WaitForSwap(ctx context.Context, txHash, side string, opts ReceiptOptions) (*SwapResult, error) {
+	return u.waitForSwap(ctx, txHash, side, opts, u.decodeSwap)
+}
+
+type swapDecoder func(receipt *types.Receipt, side string, wallet common.Address) (*SwapResult, error)
+
+func (u *swapBase) waitForSwap(ctx context.Context, txHash, side string, opts ReceiptOptions, decode swapDecoder) (*SwapResult, error) {
+	receipt, err := u.client.WaitForReceipt(ctx, txHash, opts)
+	if err != nil {
+		if receipt != nil {
+			return &SwapResult{
+				TxHash:      receipt.TxHash.Hex(),
+				BlockNumber: receipt.BlockNumber.Uint64(),
+				GasUsed:     receipt.GasUsed,
+				GasCostETH:  ReceiptGasCostETH(receipt),
+			}, err
+		}
+		return nil, err
+	}
+	return decode(receipt, side, u.client.wallet)
+}
+
+// decodeSwap reads the pair's Swap event for the ETH amount and the quote
+// token's Transfer logs to or from wallet for the token amount, falling back
+// to the Swap event if no matching Transfer is present.
+func (u *UniswapV2) decodeSwap(receipt *types.Receipt, side string, wallet common.Address) (*SwapResult, error) {
+	swapID := u.pairABI.Events["Swap"].ID
+	wethIsToken0 := bytes.Compare(u.wethAddr.Bytes(), u.quoteAddr.Bytes()) < 0
+
+	var ethWei, swapTokenWei *big.Int
+	for _, lg := range receipt.Logs {
+		if len(lg.Topics) == 0 || lg.Topics[0] != swapID {
+			continue
+		}
+		vals, err := u.pairABI.Unpack("Swap", lg.Data)
+		if err != nil {
+			return nil, fmt.Errorf("decode Swap event: %w", err)
+		}
+		in0, in1 := vals[0].(*big.Int), vals[1].(*big.Int)
+		out0, out1 := vals[2].(*big.Int), vals[3].(*big.Int)
+		ethIn, ethOut, tokenIn, tokenOut := in1, out1, in0, out0
+		if wethIsToken0 {
+			ethIn, ethOut, tokenIn, tokenOut = in0, out0, in1, out1
+		}
+		if side == "buy" {
+			ethWei, swapTokenWei = ethOut, tokenIn
+		} else {
+			ethWei, swapTokenWei = ethIn, tokenOut
+		}
+		break
+	}
+
+	if ethWei == nil {
+		return nil, fmt.Errorf("no Uniswap Swap event in receipt %s", receipt.TxHash.Hex())
+	}
+	return u.swapResult(receipt, side, wallet, ethWei, swapTokenWei), nil
+}
+
+// swapResult builds the result for a decoded swap, preferring the quote
+// token's Transfer logs to or from wallet over the Swap event's token amount.
+func (u *swapBase) swapResult(receipt *types.Receipt, side string, wallet common.Address, ethWei, swapTokenWei *big.Int) *SwapResult {
+	transferID := u.erc20ABI.Events["Transfer"].ID
+	tokenWei := swapTokenWei
+	transferWei := new(big.Int)
+	for _, lg := range receipt.Logs {
+		if len(lg.Topics) != 3 || lg.Topics[0] != transferID || lg.Address != u.quoteAddr {
+			continue
+		}
+		from := common.BytesToAddress(lg.Topics[1].Bytes())
+		to := common.BytesToAddress(lg.Topics[2].Bytes())
+		if (side == "buy" && from == wallet) || (side == "sell" && to == wallet) {
+			tokenWei = transferWei.Add(transferWei, new(big.Int).SetBytes(lg.Data))
+		}
+	}
+
+	return &SwapResult{
+		TxHash:      receipt.TxHash.Hex(),
+		BlockNumber: receipt.BlockNumber.Uint64(),
+		ETHAmount:   fromWei(ethWei, 18),
+		TokenAmount: fromWei(tokenWei, u.quoteDec),
+		GasUsed:     receipt.GasUsed,
+		GasCostETH:  ReceiptGasCostETH(receipt),
+	}
+}
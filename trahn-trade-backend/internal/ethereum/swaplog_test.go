@@ -0,0 +1,112 @@
+package ethereum
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const (
+	testWETH = "0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2"
+	testUSDC = "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"
+)
+
+var (
+	testWallet = common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	testPair   = common.HexToAddress("0xB4e16d0168e52d35CaCD2c6185b44281Ec28C9Dc")
+)
+
+func testUniswap(t *testing.T) *UniswapV2 {
+	t.Helper()
+	u, err := NewUniswapV2(nil, "0x7a250d5630B4cF539739dF2C5dAcb4c659F2488D", testWETH, testUSDC, "USDC", 6, 1.5, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u
+}
+
+func swapLog(t *testing.T, u *UniswapV2, in0, in1, out0, out1 *big.Int) *types.Log {
+	t.Helper()
+	ev := u.pairABI.Events["Swap"]
+	data, err := ev.Inputs.NonIndexed().Pack(in0, in1, out0, out1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &types.Log{
+		Address: testPair,
+		Topics:  []common.Hash{ev.ID, common.BytesToHash(u.routerAddr.Bytes()), common.BytesToHash(testWallet.Bytes())},
+		Data:    data,
+	}
+}
+
+func transferLog(u *swapBase, from, to common.Address, value *big.Int) *types.Log {
+	return &types.Log{
+		Address: u.quoteAddr,
+		Topics:  []common.Hash{u.erc20ABI.Events["Transfer"].ID, common.BytesToHash(from.Bytes()), common.BytesToHash(to.Bytes())},
+		Data:    common.LeftPadBytes(value.Bytes(), 32),
+	}
+}
+
+func receipt(logs ...*types.Log) *types.Receipt {
+	return &types.Receipt{
+		Status:            types.ReceiptStatusSuccessful,
+		BlockNumber:       big.NewInt(100),
+		GasUsed:           120000,
+		EffectiveGasPrice: big.NewInt(20e9), // 20 gwei
+		Logs:              logs,
+	}
+}
+
+func TestDecodeSwap_Buy(t *testing.T) {
+	u := testUniswap(t)
+	// USDC is token0: 100 USDC in, 0.05 ETH out.
+	usdcIn := big.NewInt(100_000_000)
+	ethOut := toEthWei(0.05)
+	r := receipt(
+		transferLog(&u.swapBase, testWallet, testPair, usdcIn),
+		swapLog(t, u, usdcIn, big.NewInt(0), big.NewInt(0), ethOut),
+	)
+
+	res, err := u.decodeSwap(r, "buy", testWallet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(res.ETHAmount-0.05) > 1e-12 || math.Abs(res.TokenAmount-100) > 1e-9 {
+		t.Fatalf("unexpected amounts: %f ETH, %f USDC", res.ETHAmount, res.TokenAmount)
+	}
+	if math.Abs(res.Price()-2000) > 1e-6 {
+		t.Fatalf("expected executed price 2000, got %f", res.Price())
+	}
+	if math.Abs(res.GasCostETH-0.0024) > 1e-12 {
+		t.Fatalf("expected 0.0024 ETH gas, got %f", res.GasCostETH)
+	}
+}
+
+func TestDecodeSwap_SellPrefersTransfer(t *testing.T) {
+	u := testUniswap(t)
+	ethIn := toEthWei(0.05)
+	// Swap says 101 USDC out, but only 100.5 reached the wallet.
+	r := receipt(
+		swapLog(t, u, big.NewInt(0), ethIn, big.NewInt(101_000_000), big.NewInt(0)),
+		transferLog(&u.swapBase, testPair, testWallet, big.NewInt(100_500_000)),
+	)
+
+	res, err := u.decodeSwap(r, "sell", testWallet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(res.ETHAmount-0.05) > 1e-12 || math.Abs(res.TokenAmount-100.5) > 1e-9 {
+		t.Fatalf("unexpected amounts: %f ETH, %f USDC", res.ETHAmount, res.TokenAmount)
+	}
+}
+
+func TestDecodeSwap_NoSwapEvent(t *testing.T) {
+	u := testUniswap(t)
+	r := receipt(transferLog(&u.swapBase, testWallet, testPair, big.NewInt(1)))
+	if _, err := u.decodeSwap(r, "buy", testWallet); err == nil {
+		t.Fatal("expected error for receipt without Swap event")
+	}
+}
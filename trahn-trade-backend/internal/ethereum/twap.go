@@ -0,0 +1,160 @@
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"sync"
+	"time"
+)
+
+// ErrTWAPWarmingUp is returned until observations span the full window.
+var ErrTWAPWarmingUp = errors.New("TWAP window not yet covered")
+
+// TWAP is a time-weighted average price over a Uniswap V2 pair's
+// cumulative prices. A pool can be pushed off-market within a block, but
+// moving its average over many minutes costs an attacker that long, which
+// makes this a manipulation-resistant reference.
+//
+// Observations are kept in memory, so the average is only available once
+// the bot has been observing for a full window.
+type TWAP struct {
+	pair   *V2Pair
+	window time.Duration
+
+	mu  sync.Mutex
+	obs []cumulativeObs // oldest first
+}
+
+// cumulativeObs is the pair's cumulative quote-per-WETH price (UQ112x112
+// seconds) as of a block timestamp.
+type cumulativeObs struct {
+	at  uint64
+	cum *big.Int
+}
+
+func NewTWAP(pair *V2Pair, window time.Duration) *TWAP {
+	return &TWAP{pair: pair, window: window}
+}
+
+func (t *TWAP) Name() string { return "twap" }
+
+// Observe records the pair's cumulative price at the latest block. Call it
+// regularly (every tick): the average is taken between the newest
+// observation and the latest one at least a window older.
+func (t *TWAP) Observe(ctx context.Context) error {
+	o, err := t.pair.cumulative(ctx)
+	if err != nil {
+		return err
+	}
+	t.mu.Lock()
+	t.add(o)
+	t.mu.Unlock()
+	return nil
+}
+
+// Price observes and returns the average quote-token price of ETH over the
+// window, timestamped with the latest block.
+func (t *TWAP) Price(ctx context.Context) (float64, time.Time, error) {
+	if err := t.Observe(ctx); err != nil {
+		return 0, time.Time{}, err
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	p, err := t.average()
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	return p, time.Unix(int64(t.obs[len(t.obs)-1].at), 0), nil
+}
+
+// add appends o and drops observations no longer needed as the window's
+// anchor.
+func (t *TWAP) add(o cumulativeObs) {
+	if n := len(t.obs); n > 0 && t.obs[n-1].at >= o.at {
+		t.obs[n-1] = o // same block
+	} else {
+		t.obs = append(t.obs, o)
+	}
+	span := uint64(t.window / time.Second)
+	if o.at < span {
+		return
+	}
+	start := o.at - span
+	for len(t.obs) > 2 && t.obs[1].at <= start {
+		t.obs = t.obs[1:]
+	}
+}
+
+func (t *TWAP) average() (float64, error) {
+	first, last := t.obs[0], t.obs[len(t.obs)-1]
+	elapsed := last.at - first.at
+	if len(t.obs) < 2 || time.Duration(elapsed)*time.Second < t.window {
+		return 0, fmt.Errorf("%w: %s of %s observed", ErrTWAPWarmingUp,
+			time.Duration(elapsed)*time.Second, t.window)
+	}
+	return twapPrice(first, last, t.pair.quoteDec), nil
+}
+
+// twapPrice converts the change in cumulative price between two
+// observations into quote tokens per ETH.
+func twapPrice(from, to cumulativeObs, quoteDec int) float64 {
+	diff := new(big.Int).Sub(to.cum, from.cum)
+	avg := new(big.Float).Quo(new(big.Float).SetInt(diff), new(big.Float).SetUint64(to.at-from.at))
+	avg.Quo(avg, new(big.Float).SetInt(q112))
+	f, _ := avg.Float64()
+	// Reserves are in base units: quote/10^dec per WETH/10^18.
+	return f * math.Pow10(18-quoteDec)
+}
+
+var q112 = new(big.Int).Lsh(big.NewInt(1), 112)
+
+// cumulative reads the pair's cumulative quote-per-WETH price, brought
+// forward to the latest block as the pair itself would on its next swap.
+func (p *V2Pair) cumulative(ctx context.Context) (cumulativeObs, error) {
+	pair, err := p.Address(ctx)
+	if err != nil {
+		return cumulativeObs{}, err
+	}
+	head, err := p.client.rpc.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return cumulativeObs{}, fmt.Errorf("latest block: %w", err)
+	}
+
+	// price0 is token1 per token0; WETH/quote ordering picks the one that
+	// is quote per WETH.
+	method := "price1CumulativeLast"
+	if p.wethIsToken0() {
+		method = "price0CumulativeLast"
+	}
+	vals, err := p.callPair(ctx, pair, method)
+	if err != nil {
+		return cumulativeObs{}, err
+	}
+	cum := vals[0].(*big.Int)
+
+	vals, err = p.callPair(ctx, pair, "getReserves")
+	if err != nil {
+		return cumulativeObs{}, err
+	}
+	r0, r1, last := vals[0].(*big.Int), vals[1].(*big.Int), uint64(vals[2].(uint32))
+	rWETH, rQuote := r1, r0
+	if p.wethIsToken0() {
+		rWETH, rQuote = r0, r1
+	}
+
+	return accumulate(cum, rWETH, rQuote, last, head.Time), nil
+}
+
+// accumulate adds the current spot price for the seconds since the pair
+// last updated its cumulative price.
+func accumulate(cum, rWETH, rQuote *big.Int, last, now uint64) cumulativeObs {
+	cum = new(big.Int).Set(cum)
+	if now > last && rWETH.Sign() > 0 {
+		spot := new(big.Int).Quo(new(big.Int).Lsh(rQuote, 112), rWETH)
+		cum.Add(cum, spot.Mul(spot, new(big.Int).SetUint64(now-last)))
+	}
+	return cumulativeObs{at: now, cum: cum}
+}
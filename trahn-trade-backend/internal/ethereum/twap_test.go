@@ -0,0 +1,77 @@
+package ethereum
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"testing"
+	"time"
+)
+
+// usdcPerWETH returns V2 reserves pricing ETH at price USDC (6 decimals).
+func usdcPerWETH(price int64) (weth, usdc *big.Int) {
+	weth = new(big.Int).Mul(big.NewInt(1000), big.NewInt(1e18))
+	usdc = new(big.Int).Mul(big.NewInt(1000*price), big.NewInt(1e6))
+	return weth, usdc
+}
+
+func TestAccumulateAndTWAPPrice(t *testing.T) {
+	weth, usdc := usdcPerWETH(2000)
+	start := accumulate(big.NewInt(0), weth, usdc, 1000, 1000)
+	a := accumulate(start.cum, weth, usdc, 1000, 1600) // 10 min at $2000
+
+	weth, usdc = usdcPerWETH(2600)
+	b := accumulate(a.cum, weth, usdc, 1600, 1800) // 200s at $2600
+
+	got := twapPrice(start, b, 6)
+	want := (2000.0*600 + 2600.0*200) / 800
+	if math.Abs(got-want) > 0.01 {
+		t.Errorf("twapPrice = %.4f, want %.4f", got, want)
+	}
+}
+
+func TestAccumulate_SameBlock(t *testing.T) {
+	weth, usdc := usdcPerWETH(2000)
+	o := accumulate(big.NewInt(42), weth, usdc, 1000, 1000)
+	if o.cum.Int64() != 42 {
+		t.Errorf("cumulative changed with no elapsed time: %s", o.cum)
+	}
+}
+
+func TestTWAPWindow(t *testing.T) {
+	tw := NewTWAP(&V2Pair{quoteDec: 6}, 10*time.Minute)
+	weth, usdc := usdcPerWETH(2000)
+
+	obs := accumulate(big.NewInt(0), weth, usdc, 0, 0)
+	tw.add(obs)
+	for at := uint64(60); at < 600; at += 60 {
+		obs = accumulate(obs.cum, weth, usdc, obs.at, at)
+		tw.add(obs)
+	}
+	if _, err := tw.average(); !errors.Is(err, ErrTWAPWarmingUp) {
+		t.Fatalf("expected ErrTWAPWarmingUp before a full window, got %v", err)
+	}
+
+	for at := uint64(600); at <= 1200; at += 60 {
+		obs = accumulate(obs.cum, weth, usdc, obs.at, at)
+		tw.add(obs)
+	}
+	p, err := tw.average()
+	if err != nil {
+		t.Fatalf("average: %v", err)
+	}
+	if math.Abs(p-2000) > 0.01 {
+		t.Errorf("average = %.4f, want 2000", p)
+	}
+	// Only the anchor at the window's start is kept before it.
+	if first := tw.obs[0].at; first != 600 {
+		t.Errorf("oldest observation at %d, want 600", first)
+	}
+
+	// A re-read of the same block replaces rather than appends.
+	n := len(tw.obs)
+	tw.add(obs)
+	if len(tw.obs) != n {
+		t.Errorf("same-block observation appended: %d -> %d", n, len(tw.obs))
+	}
+}
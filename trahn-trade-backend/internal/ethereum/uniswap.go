@@ -2,6 +2,7 @@ package ethereum
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
 	"math/big"
@@ -13,17 +14,54 @@ import (
 
 const explorerTxPrefix = "https://etherscan.io/tx/"
 
+// ErrQuoteDeviation is returned (wrapped) when the pool's quote is too far
+// from the reference price to trade safely.
+var ErrQuoteDeviation = errors.New("pool quote deviates from reference price")
+
+// Swapper is a venue the bot trades ETH against the quote token on.
+// UniswapV2 and UniswapV3 implement it.
+type Swapper interface {
+	Name() string
+	ExplorerURL(txHash string) string
+	ETHBalance(ctx context.Context) (float64, error)
+	TokenBalance(ctx context.Context) (float64, error)
+	GasCostETH(ctx context.Context) (float64, error)
+
+	QuoteBuy(ctx context.Context, usdcAmount float64) (*Quote, error)
+	QuoteSell(ctx context.Context, ethAmount float64) (*Quote, error)
+
+	// SwapUSDCForETH and SwapETHForUSDC quote the pool, refuse the trade if
+	// the quote is too far from refPrice, and send the swap with a minimum
+	// output derived from the quote. They return the transaction hash.
+	SwapUSDCForETH(ctx context.Context, usdcAmount, refPrice float64) (string, error)
+	SwapETHForUSDC(ctx context.Context, ethAmount, refPrice float64) (string, error)
+	WaitForSwap(ctx context.Context, txHash, side string, opts ReceiptOptions) (*SwapResult, error)
+}
+
+var (
+	_ Swapper = (*UniswapV2)(nil)
+	_ Swapper = (*UniswapV3)(nil)
+)
+
+// swapBase is what every Uniswap version shares: the wallet client, the
+// WETH/quote-token pair and the router that spends the quote token.
+type swapBase struct {
+	client      *Client
+	routerAddr  common.Address
+	wethAddr    common.Address
+	quoteAddr   common.Address
+	quoteSymbol string
+	quoteDec    int
+	slippagePct float64
+	maxDevPct   float64
+	erc20ABI    abi.ABI
+}
+
 // UniswapV2 wraps an Ethereum Client and provides Uniswap V2 Router swap methods.
 type UniswapV2 struct {
-	client       *Client
-	routerAddr   common.Address
-	wethAddr     common.Address
-	quoteAddr    common.Address
-	quoteSymbol  string
-	quoteDec     int
-	slippagePct  float64
-	routerABI    abi.ABI
-	erc20ABI     abi.ABI
+	swapBase
+	routerABI abi.ABI
+	pairABI   abi.ABI
 }
 
 func NewUniswapV2(
@@ -32,6 +70,7 @@ func NewUniswapV2(
 	quoteSymbol string,
 	quoteDecimals int,
 	slippagePct float64,
+	maxDeviationPct float64,
 ) (*UniswapV2, error) {
 	rABI, err := abi.JSON(mustRouterABI())
 	if err != nil {
@@ -41,25 +80,35 @@ func NewUniswapV2(
 	if err != nil {
 		return nil, fmt.Errorf("parse ERC20 ABI: %w", err)
 	}
+	pABI, err := abi.JSON(mustPairABI())
+	if err != nil {
+		return nil, fmt.Errorf("parse pair ABI: %w", err)
+	}
 	return &UniswapV2{
-		client:      client,
-		routerAddr:  common.HexToAddress(routerAddr),
-		wethAddr:    common.HexToAddress(wethAddr),
-		quoteAddr:   common.HexToAddress(quoteAddr),
-		quoteSymbol: quoteSymbol,
-		quoteDec:    quoteDecimals,
-		slippagePct: slippagePct,
-		routerABI:   rABI,
-		erc20ABI:    eABI,
+		swapBase: swapBase{
+			client:      client,
+			routerAddr:  common.HexToAddress(routerAddr),
+			wethAddr:    common.HexToAddress(wethAddr),
+			quoteAddr:   common.HexToAddress(quoteAddr),
+			quoteSymbol: quoteSymbol,
+			quoteDec:    quoteDecimals,
+			slippagePct: slippagePct,
+			maxDevPct:   maxDeviationPct,
+			erc20ABI:    eABI,
+		},
+		routerABI: rABI,
+		pairABI:   pABI,
 	}, nil
 }
 
-func (u *UniswapV2) ExplorerURL(txHash string) string {
+func (u *UniswapV2) Name() string { return "Uniswap V2" }
+
+func (u *swapBase) ExplorerURL(txHash string) string {
 	return explorerTxPrefix + txHash
 }
 
 // TokenBalance returns the ERC20 token balance as a human-readable float.
-func (u *UniswapV2) TokenBalance(ctx context.Context) (float64, error) {
+func (u *swapBase) TokenBalance(ctx context.Context) (float64, error) {
 	data, err := u.erc20ABI.Pack("balanceOf", u.client.wallet)
 	if err != nil {
 		return 0, err
@@ -75,7 +124,7 @@ func (u *UniswapV2) TokenBalance(ctx context.Context) (float64, error) {
 }
 
 // ETHBalance returns wallet ETH balance as a human-readable float.
-func (u *UniswapV2) ETHBalance(ctx context.Context) (float64, error) {
+func (u *swapBase) ETHBalance(ctx context.Context) (float64, error) {
 	bal, err := u.client.ETHBalance(ctx)
 	if err != nil {
 		return 0, err
@@ -85,7 +134,7 @@ func (u *UniswapV2) ETHBalance(ctx context.Context) (float64, error) {
 }
 
 // EnsureAllowance checks the router's allowance for the quote token and approves max if needed.
-func (u *UniswapV2) EnsureAllowance(ctx context.Context, requiredAmount float64) error {
+func (u *swapBase) EnsureAllowance(ctx context.Context, requiredAmount float64) error {
 	data, err := u.erc20ABI.Pack("allowance", u.client.wallet, u.routerAddr)
 	if err != nil {
 		return err
@@ -101,7 +150,7 @@ func (u *UniswapV2) EnsureAllowance(ctx context.Context, requiredAmount float64)
 		return nil
 	}
 
-	fmt.Printf("Setting %s allowance for Uniswap Router...\n", u.quoteSymbol)
+	fmt.Printf("Setting %s allowance for router %s...\n", u.quoteSymbol, u.routerAddr.Hex())
 	maxUint256 := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
 	approveData, err := u.erc20ABI.Pack("approve", u.routerAddr, maxUint256)
 	if err != nil {
@@ -112,25 +161,116 @@ func (u *UniswapV2) EnsureAllowance(ctx context.Context, requiredAmount float64)
 	if err != nil {
 		return fmt.Errorf("approve tx: %w", err)
 	}
+	// The swap spends the allowance, so it must be mined first.
+	if _, err := u.client.WaitForReceipt(ctx, txHash, ReceiptOptions{Timeout: 5 * time.Minute}); err != nil {
+		return fmt.Errorf("approve tx: %w", err)
+	}
 	fmt.Printf("Allowance TX confirmed: %s\n", u.ExplorerURL(txHash))
 	return nil
 }
 
-// SwapUSDCForETH executes swapExactTokensForETH on the Uniswap V2 Router.
-// Returns the transaction hash.
-func (u *UniswapV2) SwapUSDCForETH(ctx context.Context, usdcAmount, minETHOut float64) (string, error) {
+// Quote is the pool's answer for a swap (getAmountsOut on V2, QuoterV2 on
+// V3), with the minimum output derived from it.
+type Quote struct {
+	AmountIn  *big.Int
+	AmountOut *big.Int
+	MinOut    *big.Int // AmountOut less SlippageTolerance
+	Price     float64  // quote tokens per ETH implied by the pool
+	FeeTier   uint32   // V3 pool fee in hundredths of a bip; 0 on V2
+}
+
+func (u *swapBase) newQuote(side string, in, out *big.Int) *Quote {
+	q := &Quote{AmountIn: in, AmountOut: out, MinOut: applySlippage(out, u.slippagePct)}
+	eth, tokens := fromWei(out, 18), fromWei(in, u.quoteDec)
+	if side == "sell" {
+		eth, tokens = fromWei(in, 18), fromWei(out, u.quoteDec)
+	}
+	if eth > 0 {
+		q.Price = tokens / eth
+	}
+	return q
+}
+
+// QuoteBuy quotes spending usdcAmount of the quote token for ETH.
+func (u *UniswapV2) QuoteBuy(ctx context.Context, usdcAmount float64) (*Quote, error) {
+	in := toTokenWei(usdcAmount, u.quoteDec)
+	out, err := u.amountsOut(ctx, in, []common.Address{u.quoteAddr, u.wethAddr})
+	if err != nil {
+		return nil, err
+	}
+	return u.newQuote("buy", in, out), nil
+}
+
+// QuoteSell quotes selling ethAmount of ETH for the quote token.
+func (u *UniswapV2) QuoteSell(ctx context.Context, ethAmount float64) (*Quote, error) {
+	in := toEthWei(ethAmount)
+	out, err := u.amountsOut(ctx, in, []common.Address{u.wethAddr, u.quoteAddr})
+	if err != nil {
+		return nil, err
+	}
+	return u.newQuote("sell", in, out), nil
+}
+
+func (u *UniswapV2) amountsOut(ctx context.Context, amountIn *big.Int, path []common.Address) (*big.Int, error) {
+	data, err := u.routerABI.Pack("getAmountsOut", amountIn, path)
+	if err != nil {
+		return nil, fmt.Errorf("pack getAmountsOut: %w", err)
+	}
+	result, err := u.client.CallContract(ctx, u.routerAddr, data)
+	if err != nil {
+		return nil, fmt.Errorf("getAmountsOut call: %w", err)
+	}
+	vals, err := u.routerABI.Unpack("getAmountsOut", result)
+	if err != nil {
+		return nil, fmt.Errorf("decode getAmountsOut: %w", err)
+	}
+	amounts, ok := vals[0].([]*big.Int)
+	if !ok || len(amounts) != len(path) {
+		return nil, fmt.Errorf("unexpected getAmountsOut result")
+	}
+	return amounts[len(amounts)-1], nil
+}
+
+// CheckDeviation refuses a quote whose price is more than the configured
+// percentage away from refPrice. A zero limit disables the check.
+func (u *swapBase) CheckDeviation(q *Quote, refPrice float64) error {
+	return checkDeviation(q.Price, refPrice, u.maxDevPct)
+}
+
+func checkDeviation(poolPrice, refPrice, maxPct float64) error {
+	if maxPct <= 0 || refPrice <= 0 {
+		return nil
+	}
+	dev := math.Abs(poolPrice-refPrice) / refPrice * 100
+	if dev > maxPct {
+		return fmt.Errorf("%w: pool $%.2f vs reference $%.2f (%.2f%% > %.2f%%)",
+			ErrQuoteDeviation, poolPrice, refPrice, dev, maxPct)
+	}
+	return nil
+}
+
+// SwapUSDCForETH executes swapExactTokensForETH on the Uniswap V2 Router,
+// with the minimum output taken from the pool's quote. It refuses to trade
+// if the pool price is too far from refPrice. Returns the transaction hash.
+func (u *UniswapV2) SwapUSDCForETH(ctx context.Context, usdcAmount, refPrice float64) (string, error) {
+	// Approving can wait minutes for a receipt, so quote afterwards: the
+	// minimum output must reflect the pool when the swap is sent.
 	if err := u.EnsureAllowance(ctx, usdcAmount); err != nil {
 		return "", err
 	}
+	q, err := u.QuoteBuy(ctx, usdcAmount)
+	if err != nil {
+		return "", err
+	}
+	if err := u.CheckDeviation(q, refPrice); err != nil {
+		return "", err
+	}
 
 	path := []common.Address{u.quoteAddr, u.wethAddr}
 	deadline := big.NewInt(time.Now().Unix() + 20*60)
-	amountIn := toTokenWei(usdcAmount, u.quoteDec)
-	// Apply slippage to minETHOut
-	minOutWei := toEthWei(minETHOut * (1 - u.slippagePct/100))
 
 	data, err := u.routerABI.Pack("swapExactTokensForETH",
-		amountIn, minOutWei, path, u.client.wallet, deadline)
+		q.AmountIn, q.MinOut, path, u.client.wallet, deadline)
 	if err != nil {
 		return "", fmt.Errorf("pack swapExactTokensForETH: %w", err)
 	}
@@ -138,35 +278,52 @@ func (u *UniswapV2) SwapUSDCForETH(ctx context.Context, usdcAmount, minETHOut fl
 	return u.client.SignAndSend(ctx, u.routerAddr, big.NewInt(0), data)
 }
 
-// SwapETHForUSDC executes swapExactETHForTokens on the Uniswap V2 Router.
-// Returns the transaction hash.
-func (u *UniswapV2) SwapETHForUSDC(ctx context.Context, ethAmount float64) (string, error) {
+// SwapETHForUSDC executes swapExactETHForTokens on the Uniswap V2 Router,
+// with the minimum output taken from the pool's quote. It refuses to trade
+// if the pool price is too far from refPrice. Returns the transaction hash.
+func (u *UniswapV2) SwapETHForUSDC(ctx context.Context, ethAmount, refPrice float64) (string, error) {
+	q, err := u.QuoteSell(ctx, ethAmount)
+	if err != nil {
+		return "", err
+	}
+	if err := u.CheckDeviation(q, refPrice); err != nil {
+		return "", err
+	}
+
 	path := []common.Address{u.wethAddr, u.quoteAddr}
 	deadline := big.NewInt(time.Now().Unix() + 20*60)
-	value := toEthWei(ethAmount)
 
 	data, err := u.routerABI.Pack("swapExactETHForTokens",
-		big.NewInt(0), path, u.client.wallet, deadline)
+		q.MinOut, path, u.client.wallet, deadline)
 	if err != nil {
 		return "", fmt.Errorf("pack swapExactETHForTokens: %w", err)
 	}
 
-	return u.client.SignAndSend(ctx, u.routerAddr, value, data)
+	return u.client.SignAndSend(ctx, u.routerAddr, q.AmountIn, data)
 }
 
-// GasCostETH estimates the gas cost for a transaction in ETH.
-func (u *UniswapV2) GasCostETH(ctx context.Context) (float64, error) {
-	gasPrice, err := u.client.GasPrice(ctx)
+// GasCostETH estimates the gas cost for a transaction in ETH at the
+// effective gas price it would pay now (base fee plus tip under EIP-1559,
+// not the fee cap). The actual cost is read from the receipt.
+func (u *swapBase) GasCostETH(ctx context.Context) (float64, error) {
+	fees, err := u.client.SuggestFees(ctx)
 	if err != nil {
 		return 0, err
 	}
-	cost := new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(u.client.GasLimit()))
+	cost := new(big.Int).Mul(fees.EffectiveGasPrice(), new(big.Int).SetUint64(u.client.GasLimit()))
 	f, _ := new(big.Float).Quo(new(big.Float).SetInt(cost), new(big.Float).SetFloat64(1e18)).Float64()
 	return f, nil
 }
 
 // --- helpers ---
 
+// applySlippage returns amount reduced by pct percent, rounded down.
+func applySlippage(amount *big.Int, pct float64) *big.Int {
+	bps := big.NewInt(int64(math.Round((100 - pct) * 100)))
+	out := new(big.Int).Mul(amount, bps)
+	return out.Quo(out, big.NewInt(10000))
+}
+
 func toEthWei(eth float64) *big.Int {
 	// eth * 1e18
 	f := new(big.Float).Mul(new(big.Float).SetFloat64(eth), new(big.Float).SetFloat64(1e18))
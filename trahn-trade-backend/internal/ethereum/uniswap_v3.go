@@ -0,0 +1,271 @@
+package ethereum
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// V3AutoFeeTiers are the pools quoted when no fee tier is configured:
+// 0.05% and 0.3%. The tier paying the most for each trade is used.
+var V3AutoFeeTiers = []uint32{500, 3000}
+
+// UniswapV3 trades through the Uniswap V3 SwapRouter's exactInputSingle,
+// quoting with QuoterV2.
+type UniswapV3 struct {
+	swapBase
+	quoterAddr common.Address
+	feeTiers   []uint32
+	routerABI  abi.ABI
+	quoterABI  abi.ABI
+	poolABI    abi.ABI
+}
+
+// exactInputSingleParams mirrors ISwapRouter.ExactInputSingleParams.
+type exactInputSingleParams struct {
+	TokenIn           common.Address
+	TokenOut          common.Address
+	Fee               *big.Int
+	Recipient         common.Address
+	Deadline          *big.Int
+	AmountIn          *big.Int
+	AmountOutMinimum  *big.Int
+	SqrtPriceLimitX96 *big.Int
+}
+
+// quoteExactInputSingleParams mirrors IQuoterV2.QuoteExactInputSingleParams.
+type quoteExactInputSingleParams struct {
+	TokenIn           common.Address
+	TokenOut          common.Address
+	AmountIn          *big.Int
+	Fee               *big.Int
+	SqrtPriceLimitX96 *big.Int
+}
+
+// NewUniswapV3 creates a V3 swapper. feeTier is the pool fee in hundredths
+// of a bip (500 = 0.05%); 0 picks the best of V3AutoFeeTiers per trade.
+func NewUniswapV3(
+	client *Client,
+	routerAddr, quoterAddr, wethAddr, quoteAddr string,
+	quoteSymbol string,
+	quoteDecimals int,
+	feeTier uint32,
+	slippagePct float64,
+	maxDeviationPct float64,
+) (*UniswapV3, error) {
+	rABI, err := abi.JSON(mustV3RouterABI())
+	if err != nil {
+		return nil, fmt.Errorf("parse V3 router ABI: %w", err)
+	}
+	qABI, err := abi.JSON(mustV3QuoterABI())
+	if err != nil {
+		return nil, fmt.Errorf("parse V3 quoter ABI: %w", err)
+	}
+	pABI, err := abi.JSON(mustV3PoolABI())
+	if err != nil {
+		return nil, fmt.Errorf("parse V3 pool ABI: %w", err)
+	}
+	eABI, err := abi.JSON(mustERC20ABI())
+	if err != nil {
+		return nil, fmt.Errorf("parse ERC20 ABI: %w", err)
+	}
+
+	tiers := V3AutoFeeTiers
+	if feeTier != 0 {
+		tiers = []uint32{feeTier}
+	}
+	return &UniswapV3{
+		swapBase: swapBase{
+			client:      client,
+			routerAddr:  common.HexToAddress(routerAddr),
+			wethAddr:    common.HexToAddress(wethAddr),
+			quoteAddr:   common.HexToAddress(quoteAddr),
+			quoteSymbol: quoteSymbol,
+			quoteDec:    quoteDecimals,
+			slippagePct: slippagePct,
+			maxDevPct:   maxDeviationPct,
+			erc20ABI:    eABI,
+		},
+		quoterAddr: common.HexToAddress(quoterAddr),
+		feeTiers:   tiers,
+		routerABI:  rABI,
+		quoterABI:  qABI,
+		poolABI:    pABI,
+	}, nil
+}
+
+func (u *UniswapV3) Name() string { return "Uniswap V3" }
+
+// QuoteBuy quotes spending usdcAmount of the quote token for ETH.
+func (u *UniswapV3) QuoteBuy(ctx context.Context, usdcAmount float64) (*Quote, error) {
+	return u.bestQuote(ctx, "buy", toTokenWei(usdcAmount, u.quoteDec))
+}
+
+// QuoteSell quotes selling ethAmount of ETH for the quote token.
+func (u *UniswapV3) QuoteSell(ctx context.Context, ethAmount float64) (*Quote, error) {
+	return u.bestQuote(ctx, "sell", toEthWei(ethAmount))
+}
+
+// bestQuote quotes every candidate fee tier and keeps the largest output.
+// Quotes already net out the pool fee, so this is the cheapest pool for
+// the trade's size. Tiers without a pool are skipped.
+func (u *UniswapV3) bestQuote(ctx context.Context, side string, amountIn *big.Int) (*Quote, error) {
+	tokenIn, tokenOut := u.quoteAddr, u.wethAddr
+	if side == "sell" {
+		tokenIn, tokenOut = u.wethAddr, u.quoteAddr
+	}
+
+	var best *Quote
+	var lastErr error
+	for _, fee := range u.feeTiers {
+		out, err := u.quoteExactInputSingle(ctx, tokenIn, tokenOut, amountIn, fee)
+		if err != nil {
+			lastErr = fmt.Errorf("fee tier %d: %w", fee, err)
+			continue
+		}
+		if best == nil || out.Cmp(best.AmountOut) > 0 {
+			best = u.newQuote(side, amountIn, out)
+			best.FeeTier = fee
+		}
+	}
+	if best == nil {
+		return nil, lastErr
+	}
+	return best, nil
+}
+
+func (u *UniswapV3) quoteExactInputSingle(ctx context.Context, tokenIn, tokenOut common.Address, amountIn *big.Int, fee uint32) (*big.Int, error) {
+	data, err := u.quoterABI.Pack("quoteExactInputSingle", quoteExactInputSingleParams{
+		TokenIn:           tokenIn,
+		TokenOut:          tokenOut,
+		AmountIn:          amountIn,
+		Fee:               big.NewInt(int64(fee)),
+		SqrtPriceLimitX96: new(big.Int),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("pack quoteExactInputSingle: %w", err)
+	}
+	result, err := u.client.CallContract(ctx, u.quoterAddr, data)
+	if err != nil {
+		return nil, fmt.Errorf("quoteExactInputSingle call: %w", err)
+	}
+	vals, err := u.quoterABI.Unpack("quoteExactInputSingle", result)
+	if err != nil {
+		return nil, fmt.Errorf("decode quoteExactInputSingle: %w", err)
+	}
+	out, ok := vals[0].(*big.Int)
+	if !ok {
+		return nil, fmt.Errorf("unexpected quoteExactInputSingle result")
+	}
+	return out, nil
+}
+
+// SwapUSDCForETH swaps the quote token for WETH with exactInputSingle and
+// unwraps it to the wallet in the same multicall.
+func (u *UniswapV3) SwapUSDCForETH(ctx context.Context, usdcAmount, refPrice float64) (string, error) {
+	// Quote after approving, as in UniswapV2.SwapUSDCForETH.
+	if err := u.EnsureAllowance(ctx, usdcAmount); err != nil {
+		return "", err
+	}
+	q, err := u.QuoteBuy(ctx, usdcAmount)
+	if err != nil {
+		return "", err
+	}
+	if err := u.CheckDeviation(q, refPrice); err != nil {
+		return "", err
+	}
+
+	// WETH goes to the router, which unwraps it to the wallet.
+	swap, err := u.packExactInputSingle(u.quoteAddr, u.wethAddr, u.routerAddr, q)
+	if err != nil {
+		return "", err
+	}
+	unwrap, err := u.routerABI.Pack("unwrapWETH9", q.MinOut, u.client.wallet)
+	if err != nil {
+		return "", fmt.Errorf("pack unwrapWETH9: %w", err)
+	}
+	data, err := u.routerABI.Pack("multicall", [][]byte{swap, unwrap})
+	if err != nil {
+		return "", fmt.Errorf("pack multicall: %w", err)
+	}
+
+	return u.client.SignAndSend(ctx, u.routerAddr, big.NewInt(0), data)
+}
+
+// SwapETHForUSDC sends ETH with exactInputSingle; the router wraps it.
+func (u *UniswapV3) SwapETHForUSDC(ctx context.Context, ethAmount, refPrice float64) (string, error) {
+	q, err := u.QuoteSell(ctx, ethAmount)
+	if err != nil {
+		return "", err
+	}
+	if err := u.CheckDeviation(q, refPrice); err != nil {
+		return "", err
+	}
+
+	data, err := u.packExactInputSingle(u.wethAddr, u.quoteAddr, u.client.wallet, q)
+	if err != nil {
+		return "", err
+	}
+
+	return u.client.SignAndSend(ctx, u.routerAddr, q.AmountIn, data)
+}
+
+func (u *UniswapV3) packExactInputSingle(tokenIn, tokenOut, recipient common.Address, q *Quote) ([]byte, error) {
+	data, err := u.routerABI.Pack("exactInputSingle", exactInputSingleParams{
+		TokenIn:           tokenIn,
+		TokenOut:          tokenOut,
+		Fee:               big.NewInt(int64(q.FeeTier)),
+		Recipient:         recipient,
+		Deadline:          big.NewInt(time.Now().Unix() + 20*60),
+		AmountIn:          q.AmountIn,
+		AmountOutMinimum:  q.MinOut,
+		SqrtPriceLimitX96: new(big.Int),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("pack exactInputSingle: %w", err)
+	}
+	return data, nil
+}
+
+// WaitForSwap waits for a swap transaction to confirm and decodes the
+// amounts actually exchanged, as UniswapV2.WaitForSwap does.
+func (u *UniswapV3) WaitForSwap(ctx context.Context, txHash, side string, opts ReceiptOptions) (*SwapResult, error) {
+	return u.waitForSwap(ctx, txHash, side, opts, u.decodeSwap)
+}
+
+// decodeSwap reads the pool's Swap event, whose signed amounts are positive
+// into the pool and negative out of it, for the ETH amount, and the quote
+// token's Transfer logs for the token amount.
+func (u *UniswapV3) decodeSwap(receipt *types.Receipt, side string, wallet common.Address) (*SwapResult, error) {
+	swapID := u.poolABI.Events["Swap"].ID
+	wethIsToken0 := bytes.Compare(u.wethAddr.Bytes(), u.quoteAddr.Bytes()) < 0
+
+	var ethWei, swapTokenWei *big.Int
+	for _, lg := range receipt.Logs {
+		if len(lg.Topics) == 0 || lg.Topics[0] != swapID {
+			continue
+		}
+		vals, err := u.poolABI.Unpack("Swap", lg.Data)
+		if err != nil {
+			return nil, fmt.Errorf("decode Swap event: %w", err)
+		}
+		ethDelta, tokenDelta := vals[1].(*big.Int), vals[0].(*big.Int)
+		if wethIsToken0 {
+			ethDelta, tokenDelta = vals[0].(*big.Int), vals[1].(*big.Int)
+		}
+		ethWei = new(big.Int).Abs(ethDelta)
+		swapTokenWei = new(big.Int).Abs(tokenDelta)
+		break
+	}
+
+	if ethWei == nil {
+		return nil, fmt.Errorf("no Uniswap V3 Swap event in receipt %s", receipt.TxHash.Hex())
+	}
+	return u.swapResult(receipt, side, wallet, ethWei, swapTokenWei), nil
+}
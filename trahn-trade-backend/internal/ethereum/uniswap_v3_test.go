@@ -0,0 +1,107 @@
+package ethereum
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+var testPool = common.HexToAddress("0x88e6A0c2dDD26FEEb64F039a2c41296FcB3f5640")
+
+func testUniswapV3(t *testing.T, feeTier uint32) *UniswapV3 {
+	t.Helper()
+	u, err := NewUniswapV3(nil, "0xE592427A0AEce92De3Edee1F18E0157C05861564",
+		"0x61fFE014bA17989E743c5F6cB21bF9697530B21e", testWETH, testUSDC, "USDC", 6, feeTier, 1.5, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u
+}
+
+// v3SwapLog builds a pool Swap event. USDC sorts before WETH, so USDC is
+// token0; positive amounts go into the pool.
+func v3SwapLog(t *testing.T, u *UniswapV3, amount0, amount1 *big.Int) *types.Log {
+	t.Helper()
+	ev := u.poolABI.Events["Swap"]
+	data, err := ev.Inputs.NonIndexed().Pack(amount0, amount1, big.NewInt(1), big.NewInt(1), big.NewInt(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &types.Log{
+		Address: testPool,
+		Topics:  []common.Hash{ev.ID, common.BytesToHash(u.routerAddr.Bytes()), common.BytesToHash(testWallet.Bytes())},
+		Data:    data,
+	}
+}
+
+func TestNewUniswapV3_FeeTiers(t *testing.T) {
+	if got := testUniswapV3(t, 0).feeTiers; len(got) != 2 || got[0] != 500 || got[1] != 3000 {
+		t.Errorf("auto fee tiers = %v, want [500 3000]", got)
+	}
+	if got := testUniswapV3(t, 3000).feeTiers; len(got) != 1 || got[0] != 3000 {
+		t.Errorf("fixed fee tier = %v, want [3000]", got)
+	}
+}
+
+func TestV3DecodeSwap_Buy(t *testing.T) {
+	u := testUniswapV3(t, 500)
+	usdcIn := big.NewInt(100_000_000)
+	ethOut := toEthWei(0.05)
+	r := receipt(
+		transferLog(&u.swapBase, testWallet, testPool, usdcIn),
+		v3SwapLog(t, u, usdcIn, new(big.Int).Neg(ethOut)),
+	)
+
+	res, err := u.decodeSwap(r, "buy", testWallet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(res.ETHAmount-0.05) > 1e-12 || math.Abs(res.TokenAmount-100) > 1e-9 {
+		t.Fatalf("unexpected amounts: %f ETH, %f USDC", res.ETHAmount, res.TokenAmount)
+	}
+}
+
+func TestV3DecodeSwap_Sell(t *testing.T) {
+	u := testUniswapV3(t, 500)
+	ethIn := toEthWei(0.05)
+	r := receipt(v3SwapLog(t, u, big.NewInt(-101_000_000), ethIn))
+
+	res, err := u.decodeSwap(r, "sell", testWallet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(res.ETHAmount-0.05) > 1e-12 || math.Abs(res.TokenAmount-101) > 1e-9 {
+		t.Fatalf("unexpected amounts: %f ETH, %f USDC", res.ETHAmount, res.TokenAmount)
+	}
+}
+
+func TestV3PackExactInputSingle(t *testing.T) {
+	u := testUniswapV3(t, 500)
+	q := &Quote{AmountIn: toEthWei(0.05), MinOut: big.NewInt(98_500_000), FeeTier: 500}
+	data, err := u.packExactInputSingle(u.wethAddr, u.quoteAddr, testWallet, q)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := u.routerABI.Methods["exactInputSingle"]
+	vals, err := m.Inputs.Unpack(data[4:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := vals[0].(struct {
+		TokenIn           common.Address `json:"tokenIn"`
+		TokenOut          common.Address `json:"tokenOut"`
+		Fee               *big.Int       `json:"fee"`
+		Recipient         common.Address `json:"recipient"`
+		Deadline          *big.Int       `json:"deadline"`
+		AmountIn          *big.Int       `json:"amountIn"`
+		AmountOutMinimum  *big.Int       `json:"amountOutMinimum"`
+		SqrtPriceLimitX96 *big.Int       `json:"sqrtPriceLimitX96"`
+	})
+	if p.TokenIn != u.wethAddr || p.Recipient != testWallet || p.Fee.Int64() != 500 || p.AmountOutMinimum.Int64() != 98_500_000 {
+		t.Fatalf("unexpected params: %+v", p)
+	}
+}
@@ -10,7 +10,7 @@ import (
 	"github.com/kjannette/trahn-backend/internal/httputil"
 )
 
-const coingeckoURL = "https://api.coingecko.com/api/v3/simple/price?ids=ethereum&vs_currencies=usd"
+const coingeckoURL = "https://api.coingecko.com/api/v3/simple/price?ids=ethereum&vs_currencies=usd&include_last_updated_at=true"
 
 type CoinGeckoClient struct {
 	httpClient *http.Client
@@ -28,31 +28,45 @@ func NewCoinGeckoClient() *CoinGeckoClient {
 	}
 }
 
+func (c *CoinGeckoClient) Name() string { return "coingecko" }
+
 func (c *CoinGeckoClient) GetETHPrice(ctx context.Context) (float64, error) {
+	price, _, err := c.Price(ctx)
+	return price, err
+}
+
+// Price returns the ETH price and when CoinGecko last updated it, making
+// the client an oracle.PriceSource.
+func (c *CoinGeckoClient) Price(ctx context.Context) (float64, time.Time, error) {
 	resp, err := httputil.Do(ctx, c.httpClient, c.retry, func() (*http.Request, error) {
 		return http.NewRequestWithContext(ctx, http.MethodGet, coingeckoURL, nil)
 	})
 	if err != nil {
-		return 0, fmt.Errorf("coingecko fetch: %w", err)
+		return 0, time.Time{}, fmt.Errorf("coingecko fetch: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return 0, fmt.Errorf("coingecko returned status %d", resp.StatusCode)
+		return 0, time.Time{}, fmt.Errorf("coingecko returned status %d", resp.StatusCode)
 	}
 
 	var data struct {
 		Ethereum struct {
-			USD float64 `json:"usd"`
+			USD           float64 `json:"usd"`
+			LastUpdatedAt int64   `json:"last_updated_at"`
 		} `json:"ethereum"`
 	}
 	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
-		return 0, fmt.Errorf("decode: %w", err)
+		return 0, time.Time{}, fmt.Errorf("decode: %w", err)
 	}
 
 	if data.Ethereum.USD <= 0 {
-		return 0, fmt.Errorf("invalid price: %f", data.Ethereum.USD)
+		return 0, time.Time{}, fmt.Errorf("invalid price: %f", data.Ethereum.USD)
 	}
 
-	return data.Ethereum.USD, nil
+	at := time.Now()
+	if data.Ethereum.LastUpdatedAt > 0 {
+		at = time.Unix(data.Ethereum.LastUpdatedAt, 0)
+	}
+	return data.Ethereum.USD, at, nil
 }
@@ -16,7 +16,7 @@ import (
 type DuneClient struct {
 	apiKey       string
 	baseURL      string
-	method       string // "simple" or "percentile"
+	method       string // "simple", "percentile" or "volume-profile"
 	lookbackDays int
 	httpClient   *http.Client
 	retry        httputil.RetryConfig
@@ -37,6 +37,14 @@ type SRResult struct {
 	FetchedAt    time.Time `json:"fetchedAt"`
 }
 
+// SRProvider supplies support/resistance levels. forceRefresh bypasses any
+// cache the provider keeps.
+type SRProvider interface {
+	FetchSupportResistance(ctx context.Context, forceRefresh bool) (*SRResult, error)
+}
+
+var _ SRProvider = (*DuneClient)(nil)
+
 type DuneOptions struct {
 	Method       string
 	LookbackDays int
@@ -83,25 +91,16 @@ func (d *DuneClient) FetchSupportResistance(ctx context.Context, forceRefresh bo
 	}
 	d.mu.Unlock()
 
-	sql := d.buildSRQuery()
-	rows, err := d.executeQuery(ctx, sql)
+	var result *SRResult
+	var err error
+	if d.method == "volume-profile" {
+		result, err = d.fetchVolumeProfile(ctx)
+	} else {
+		result, err = d.fetchRange(ctx)
+	}
 	if err != nil {
 		return nil, err
 	}
-	if len(rows) == 0 {
-		return nil, fmt.Errorf("dune returned no data for S/R query")
-	}
-
-	row := rows[0]
-	result := &SRResult{
-		Support:      jsonFloat(row, "support"),
-		Resistance:   jsonFloat(row, "resistance"),
-		Midpoint:     jsonFloat(row, "midpoint"),
-		AvgPrice:     jsonFloat(row, "avg_price"),
-		Method:       d.method,
-		LookbackDays: d.lookbackDays,
-		FetchedAt:    time.Now(),
-	}
 
 	if math.IsNaN(result.Support) || math.IsNaN(result.Resistance) || math.IsNaN(result.Midpoint) {
 		return nil, fmt.Errorf("invalid S/R data from Dune")
@@ -124,6 +123,29 @@ func (d *DuneClient) FetchSupportResistance(ctx context.Context, forceRefresh bo
 	return result, nil
 }
 
+// fetchRange runs the simple or percentile query, which returns the levels
+// as a single row.
+func (d *DuneClient) fetchRange(ctx context.Context) (*SRResult, error) {
+	rows, err := d.executeQuery(ctx, d.buildSRQuery())
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("dune returned no data for S/R query")
+	}
+
+	row := rows[0]
+	return &SRResult{
+		Support:      jsonFloat(row, "support"),
+		Resistance:   jsonFloat(row, "resistance"),
+		Midpoint:     jsonFloat(row, "midpoint"),
+		AvgPrice:     jsonFloat(row, "avg_price"),
+		Method:       d.method,
+		LookbackDays: d.lookbackDays,
+		FetchedAt:    time.Now(),
+	}, nil
+}
+
 // SeedCache pre-populates the in-memory cache from a previously persisted
 // S/R result (e.g. loaded from the database on startup). The cached entry
 // is only used if it falls within the configured TTL.
@@ -29,6 +29,34 @@ func TestCoinGeckoGetETHPrice(t *testing.T) {
 	t.Logf("ETH price: $%.2f", price)
 }
 
+func TestValueArea(t *testing.T) {
+	// Bins 2000..2090: volume peaks at 2040 and tails off on both sides.
+	vols := []float64{1, 2, 5, 10, 30, 20, 15, 8, 3, 1}
+	nodes := make([]external.VolumeNode, len(vols))
+	for i, v := range vols {
+		nodes[len(vols)-1-i] = external.VolumeNode{Price: 2000 + float64(i)*10, Volume: v} // unsorted input
+	}
+
+	poc, val, vah, vwap, err := external.ValueArea(nodes, 0.70)
+	if err != nil {
+		t.Fatalf("ValueArea: %v", err)
+	}
+	if poc != 2045 {
+		t.Errorf("poc = %.2f, want 2045", poc)
+	}
+	// 30+20+15 = 65 of 95 is short of 70%; adding 10 (2030) reaches 75.
+	if val != 2030 || vah != 2070 {
+		t.Errorf("value area = %.2f-%.2f, want 2030-2070", val, vah)
+	}
+	if vwap <= val || vwap >= vah {
+		t.Errorf("vwap %.2f outside value area", vwap)
+	}
+
+	if _, _, _, _, err := external.ValueArea(nodes[:1], 0.70); err == nil {
+		t.Error("expected error for a single price level")
+	}
+}
+
 func TestDuneFetchSupportResistance(t *testing.T) {
 	apiKey := os.Getenv("DUNE_API_KEY")
 	if apiKey == "" {
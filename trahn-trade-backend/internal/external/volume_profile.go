@@ -0,0 +1,134 @@
+package external
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	// volumeProfileBucketUSD is the price width of each volume-profile bin.
+	volumeProfileBucketUSD = 10
+	// valueAreaFraction is the share of traded volume the value area holds.
+	valueAreaFraction = 0.70
+)
+
+// VolumeNode is the USD volume traded within one price bin.
+type VolumeNode struct {
+	Price  float64 // lower edge of the bin
+	Volume float64
+}
+
+// fetchVolumeProfile bins WETH/USDC DEX trades by execution price and
+// returns the value area: the point of control (the bin with the most
+// volume) as midpoint, and the edges of the band holding 70% of the volume
+// around it as support and resistance.
+func (d *DuneClient) fetchVolumeProfile(ctx context.Context) (*SRResult, error) {
+	rows, err := d.executeQuery(ctx, d.buildVolumeProfileQuery())
+	if err != nil {
+		return nil, err
+	}
+	nodes := make([]VolumeNode, 0, len(rows))
+	for _, row := range rows {
+		n := VolumeNode{Price: jsonFloat(row, "price_level"), Volume: jsonFloat(row, "volume")}
+		if math.IsNaN(n.Price) || math.IsNaN(n.Volume) {
+			continue
+		}
+		nodes = append(nodes, n)
+	}
+
+	poc, val, vah, vwap, err := ValueArea(nodes, valueAreaFraction)
+	if err != nil {
+		return nil, err
+	}
+	return &SRResult{
+		Support:      val,
+		Resistance:   vah,
+		Midpoint:     poc,
+		AvgPrice:     vwap,
+		Method:       d.method,
+		LookbackDays: d.lookbackDays,
+		FetchedAt:    time.Now(),
+	}, nil
+}
+
+// ValueArea finds the point of control of a volume profile and grows the
+// value area from it, one bin at a time towards the side with more volume,
+// until it holds fraction of the total. val and vah are the low edge of the
+// lowest bin and the high edge of the highest bin in the area; poc is the
+// centre of the busiest bin. vwap is the volume-weighted bin centre.
+func ValueArea(nodes []VolumeNode, fraction float64) (poc, val, vah, vwap float64, err error) {
+	if len(nodes) < 2 {
+		return 0, 0, 0, 0, fmt.Errorf("volume profile needs at least 2 price levels, got %d", len(nodes))
+	}
+	sorted := make([]VolumeNode, len(nodes))
+	copy(sorted, nodes)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Price < sorted[j].Price })
+
+	width := sorted[1].Price - sorted[0].Price
+	for i := 2; i < len(sorted); i++ {
+		width = math.Min(width, sorted[i].Price-sorted[i-1].Price)
+	}
+
+	var total, weighted float64
+	top := 0
+	for i, n := range sorted {
+		total += n.Volume
+		weighted += (n.Price + width/2) * n.Volume
+		if n.Volume > sorted[top].Volume {
+			top = i
+		}
+	}
+	if total <= 0 {
+		return 0, 0, 0, 0, fmt.Errorf("volume profile has no volume")
+	}
+
+	lo, hi := top, top
+	inArea := sorted[top].Volume
+	for inArea < total*fraction && (lo > 0 || hi < len(sorted)-1) {
+		below, above := -1.0, -1.0
+		if lo > 0 {
+			below = sorted[lo-1].Volume
+		}
+		if hi < len(sorted)-1 {
+			above = sorted[hi+1].Volume
+		}
+		if above >= below {
+			hi++
+			inArea += above
+		} else {
+			lo--
+			inArea += below
+		}
+	}
+
+	return sorted[top].Price + width/2, sorted[lo].Price, sorted[hi].Price + width, weighted / total, nil
+}
+
+func (d *DuneClient) buildVolumeProfileQuery() string {
+	return fmt.Sprintf(`
+		SELECT
+			FLOOR(price / %[1]d) * %[1]d as price_level,
+			SUM(amount_usd) as volume
+		FROM (
+			SELECT
+				amount_usd,
+				CASE WHEN token_bought_symbol = 'WETH'
+					THEN token_sold_amount / token_bought_amount
+					ELSE token_bought_amount / token_sold_amount
+				END as price
+			FROM dex.trades
+			WHERE blockchain = 'ethereum'
+				AND block_time > now() - interval '%[2]d' day
+				AND ((token_bought_symbol = 'WETH' AND token_sold_symbol = 'USDC')
+					OR (token_bought_symbol = 'USDC' AND token_sold_symbol = 'WETH'))
+				AND token_bought_amount > 0
+				AND token_sold_amount > 0
+				AND amount_usd > 0
+		) t
+		GROUP BY 1
+		ORDER BY 1
+	`, volumeProfileBucketUSD, d.lookbackDays)
+}
@@ -0,0 +1,107 @@
+package levels
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kjannette/trahn-backend/internal/external"
+	"github.com/kjannette/trahn-backend/internal/models"
+)
+
+// fractalWing is how many hourly candles on each side a swing must exceed,
+// so a swing high is the highest high of a 13-hour window.
+const fractalWing = 6
+
+var _ external.SRProvider = (*Fractal)(nil)
+
+// Fractal finds swing highs and lows (fractals) in hourly candles over the
+// lookback window. Support is the highest swing low below the latest close,
+// resistance the lowest swing high above it: the nearest levels where price
+// last turned.
+type Fractal struct {
+	candles      CandleSource
+	lookbackDays int
+}
+
+func NewFractal(candles CandleSource, lookbackDays int) *Fractal {
+	if lookbackDays <= 0 {
+		lookbackDays = 14
+	}
+	return &Fractal{candles: candles, lookbackDays: lookbackDays}
+}
+
+func (f *Fractal) FetchSupportResistance(ctx context.Context, _ bool) (*external.SRResult, error) {
+	if _, err := f.candles.Sync(ctx, "1h"); err != nil {
+		return nil, fmt.Errorf("sync candles: %w", err)
+	}
+	now := time.Now()
+	candles, err := f.candles.GetRange(ctx, "1h", now.AddDate(0, 0, -f.lookbackDays), now)
+	if err != nil {
+		return nil, fmt.Errorf("load candles: %w", err)
+	}
+	return f.fromCandles(candles, now)
+}
+
+func (f *Fractal) fromCandles(candles []models.Candle, now time.Time) (*external.SRResult, error) {
+	if len(candles) < 2*fractalWing+1 {
+		return nil, fmt.Errorf("not enough price history for fractals: %d hour(s), need %d", len(candles), 2*fractalWing+1)
+	}
+	highs, lows := swings(candles, fractalWing)
+	price := candles[len(candles)-1].Close
+
+	support, resistance := 0.0, 0.0
+	for _, l := range lows {
+		if l < price && l > support {
+			support = l
+		}
+	}
+	for _, h := range highs {
+		if h > price && (resistance == 0 || h < resistance) {
+			resistance = h
+		}
+	}
+	if support == 0 {
+		return nil, fmt.Errorf("no swing low below $%.2f in the last %d days", price, f.lookbackDays)
+	}
+	if resistance == 0 {
+		return nil, fmt.Errorf("no swing high above $%.2f in the last %d days", price, f.lookbackDays)
+	}
+
+	return &external.SRResult{
+		Support:      support,
+		Resistance:   resistance,
+		Midpoint:     (support + resistance) / 2,
+		AvgPrice:     meanClose(candles),
+		Method:       "local-fractal",
+		LookbackDays: f.lookbackDays,
+		FetchedAt:    now,
+	}, nil
+}
+
+// swings returns the highs of candles whose high is above every high within
+// wing candles on either side, and the lows of those whose low is below
+// every such low.
+func swings(candles []models.Candle, wing int) (highs, lows []float64) {
+	for i := wing; i < len(candles)-wing; i++ {
+		isHigh, isLow := true, true
+		for j := i - wing; j <= i+wing; j++ {
+			if j == i {
+				continue
+			}
+			if candles[j].High >= candles[i].High {
+				isHigh = false
+			}
+			if candles[j].Low <= candles[i].Low {
+				isLow = false
+			}
+		}
+		if isHigh {
+			highs = append(highs, candles[i].High)
+		}
+		if isLow {
+			lows = append(lows, candles[i].Low)
+		}
+	}
+	return highs, lows
+}
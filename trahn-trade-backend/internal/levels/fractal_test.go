@@ -0,0 +1,64 @@
+package levels
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kjannette/trahn-backend/internal/models"
+)
+
+// hourly builds candles with the given highs; lows sit 20 below each high.
+func hourly(highs ...float64) []models.Candle {
+	out := make([]models.Candle, len(highs))
+	for i, h := range highs {
+		out[i] = models.Candle{Resolution: "1h", High: h, Low: h - 20, Close: h - 10}
+	}
+	return out
+}
+
+func TestSwings(t *testing.T) {
+	c := hourly(10, 20, 30, 20, 10, 0, 10, 20)
+	highs, lows := swings(c, 2)
+	if len(highs) != 1 || highs[0] != 30 {
+		t.Errorf("highs = %v, want [30]", highs)
+	}
+	if len(lows) != 1 || lows[0] != -20 {
+		t.Errorf("lows = %v, want [-20]", lows)
+	}
+}
+
+func TestFractal_NearestLevels(t *testing.T) {
+	// Swing highs at 2200 and 2150, swing lows at 1980 and 2030 (highs 2000,
+	// 2050), last close 2090.
+	var highs []float64
+	for _, peak := range []float64{2200, 2000, 2150, 2050} {
+		for i := 0; i < fractalWing; i++ {
+			highs = append(highs, 2100)
+		}
+		highs = append(highs, peak)
+	}
+	for i := 0; i < fractalWing; i++ {
+		highs = append(highs, 2100)
+	}
+
+	sr, err := NewFractal(&fakeCandles{}, 14).fromCandles(hourly(highs...), time.Now())
+	if err != nil {
+		t.Fatalf("fromCandles: %v", err)
+	}
+	if sr.Support != 2030 || sr.Resistance != 2150 {
+		t.Errorf("levels = %.2f-%.2f, want 2030-2150", sr.Support, sr.Resistance)
+	}
+	if sr.Midpoint != 2090 || sr.Method != "local-fractal" {
+		t.Errorf("unexpected result: %+v", sr)
+	}
+}
+
+func TestFractal_NoSwingAbove(t *testing.T) {
+	rising := make([]float64, 30)
+	for i := range rising {
+		rising[i] = 2000 + float64(i)*10
+	}
+	if _, err := NewFractal(&fakeCandles{}, 14).fromCandles(hourly(rising...), time.Now()); err == nil {
+		t.Fatal("expected error when price has no swing high above it")
+	}
+}
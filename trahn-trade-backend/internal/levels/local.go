@@ -0,0 +1,111 @@
+// Package levels computes support/resistance locally from the bot's own
+// price history, as an alternative to the Dune queries.
+package levels
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kjannette/trahn-backend/internal/external"
+	"github.com/kjannette/trahn-backend/internal/models"
+	"github.com/kjannette/trahn-backend/internal/repository"
+	"github.com/kjannette/trahn-backend/internal/strategy"
+)
+
+// minCandles is the least history (one day of minutes) Local will derive
+// levels from; less than that is closer to noise than to a range.
+const minCandles = 24 * 60
+
+var _ external.SRProvider = (*Local)(nil)
+
+// CandleSource is the part of repository.CandleRepo Local reads.
+type CandleSource interface {
+	Sync(ctx context.Context, resolution string) (int64, error)
+	GetRange(ctx context.Context, resolution string, from, to time.Time) ([]models.Candle, error)
+}
+
+var _ CandleSource = (*repository.CandleRepo)(nil)
+
+// Local runs the same simple and percentile methods as the Dune S/R queries
+// over 1-minute candles from price_history. Dune's prices.usd holds one
+// price per minute, so minute closes are the local equivalent. Results are
+// recorded with method "local-simple" or "local-percentile".
+type Local struct {
+	candles      CandleSource
+	method       string
+	lookbackDays int
+}
+
+// New returns the local provider for an SR_METHOD: simple, percentile,
+// pivot, pivot-fib or fractal. volume-profile needs trade volume, which only
+// the Dune provider has.
+func New(candles CandleSource, method string, lookbackDays int) (external.SRProvider, error) {
+	switch method {
+	case "simple", "percentile":
+		return NewLocal(candles, method, lookbackDays), nil
+	case "pivot":
+		return NewPivot(candles, false, lookbackDays), nil
+	case "pivot-fib":
+		return NewPivot(candles, true, lookbackDays), nil
+	case "fractal":
+		return NewFractal(candles, lookbackDays), nil
+	default:
+		return nil, fmt.Errorf("S/R method %q is not available locally", method)
+	}
+}
+
+func NewLocal(candles CandleSource, method string, lookbackDays int) *Local {
+	if method == "" {
+		method = "simple"
+	}
+	if lookbackDays <= 0 {
+		lookbackDays = 14
+	}
+	return &Local{candles: candles, method: method, lookbackDays: lookbackDays}
+}
+
+// FetchSupportResistance computes levels over the lookback window. It reads
+// the database each call, so forceRefresh has no effect.
+func (l *Local) FetchSupportResistance(ctx context.Context, _ bool) (*external.SRResult, error) {
+	if _, err := l.candles.Sync(ctx, "1m"); err != nil {
+		return nil, fmt.Errorf("sync candles: %w", err)
+	}
+	now := time.Now()
+	candles, err := l.candles.GetRange(ctx, "1m", now.AddDate(0, 0, -l.lookbackDays), now)
+	if err != nil {
+		return nil, fmt.Errorf("load candles: %w", err)
+	}
+	return l.fromCandles(candles, now)
+}
+
+func (l *Local) fromCandles(candles []models.Candle, now time.Time) (*external.SRResult, error) {
+	if len(candles) < minCandles {
+		return nil, fmt.Errorf("not enough price history for local S/R: %d minute(s), need %d", len(candles), minCandles)
+	}
+	closes := make([]float64, len(candles))
+	for i, c := range candles {
+		closes[i] = c.Close
+	}
+	sr, err := strategy.CalculateSR(closes, l.method)
+	if err != nil {
+		return nil, err
+	}
+	return &external.SRResult{
+		Support:      sr.Support,
+		Resistance:   sr.Resistance,
+		Midpoint:     sr.Midpoint,
+		AvgPrice:     meanClose(candles),
+		Method:       "local-" + sr.Method,
+		LookbackDays: l.lookbackDays,
+		FetchedAt:    now,
+	}, nil
+}
+
+func meanClose(candles []models.Candle) float64 {
+	var sum float64
+	for _, c := range candles {
+		sum += c.Close
+	}
+	return sum / float64(len(candles))
+}
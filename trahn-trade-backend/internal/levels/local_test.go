@@ -0,0 +1,76 @@
+package levels
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/kjannette/trahn-backend/internal/models"
+)
+
+type fakeCandles struct {
+	candles []models.Candle
+	synced  []string
+}
+
+func (f *fakeCandles) Sync(_ context.Context, resolution string) (int64, error) {
+	f.synced = append(f.synced, resolution)
+	return 0, nil
+}
+
+func (f *fakeCandles) GetRange(context.Context, string, time.Time, time.Time) ([]models.Candle, error) {
+	return f.candles, nil
+}
+
+// minuteCloses returns n minute candles whose closes cycle through 2000..2099.
+func minuteCloses(n int) []models.Candle {
+	start := time.Now().Add(-time.Duration(n) * time.Minute)
+	out := make([]models.Candle, n)
+	for i := range out {
+		p := 2000 + float64(i%100)
+		out[i] = models.Candle{Resolution: "1m", Bucket: start.Add(time.Duration(i) * time.Minute), Open: p, High: p, Low: p, Close: p, Ticks: 2}
+	}
+	return out
+}
+
+func TestLocal_Simple(t *testing.T) {
+	src := &fakeCandles{candles: minuteCloses(1500)}
+	sr, err := NewLocal(src, "simple", 7).FetchSupportResistance(context.Background(), false)
+	if err != nil {
+		t.Fatalf("FetchSupportResistance: %v", err)
+	}
+	if sr.Support != 2000 || sr.Resistance != 2099 || sr.Midpoint != 2049.5 {
+		t.Errorf("unexpected levels: %+v", sr)
+	}
+	if math.Abs(sr.AvgPrice-2049.5) > 0.01 {
+		t.Errorf("AvgPrice = %.2f, want 2049.5", sr.AvgPrice)
+	}
+	if sr.Method != "local-simple" || sr.LookbackDays != 7 {
+		t.Errorf("unexpected method/lookback: %s/%d", sr.Method, sr.LookbackDays)
+	}
+	if len(src.synced) != 1 || src.synced[0] != "1m" {
+		t.Errorf("expected 1m candles synced, got %v", src.synced)
+	}
+}
+
+func TestLocal_Percentile(t *testing.T) {
+	src := &fakeCandles{candles: minuteCloses(1500)}
+	sr, err := NewLocal(src, "percentile", 14).FetchSupportResistance(context.Background(), false)
+	if err != nil {
+		t.Fatalf("FetchSupportResistance: %v", err)
+	}
+	if sr.Support <= 2000 || sr.Resistance >= 2099 || sr.Support >= sr.Resistance {
+		t.Errorf("expected percentile levels inside the range, got %+v", sr)
+	}
+	if sr.Method != "local-percentile" {
+		t.Errorf("Method = %q", sr.Method)
+	}
+}
+
+func TestLocal_NotEnoughHistory(t *testing.T) {
+	src := &fakeCandles{candles: minuteCloses(60)}
+	if _, err := NewLocal(src, "simple", 14).FetchSupportResistance(context.Background(), false); err == nil {
+		t.Fatal("expected error with an hour of history")
+	}
+}
@@ -0,0 +1,78 @@
+package levels
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kjannette/trahn-backend/internal/external"
+	"github.com/kjannette/trahn-backend/internal/models"
+)
+
+// fibRatio places Fibonacci pivot S1/R1 at 38.2% of the period's range.
+const fibRatio = 0.382
+
+var _ external.SRProvider = (*Pivot)(nil)
+
+// Pivot computes pivot points over the lookback period, treating it as one
+// bar: high and low over the period's complete UTC days, close of the last
+// one. The pivot P = (H+L+C)/3 is the midpoint. Classic pivots put support
+// and resistance at S1 = 2P-H and R1 = 2P-L; Fibonacci pivots at P -/+
+// 0.382 of the range.
+type Pivot struct {
+	candles      CandleSource
+	fib          bool
+	lookbackDays int
+}
+
+func NewPivot(candles CandleSource, fib bool, lookbackDays int) *Pivot {
+	if lookbackDays <= 0 {
+		lookbackDays = 14
+	}
+	return &Pivot{candles: candles, fib: fib, lookbackDays: lookbackDays}
+}
+
+func (p *Pivot) FetchSupportResistance(ctx context.Context, _ bool) (*external.SRResult, error) {
+	if _, err := p.candles.Sync(ctx, "1d"); err != nil {
+		return nil, fmt.Errorf("sync candles: %w", err)
+	}
+	now := time.Now()
+	today := now.UTC().Truncate(24 * time.Hour) // today's candle is still open
+	candles, err := p.candles.GetRange(ctx, "1d", today.AddDate(0, 0, -p.lookbackDays), today)
+	if err != nil {
+		return nil, fmt.Errorf("load candles: %w", err)
+	}
+	return p.fromCandles(candles, now)
+}
+
+func (p *Pivot) fromCandles(candles []models.Candle, now time.Time) (*external.SRResult, error) {
+	if len(candles) == 0 {
+		return nil, fmt.Errorf("not enough price history for pivot points: no complete day")
+	}
+	high, low := candles[0].High, candles[0].Low
+	for _, c := range candles[1:] {
+		high = max(high, c.High)
+		low = min(low, c.Low)
+	}
+	if high <= low {
+		return nil, fmt.Errorf("invalid pivot range: high %.2f <= low %.2f", high, low)
+	}
+	pivot := (high + low + candles[len(candles)-1].Close) / 3
+
+	sr := &external.SRResult{
+		Midpoint:     pivot,
+		AvgPrice:     meanClose(candles),
+		LookbackDays: p.lookbackDays,
+		FetchedAt:    now,
+	}
+	if p.fib {
+		sr.Support = pivot - fibRatio*(high-low)
+		sr.Resistance = pivot + fibRatio*(high-low)
+		sr.Method = "local-pivot-fib"
+	} else {
+		sr.Support = 2*pivot - high
+		sr.Resistance = 2*pivot - low
+		sr.Method = "local-pivot"
+	}
+	return sr, nil
+}
@@ -0,0 +1,50 @@
+package levels
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/kjannette/trahn-backend/internal/models"
+)
+
+func dailyCandles() []models.Candle {
+	return []models.Candle{
+		{Resolution: "1d", High: 2100, Low: 1950, Close: 2050},
+		{Resolution: "1d", High: 2200, Low: 2000, Close: 2150},
+		{Resolution: "1d", High: 2180, Low: 1900, Close: 2050},
+	}
+}
+
+func TestPivot_Classic(t *testing.T) {
+	sr, err := NewPivot(&fakeCandles{}, false, 3).fromCandles(dailyCandles(), time.Now())
+	if err != nil {
+		t.Fatalf("fromCandles: %v", err)
+	}
+	// H 2200, L 1900, C 2050: P = 2050, S1 = 1900, R1 = 2200.
+	if sr.Midpoint != 2050 || sr.Support != 1900 || sr.Resistance != 2200 {
+		t.Errorf("unexpected pivots: %+v", sr)
+	}
+	if sr.Method != "local-pivot" {
+		t.Errorf("Method = %q", sr.Method)
+	}
+}
+
+func TestPivot_Fibonacci(t *testing.T) {
+	sr, err := NewPivot(&fakeCandles{}, true, 3).fromCandles(dailyCandles(), time.Now())
+	if err != nil {
+		t.Fatalf("fromCandles: %v", err)
+	}
+	if math.Abs(sr.Support-(2050-0.382*300)) > 1e-9 || math.Abs(sr.Resistance-(2050+0.382*300)) > 1e-9 {
+		t.Errorf("unexpected fib pivots: %+v", sr)
+	}
+	if sr.Method != "local-pivot-fib" {
+		t.Errorf("Method = %q", sr.Method)
+	}
+}
+
+func TestPivot_NoHistory(t *testing.T) {
+	if _, err := NewPivot(&fakeCandles{}, false, 3).fromCandles(nil, time.Now()); err == nil {
+		t.Fatal("expected error without a complete day")
+	}
+}
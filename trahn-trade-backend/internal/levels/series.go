@@ -0,0 +1,64 @@
+package levels
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kjannette/trahn-backend/internal/external"
+	"github.com/kjannette/trahn-backend/internal/models"
+)
+
+// Candles aggregates prices, oldest first, into candles of width aligned to
+// the Unix epoch, as CandleRepo.Sync does for price_history.
+func Candles(prices []models.PricePoint, resolution string, width time.Duration) []models.Candle {
+	var out []models.Candle
+	for _, p := range prices {
+		bucket := p.Timestamp.UTC().Truncate(width)
+		if n := len(out); n > 0 && out[n-1].Bucket.Equal(bucket) {
+			c := &out[n-1]
+			c.High = max(c.High, p.Price)
+			c.Low = min(c.Low, p.Price)
+			c.Close = p.Price
+			c.Ticks++
+			continue
+		}
+		out = append(out, models.Candle{
+			Resolution: resolution,
+			Bucket:     bucket,
+			Open:       p.Price,
+			High:       p.Price,
+			Low:        p.Price,
+			Close:      p.Price,
+			Ticks:      1,
+		})
+	}
+	return out
+}
+
+// FromPrices computes pivot, pivot-fib or fractal levels as of now over
+// prices, oldest first, using candles built in memory rather than read from
+// the database. The backtester uses it to center a grid on its warm-up
+// window the way the live provider would.
+func FromPrices(prices []models.PricePoint, method string, now time.Time) (*external.SRResult, error) {
+	if len(prices) == 0 {
+		return nil, fmt.Errorf("no prices to derive S/R from")
+	}
+	days := int(now.Sub(prices[0].Timestamp).Hours()/24 + 0.5)
+
+	switch method {
+	case "pivot", "pivot-fib":
+		// Only complete days count, as for the live provider: not the
+		// current one, nor one the series starts partway through.
+		var daily []models.Candle
+		for _, c := range Candles(prices, "1d", 24*time.Hour) {
+			if !c.Bucket.Before(prices[0].Timestamp) && !c.Bucket.Add(24*time.Hour).After(now) {
+				daily = append(daily, c)
+			}
+		}
+		return NewPivot(nil, method == "pivot-fib", days).fromCandles(daily, now)
+	case "fractal":
+		return NewFractal(nil, days).fromCandles(Candles(prices, "1h", time.Hour), now)
+	default:
+		return nil, fmt.Errorf("S/R method %q is not available over a price series", method)
+	}
+}
@@ -0,0 +1,56 @@
+package levels
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kjannette/trahn-backend/internal/models"
+)
+
+func TestCandles(t *testing.T) {
+	start := time.Date(2024, 1, 15, 9, 59, 0, 0, time.UTC)
+	var prices []models.PricePoint
+	for i, p := range []float64{2000, 2010, 1990, 2030, 2020} {
+		prices = append(prices, models.PricePoint{Timestamp: start.Add(time.Duration(i) * 30 * time.Second), Price: p})
+	}
+
+	c := Candles(prices, "1m", time.Minute)
+	if len(c) != 3 {
+		t.Fatalf("expected 3 candles, got %d", len(c))
+	}
+	if !c[1].Bucket.Equal(start.Add(time.Minute)) {
+		t.Errorf("second bucket = %s", c[1].Bucket)
+	}
+	if c[1].Open != 1990 || c[1].High != 2030 || c[1].Low != 1990 || c[1].Close != 2030 || c[1].Ticks != 2 {
+		t.Errorf("unexpected candle: %+v", c[1])
+	}
+}
+
+func TestFromPrices_Pivot(t *testing.T) {
+	// Two complete days ranging 1900-2200 and closing at 2050, between the
+	// end of a day and the start of another that must both be ignored.
+	day := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	var prices []models.PricePoint
+	add := func(at time.Time, p float64) {
+		prices = append(prices, models.PricePoint{Timestamp: at, Price: p})
+	}
+	add(day.Add(-time.Hour), 1000)
+	add(day.Add(time.Hour), 2000)
+	add(day.Add(2*time.Hour), 2200)
+	add(day.Add(25*time.Hour), 1900)
+	add(day.Add(47*time.Hour), 2050)
+	add(day.Add(49*time.Hour), 3000)
+
+	sr, err := FromPrices(prices, "pivot", day.Add(50*time.Hour))
+	if err != nil {
+		t.Fatalf("FromPrices: %v", err)
+	}
+	// H 2200, L 1900, C 2050: P = 2050, S1 = 1900, R1 = 2200.
+	if sr.Midpoint != 2050 || sr.Support != 1900 || sr.Resistance != 2200 {
+		t.Errorf("unexpected pivots: %+v", sr)
+	}
+
+	if _, err := FromPrices(prices, "volume-profile", day.Add(50*time.Hour)); err == nil {
+		t.Error("expected error for a method without a series implementation")
+	}
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type BacktestSweep struct {
+	ID        int64           `json:"id"`
+	RangeFrom time.Time       `json:"rangeFrom"`
+	RangeTo   time.Time       `json:"rangeTo"`
+	RankBy    string          `json:"rankBy"`
+	SpecJSON  json.RawMessage `json:"spec"`
+	RunCount  int             `json:"runCount"`
+	CreatedAt time.Time       `json:"createdAt"`
+}
+
+type BacktestRun struct {
+	ID                 int64     `json:"id"`
+	SweepID            int64     `json:"sweepId"`
+	Rank               int       `json:"rank"`
+	GridLevels         int       `json:"gridLevels"`
+	GridSpacingPercent float64   `json:"gridSpacingPercent"`
+	AmountPerGrid      float64   `json:"amountPerGrid"`
+	CooldownSeconds    int       `json:"cooldownSeconds"`
+	SRMethod           string    `json:"srMethod"`
+	NetPnL             float64   `json:"netPnl"`
+	NetPnLPercent      float64   `json:"netPnlPercent"`
+	MaxDrawdownPercent float64   `json:"maxDrawdownPercent"`
+	TradeCount         int       `json:"tradeCount"`
+	GasSpentETH        float64   `json:"gasSpentEth"`
+	Error              *string   `json:"error,omitempty"`
+	CreatedAt          time.Time `json:"createdAt"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// Candle is one OHLC bucket of price_history. Ticks, the number of price
+// samples in the bucket, stands in for volume.
+type Candle struct {
+	Resolution string    `json:"resolution"`
+	Bucket     time.Time `json:"bucket"`
+	Open       float64   `json:"open"`
+	High       float64   `json:"high"`
+	Low        float64   `json:"low"`
+	Close      float64   `json:"close"`
+	Ticks      int       `json:"ticks"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+// Lot is the ETH acquired by one buy trade, carried at its cost basis
+// until sells consume it.
+type Lot struct {
+	ID                int64     `json:"id"`
+	TradeID           int64     `json:"tradeId"`
+	AcquiredAt        time.Time `json:"acquiredAt"`
+	Quantity          float64   `json:"quantity"`
+	RemainingQuantity float64   `json:"remainingQuantity"`
+	CostPerETH        float64   `json:"costPerEth"` // USD, including slippage and gas
+	IsPaperTrade      bool      `json:"isPaperTrade"`
+	CreatedAt         time.Time `json:"createdAt"`
+
+	// RealizedGainUSD is the sum of gains on this lot's disposals (read-only).
+	RealizedGainUSD float64 `json:"realizedGainUsd"`
+}
+
+// LotDisposal is the part of a sell matched against one lot. LotID is nil
+// for ETH sold with no open lot to cover it (e.g. starting inventory), in
+// which case the cost basis and gain are unknown.
+type LotDisposal struct {
+	ID           int64      `json:"id"`
+	LotID        *int64     `json:"lotId,omitempty"`
+	SellTradeID  int64      `json:"sellTradeId"`
+	AcquiredAt   *time.Time `json:"acquiredAt,omitempty"`
+	DisposedAt   time.Time  `json:"disposedAt"`
+	Quantity     float64    `json:"quantity"`
+	ProceedsUSD  float64    `json:"proceedsUsd"`
+	CostBasisUSD *float64   `json:"costBasisUsd,omitempty"`
+	GainUSD      *float64   `json:"gainUsd,omitempty"`
+	Method       string     `json:"method"`
+	IsPaperTrade bool       `json:"isPaperTrade"`
+	CreatedAt    time.Time  `json:"createdAt"`
+}
+
+type LotSummary struct {
+	OpenLots          int64    `json:"openLots"`
+	OpenQuantity      float64  `json:"openQuantity"`
+	OpenCostBasisUSD  float64  `json:"openCostBasisUsd"`
+	AvgCostPerETH     *float64 `json:"avgCostPerEth"`
+	RealizedGainUSD   float64  `json:"realizedGainUsd"`
+	UnmatchedQuantity float64  `json:"unmatchedQuantity"`
+}
+
+// CapitalGain is a lot disposal with the gas fees attributed to it, for tax
+// reporting. Fees are already included in CostBasisUSD (buy gas) and
+// ProceedsUSD (sell gas); they are broken out here for display only.
+type CapitalGain struct {
+	LotDisposal
+	BuyFeeUSD  float64 `json:"buyFeeUsd"`
+	SellFeeUSD float64 `json:"sellFeeUsd"`
+	GasETH     float64 `json:"gasEth"`
+}
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// PendingTx is a transaction the nonce manager broadcast, tracked until a
+// transaction at its nonce is mined. OrigHash is the first broadcast, which
+// callers wait on; TxHash is the latest replacement.
+type PendingTx struct {
+	ID            int64     `json:"id"`
+	WalletAddress string    `json:"walletAddress"`
+	Nonce         uint64    `json:"nonce"`
+	OrigHash      string    `json:"origHash"`
+	TxHash        string    `json:"txHash"`
+	Hashes        []string  `json:"hashes"`
+	ToAddress     string    `json:"toAddress"`
+	ValueWei      string    `json:"valueWei"`
+	Data          []byte    `json:"-"`
+	GasLimit      uint64    `json:"gasLimit"`
+	GasPriceWei   *string   `json:"gasPriceWei,omitempty"`
+	TipCapWei     *string   `json:"tipCapWei,omitempty"`
+	FeeCapWei     *string   `json:"feeCapWei,omitempty"`
+	SentBlock     uint64    `json:"sentBlock"`
+	Replacements  int       `json:"replacements"`
+	CancelHash    *string   `json:"cancelHash,omitempty"`
+	MinedHash     *string   `json:"minedHash,omitempty"`
+	Status        string    `json:"status"` // pending | mined | cancelled | dropped
+	CreatedAt     time.Time `json:"createdAt"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+type PortfolioSnapshot struct {
+	ID            int64     `json:"id"`
+	Timestamp     time.Time `json:"timestamp"`
+	WalletAddress string    `json:"walletAddress"`
+	ETHBalance    float64   `json:"ethBalance"`
+	USDCBalance   float64   `json:"usdcBalance"`
+	ETHPrice      *float64  `json:"ethPrice,omitempty"`
+	ValueUSD      *float64  `json:"valueUsd,omitempty"`
+	IsBaseline    bool      `json:"isBaseline"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
@@ -16,6 +16,7 @@ type Trade struct {
 	SlippagePercent *float64  `json:"slippagePercent,omitempty"`
 	GasCostETH      *float64  `json:"gasCostEth,omitempty"`
 	CreatedAt       time.Time `json:"createdAt"`
+	RealizedPnL     *float64  `json:"realizedPnl,omitempty"` // sells closing a grid buy
 }
 
 type TradeStats struct {
@@ -24,6 +25,7 @@ type TradeStats struct {
 	SellCount   int64      `json:"sellCount"`
 	TotalVolume *float64   `json:"totalVolume"`
 	AvgPrice    *float64   `json:"avgPrice"`
+	RealizedPnL *float64   `json:"realizedPnl"`
 	FirstTrade  *time.Time `json:"firstTrade"`
 	LastTrade   *time.Time `json:"lastTrade"`
 }
@@ -0,0 +1,152 @@
+// Package oracle combines independent ETH/USD price sources into one price
+// the bot can trade on.
+package oracle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrTooFewSources is returned when fewer sources than required produced a
+// price that agrees with the others.
+var ErrTooFewSources = errors.New("too few agreeing price sources")
+
+// PriceSource is one independent reading of the ETH price in USD. Price
+// returns the price and when it was observed at the source.
+type PriceSource interface {
+	Name() string
+	Price(ctx context.Context) (float64, time.Time, error)
+}
+
+// Reading is one source's answer. Err is set when the source failed.
+type Reading struct {
+	Source string
+	Price  float64
+	Time   time.Time
+	Err    error
+}
+
+// Result is an aggregated price and how it was arrived at. Time is the
+// newest observation among the contributing sources.
+type Result struct {
+	Price    float64
+	Time     time.Time
+	Used     []Reading // contributed to the median
+	Rejected []Reading // answered, but too far from the other sources
+	Failed   []Reading // errored
+}
+
+// Source lists the contributing sources for price_history.source, e.g.
+// "coingecko,chainlink,uniswap".
+func (r *Result) Source() string {
+	names := make([]string, len(r.Used))
+	for i, u := range r.Used {
+		names[i] = u.Source
+	}
+	return strings.Join(names, ",")
+}
+
+// Aggregator queries every source concurrently and takes the median of
+// the answers that agree with each other.
+type Aggregator struct {
+	sources         []PriceSource
+	maxDeviationPct float64
+	minSources      int
+}
+
+// NewAggregator creates an aggregator. Answers more than maxDeviationPct
+// percent from the median of all answers are rejected (0 keeps them all),
+// and at least minSources must remain.
+func NewAggregator(sources []PriceSource, maxDeviationPct float64, minSources int) *Aggregator {
+	if minSources < 1 {
+		minSources = 1
+	}
+	return &Aggregator{sources: sources, maxDeviationPct: maxDeviationPct, minSources: minSources}
+}
+
+// Sources returns the names of the configured sources.
+func (a *Aggregator) Sources() []string {
+	names := make([]string, len(a.sources))
+	for i, s := range a.sources {
+		names[i] = s.Name()
+	}
+	return names
+}
+
+// Price queries every source and returns the aggregated price. The result
+// is returned alongside ErrTooFewSources so callers can report why.
+func (a *Aggregator) Price(ctx context.Context) (*Result, error) {
+	readings := make([]Reading, len(a.sources))
+	var wg sync.WaitGroup
+	for i, src := range a.sources {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p, at, err := src.Price(ctx)
+			if err == nil && (p <= 0 || math.IsNaN(p) || math.IsInf(p, 0)) {
+				err = fmt.Errorf("invalid price %v", p)
+			}
+			readings[i] = Reading{Source: src.Name(), Price: p, Time: at, Err: err}
+		}()
+	}
+	wg.Wait()
+
+	return a.combine(readings)
+}
+
+// combine rejects outliers around the median of all answers and returns
+// the median of the rest.
+func (a *Aggregator) combine(readings []Reading) (*Result, error) {
+	res := &Result{}
+	var answered []Reading
+	for _, r := range readings {
+		if r.Err != nil {
+			res.Failed = append(res.Failed, r)
+		} else {
+			answered = append(answered, r)
+		}
+	}
+	if len(answered) == 0 {
+		return res, fmt.Errorf("%w: all %d sources failed", ErrTooFewSources, len(readings))
+	}
+
+	mid := median(answered)
+	for _, r := range answered {
+		if a.maxDeviationPct > 0 && math.Abs(r.Price-mid)/mid*100 > a.maxDeviationPct {
+			res.Rejected = append(res.Rejected, r)
+		} else {
+			res.Used = append(res.Used, r)
+		}
+	}
+	if len(res.Used) < a.minSources {
+		return res, fmt.Errorf("%w: %d of %d needed (%d rejected, %d failed)",
+			ErrTooFewSources, len(res.Used), a.minSources, len(res.Rejected), len(res.Failed))
+	}
+
+	res.Price = median(res.Used)
+	for _, r := range res.Used {
+		if r.Time.After(res.Time) {
+			res.Time = r.Time
+		}
+	}
+	return res, nil
+}
+
+func median(rs []Reading) float64 {
+	prices := make([]float64, len(rs))
+	for i, r := range rs {
+		prices[i] = r.Price
+	}
+	sort.Float64s(prices)
+	n := len(prices)
+	if n%2 == 1 {
+		return prices[n/2]
+	}
+	return (prices[n/2-1] + prices[n/2]) / 2
+}
@@ -0,0 +1,92 @@
+package oracle
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+type fakeSource struct {
+	name  string
+	price float64
+	at    time.Time
+	err   error
+}
+
+func (f *fakeSource) Name() string { return f.name }
+func (f *fakeSource) Price(context.Context) (float64, time.Time, error) {
+	return f.price, f.at, f.err
+}
+
+func TestAggregatorMedian(t *testing.T) {
+	newest := time.Now()
+	agg := NewAggregator([]PriceSource{
+		&fakeSource{name: "coingecko", price: 2000, at: newest.Add(-time.Minute)},
+		&fakeSource{name: "chainlink", price: 2004, at: newest},
+		&fakeSource{name: "uniswap", price: 2010, at: newest.Add(-time.Second)},
+	}, 1, 2)
+
+	res, err := agg.Price(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Price != 2004 {
+		t.Fatalf("expected median 2004, got %.2f", res.Price)
+	}
+	if !res.Time.Equal(newest) {
+		t.Fatalf("expected newest observation %v, got %v", newest, res.Time)
+	}
+	if got := res.Source(); got != "coingecko,chainlink,uniswap" {
+		t.Fatalf("unexpected source %q", got)
+	}
+}
+
+func TestAggregatorRejectsOutlier(t *testing.T) {
+	agg := NewAggregator([]PriceSource{
+		&fakeSource{name: "coingecko", price: 2000},
+		&fakeSource{name: "chainlink", price: 2002},
+		&fakeSource{name: "uniswap", price: 2300}, // manipulated pool
+	}, 1, 2)
+
+	res, err := agg.Price(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(res.Price-2001) > 1e-9 {
+		t.Fatalf("expected 2001 without the outlier, got %.2f", res.Price)
+	}
+	if len(res.Rejected) != 1 || res.Rejected[0].Source != "uniswap" {
+		t.Fatalf("expected uniswap rejected, got %+v", res.Rejected)
+	}
+	if got := res.Source(); got != "coingecko,chainlink" {
+		t.Fatalf("unexpected source %q", got)
+	}
+}
+
+func TestAggregatorTooFewSources(t *testing.T) {
+	agg := NewAggregator([]PriceSource{
+		&fakeSource{name: "coingecko", price: 2000},
+		&fakeSource{name: "chainlink", err: errors.New("rpc down")},
+		&fakeSource{name: "uniswap", price: -1},
+	}, 1, 2)
+
+	res, err := agg.Price(context.Background())
+	if !errors.Is(err, ErrTooFewSources) {
+		t.Fatalf("expected ErrTooFewSources, got %v", err)
+	}
+	if len(res.Failed) != 2 {
+		t.Fatalf("expected 2 failed sources, got %+v", res.Failed)
+	}
+
+	// One working source is enough when only one is required.
+	agg.minSources = 1
+	res, err = agg.Price(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Price != 2000 || res.Source() != "coingecko" {
+		t.Fatalf("unexpected result %.2f from %q", res.Price, res.Source())
+	}
+}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/kjannette/trahn-backend/internal/models"
+)
+
+type BacktestRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewBacktestRepo(pool *pgxpool.Pool) *BacktestRepo {
+	return &BacktestRepo{pool: pool}
+}
+
+// RecordSweep stores a sweep and all of its runs in one transaction.
+func (r *BacktestRepo) RecordSweep(ctx context.Context, sweep *models.BacktestSweep, runs []models.BacktestRun) (*models.BacktestSweep, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	row := tx.QueryRow(ctx,
+		`INSERT INTO backtest_sweeps (range_from, range_to, rank_by, spec_json, run_count)
+		 VALUES ($1,$2,$3,$4,$5)
+		 RETURNING *`,
+		sweep.RangeFrom, sweep.RangeTo, sweep.RankBy, sweep.SpecJSON, len(runs),
+	)
+	saved, err := scanSweep(row)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, run := range runs {
+		_, err := tx.Exec(ctx,
+			`INSERT INTO backtest_runs
+			 (sweep_id, rank, grid_levels, grid_spacing_percent, amount_per_grid,
+			  cooldown_seconds, sr_method, net_pnl, net_pnl_percent,
+			  max_drawdown_percent, trade_count, gas_spent_eth, error)
+			 VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13)`,
+			saved.ID, run.Rank, run.GridLevels, run.GridSpacingPercent, run.AmountPerGrid,
+			run.CooldownSeconds, run.SRMethod, run.NetPnL, run.NetPnLPercent,
+			run.MaxDrawdownPercent, run.TradeCount, run.GasSpentETH, run.Error,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return saved, nil
+}
+
+func (r *BacktestRepo) GetSweeps(ctx context.Context, limit int) ([]models.BacktestSweep, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT * FROM backtest_sweeps ORDER BY created_at DESC LIMIT $1`,
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []models.BacktestSweep
+	for rows.Next() {
+		s, err := scanSweep(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *s)
+	}
+	return out, rows.Err()
+}
+
+// GetRuns returns a sweep's runs, best-ranked first.
+func (r *BacktestRepo) GetRuns(ctx context.Context, sweepID int64) ([]models.BacktestRun, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT * FROM backtest_runs WHERE sweep_id = $1 ORDER BY rank ASC`,
+		sweepID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []models.BacktestRun
+	for rows.Next() {
+		var run models.BacktestRun
+		if err := rows.Scan(
+			&run.ID, &run.SweepID, &run.Rank, &run.GridLevels, &run.GridSpacingPercent,
+			&run.AmountPerGrid, &run.CooldownSeconds, &run.SRMethod, &run.NetPnL,
+			&run.NetPnLPercent, &run.MaxDrawdownPercent, &run.TradeCount,
+			&run.GasSpentETH, &run.Error, &run.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		out = append(out, run)
+	}
+	return out, rows.Err()
+}
+
+// --- scan helpers ---
+
+func scanSweep(row scannable) (*models.BacktestSweep, error) {
+	var s models.BacktestSweep
+	err := row.Scan(&s.ID, &s.RangeFrom, &s.RangeTo, &s.RankBy, &s.SpecJSON, &s.RunCount, &s.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/kjannette/trahn-backend/internal/models"
+)
+
+// candleResolutions maps each supported resolution to its bucket width.
+var candleResolutions = map[string]time.Duration{
+	"1m": time.Minute,
+	"5m": 5 * time.Minute,
+	"1h": time.Hour,
+	"1d": 24 * time.Hour,
+}
+
+// CandleResolution returns the bucket width for a resolution name
+// (1m, 5m, 1h or 1d).
+func CandleResolution(name string) (time.Duration, bool) {
+	d, ok := candleResolutions[name]
+	return d, ok
+}
+
+type CandleRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewCandleRepo(pool *pgxpool.Pool) *CandleRepo {
+	return &CandleRepo{pool: pool}
+}
+
+// Sync brings the stored candles of a resolution up to date with
+// price_history and returns the number of buckets written. Only the newest
+// stored bucket, the one before it and anything later are rebuilt, so calling
+// it often is cheap and a tick recorded just after its bucket closed is still
+// counted; the first call aggregates the whole history. Buckets are aligned to
+// the Unix epoch, so daily candles run from 00:00 UTC rather than the trading
+// day.
+func (r *CandleRepo) Sync(ctx context.Context, resolution string) (int64, error) {
+	width, ok := candleResolutions[resolution]
+	if !ok {
+		return 0, fmt.Errorf("unknown candle resolution %q", resolution)
+	}
+	tag, err := r.pool.Exec(ctx,
+		`INSERT INTO price_candles (resolution, bucket, open, high, low, close, ticks, updated_at)
+		 SELECT $1, b.bucket,
+		        (array_agg(b.price ORDER BY b.timestamp ASC))[1],
+		        MAX(b.price), MIN(b.price),
+		        (array_agg(b.price ORDER BY b.timestamp DESC))[1],
+		        COUNT(*), NOW()
+		 FROM (
+		     SELECT timestamp, price,
+		            date_bin(make_interval(secs => $2), timestamp, TIMESTAMPTZ '1970-01-01 00:00:00+00') AS bucket
+		     FROM price_history
+		     WHERE timestamp >= COALESCE(
+		         (SELECT MAX(bucket) FROM price_candles WHERE resolution = $1) - make_interval(secs => $2),
+		         '-infinity')
+		 ) b
+		 GROUP BY b.bucket
+		 ON CONFLICT (resolution, bucket) DO UPDATE SET
+		     open = EXCLUDED.open, high = EXCLUDED.high, low = EXCLUDED.low,
+		     close = EXCLUDED.close, ticks = EXCLUDED.ticks, updated_at = EXCLUDED.updated_at`,
+		resolution, width.Seconds(),
+	)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// SyncAll runs Sync for every resolution, returning the first error.
+func (r *CandleRepo) SyncAll(ctx context.Context) error {
+	for resolution := range candleResolutions {
+		if _, err := r.Sync(ctx, resolution); err != nil {
+			return fmt.Errorf("sync %s candles: %w", resolution, err)
+		}
+	}
+	return nil
+}
+
+// GetRange returns candles of a resolution with from <= bucket < to, oldest
+// first.
+func (r *CandleRepo) GetRange(ctx context.Context, resolution string, from, to time.Time) ([]models.Candle, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT * FROM price_candles
+		 WHERE resolution = $1 AND bucket >= $2 AND bucket < $3
+		 ORDER BY bucket ASC`,
+		resolution, from, to,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []models.Candle
+	for rows.Next() {
+		var c models.Candle
+		if err := rows.Scan(&c.Resolution, &c.Bucket, &c.Open, &c.High, &c.Low, &c.Close, &c.Ticks, &c.UpdatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,334 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/kjannette/trahn-backend/internal/accounting"
+	"github.com/kjannette/trahn-backend/internal/models"
+)
+
+type LotRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewLotRepo(pool *pgxpool.Pool) *LotRepo {
+	return &LotRepo{pool: pool}
+}
+
+// Sync applies every trade_history row not yet reflected in the lot tables,
+// in insertion order: buys open lots, sells are matched against open lots
+// of the same mode (paper/live) using method. Disposals keep the method they
+// were matched with, so changing it only affects later sells. Trades with no
+// quantity are skipped and logged, as they would otherwise stop every later
+// sync at the same row. The last trade examined is stored in lot_sync_state
+// with the lots, so each trade is read once. It returns the number of trades
+// applied.
+func (r *LotRepo) Sync(ctx context.Context, method string) (int, error) {
+	if err := accounting.ValidateMethod(method); err != nil {
+		return 0, err
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	// Serialize syncs so no trade is applied twice.
+	if _, err := tx.Exec(ctx, `LOCK TABLE trade_lots IN EXCLUSIVE MODE`); err != nil {
+		return 0, err
+	}
+
+	var mark int64
+	err = tx.QueryRow(ctx,
+		`SELECT GREATEST(
+			(SELECT COALESCE(MAX(last_trade_id), 0) FROM lot_sync_state),
+			(SELECT COALESCE(MAX(trade_id), 0) FROM trade_lots),
+			(SELECT COALESCE(MAX(sell_trade_id), 0) FROM lot_disposals))`,
+	).Scan(&mark)
+	if err != nil {
+		return 0, err
+	}
+
+	rows, err := tx.Query(ctx, `SELECT * FROM trade_history WHERE id > $1 ORDER BY id ASC`, mark)
+	if err != nil {
+		return 0, err
+	}
+	trades, err := collectTrades(rows)
+	rows.Close()
+	if err != nil {
+		return 0, err
+	}
+
+	applied := 0
+	for _, t := range trades {
+		if t.Side == "buy" {
+			err = openLot(ctx, tx, t)
+		} else {
+			err = disposeLots(ctx, tx, t, method)
+		}
+		if errors.Is(err, accounting.ErrNoQuantity) {
+			fmt.Printf("[LOTS] Skipping %s trade %d: %v\n", t.Side, t.ID, err)
+			continue
+		}
+		if err != nil {
+			return 0, fmt.Errorf("trade %d: %w", t.ID, err)
+		}
+		applied++
+	}
+
+	if len(trades) > 0 {
+		_, err = tx.Exec(ctx,
+			`INSERT INTO lot_sync_state (id, last_trade_id, updated_at) VALUES (TRUE, $1, NOW())
+			 ON CONFLICT (id) DO UPDATE SET
+			     last_trade_id = EXCLUDED.last_trade_id, updated_at = EXCLUDED.updated_at`,
+			trades[len(trades)-1].ID,
+		)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return applied, nil
+}
+
+func openLot(ctx context.Context, tx pgx.Tx, t models.Trade) error {
+	qty, cost := accounting.Acquired(t)
+	if qty <= 0 {
+		return nil
+	}
+	_, err := tx.Exec(ctx,
+		`INSERT INTO trade_lots
+		 (trade_id, acquired_at, quantity, remaining_quantity, cost_per_eth, is_paper_trade)
+		 VALUES ($1,$2,$3,$3,$4,$5)`,
+		t.ID, t.Timestamp, qty, cost, t.IsPaperTrade,
+	)
+	return err
+}
+
+func disposeLots(ctx context.Context, tx pgx.Tx, sell models.Trade, method string) error {
+	rows, err := tx.Query(ctx,
+		`SELECT id, trade_id, acquired_at, quantity, remaining_quantity,
+		        cost_per_eth, is_paper_trade, created_at
+		 FROM trade_lots
+		 WHERE is_paper_trade = $1 AND remaining_quantity > 0`,
+		sell.IsPaperTrade,
+	)
+	if err != nil {
+		return err
+	}
+	var lots []models.Lot
+	for rows.Next() {
+		var l models.Lot
+		if err := rows.Scan(
+			&l.ID, &l.TradeID, &l.AcquiredAt, &l.Quantity, &l.RemainingQuantity,
+			&l.CostPerETH, &l.IsPaperTrade, &l.CreatedAt,
+		); err != nil {
+			rows.Close()
+			return err
+		}
+		lots = append(lots, l)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	disposals, err := accounting.Dispose(lots, sell, method)
+	if err != nil {
+		return err
+	}
+
+	remaining := make(map[int64]float64, len(lots))
+	for _, l := range lots {
+		remaining[l.ID] = l.RemainingQuantity
+	}
+
+	for _, d := range disposals {
+		if d.LotID != nil {
+			_, err := tx.Exec(ctx,
+				`UPDATE trade_lots SET remaining_quantity = $1 WHERE id = $2`,
+				remaining[*d.LotID], *d.LotID,
+			)
+			if err != nil {
+				return err
+			}
+		}
+		_, err := tx.Exec(ctx,
+			`INSERT INTO lot_disposals
+			 (lot_id, sell_trade_id, acquired_at, disposed_at, quantity,
+			  proceeds_usd, cost_basis_usd, gain_usd, method, is_paper_trade)
+			 VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)`,
+			d.LotID, d.SellTradeID, d.AcquiredAt, d.DisposedAt, d.Quantity,
+			d.ProceedsUSD, d.CostBasisUSD, d.GainUSD, d.Method, d.IsPaperTrade,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetLots returns lots newest first with their realized gains.
+// If paperMode is non-nil, filters by is_paper_trade.
+func (r *LotRepo) GetLots(ctx context.Context, openOnly bool, limit int, paperMode *bool) ([]models.Lot, error) {
+	query, args := buildFilteredQuery(
+		`SELECT l.id, l.trade_id, l.acquired_at, l.quantity, l.remaining_quantity,
+		        l.cost_per_eth, l.is_paper_trade, l.created_at,
+		        COALESCE((SELECT SUM(d.gain_usd) FROM lot_disposals d WHERE d.lot_id = l.id), 0)
+		 FROM trade_lots l WHERE 1=1`,
+		nil,
+		paperMode,
+	)
+	if openOnly {
+		query += " AND remaining_quantity > 0"
+	}
+	args = append(args, limit)
+	query += fmt.Sprintf(" ORDER BY acquired_at DESC LIMIT $%d", len(args))
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []models.Lot
+	for rows.Next() {
+		var l models.Lot
+		if err := rows.Scan(
+			&l.ID, &l.TradeID, &l.AcquiredAt, &l.Quantity, &l.RemainingQuantity,
+			&l.CostPerETH, &l.IsPaperTrade, &l.CreatedAt, &l.RealizedGainUSD,
+		); err != nil {
+			return nil, err
+		}
+		out = append(out, l)
+	}
+	return out, rows.Err()
+}
+
+// GetDisposals returns the most recent lot disposals.
+// If paperMode is non-nil, filters by is_paper_trade.
+func (r *LotRepo) GetDisposals(ctx context.Context, limit int, paperMode *bool) ([]models.LotDisposal, error) {
+	query, args := buildFilteredQuery(
+		`SELECT * FROM lot_disposals WHERE 1=1`,
+		nil,
+		paperMode,
+	)
+	args = append(args, limit)
+	query += fmt.Sprintf(" ORDER BY disposed_at DESC, id DESC LIMIT $%d", len(args))
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return collectDisposals(rows)
+}
+
+// GetSummary returns open-lot totals, average cost and realized gains.
+// If paperMode is non-nil, filters by is_paper_trade.
+func (r *LotRepo) GetSummary(ctx context.Context, paperMode *bool) (*models.LotSummary, error) {
+	var s models.LotSummary
+
+	query, args := buildFilteredQuery(
+		`SELECT
+			COUNT(*),
+			COALESCE(SUM(remaining_quantity), 0),
+			COALESCE(SUM(remaining_quantity * cost_per_eth), 0)
+		 FROM trade_lots WHERE remaining_quantity > 0`,
+		nil,
+		paperMode,
+	)
+	err := r.pool.QueryRow(ctx, query, args...).Scan(&s.OpenLots, &s.OpenQuantity, &s.OpenCostBasisUSD)
+	if err != nil {
+		return nil, err
+	}
+
+	query, args = buildFilteredQuery(
+		`SELECT
+			COALESCE(SUM(gain_usd), 0),
+			COALESCE(SUM(CASE WHEN lot_id IS NULL THEN quantity END), 0)
+		 FROM lot_disposals WHERE 1=1`,
+		nil,
+		paperMode,
+	)
+	err = r.pool.QueryRow(ctx, query, args...).Scan(&s.RealizedGainUSD, &s.UnmatchedQuantity)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.OpenQuantity > 0 {
+		avg := s.OpenCostBasisUSD / s.OpenQuantity
+		s.AvgCostPerETH = &avg
+	}
+	return &s, nil
+}
+
+// GetCapitalGains returns disposals in [from, to), oldest first, with the
+// buy and sell gas attributed to each by quantity.
+// If paperMode is non-nil, filters by is_paper_trade.
+func (r *LotRepo) GetCapitalGains(ctx context.Context, from, to time.Time, paperMode *bool) ([]models.CapitalGain, error) {
+	query := `SELECT d.*,
+			COALESCE(b.gas_cost_eth * b.price * d.quantity / l.quantity, 0),
+			COALESCE(s.gas_cost_eth, 0) * s.price * d.quantity / s.quantity,
+			COALESCE(b.gas_cost_eth * d.quantity / l.quantity, 0)
+				+ COALESCE(s.gas_cost_eth, 0) * d.quantity / s.quantity
+		 FROM lot_disposals d
+		 JOIN trade_history s ON s.id = d.sell_trade_id
+		 LEFT JOIN trade_lots l ON l.id = d.lot_id
+		 LEFT JOIN trade_history b ON b.id = l.trade_id
+		 WHERE d.disposed_at >= $1 AND d.disposed_at < $2`
+	args := []any{from, to}
+	if paperMode != nil {
+		args = append(args, *paperMode)
+		query += " AND d.is_paper_trade = $3"
+	}
+	query += " ORDER BY d.disposed_at ASC, d.id ASC"
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []models.CapitalGain
+	for rows.Next() {
+		var g models.CapitalGain
+		d := &g.LotDisposal
+		if err := rows.Scan(
+			&d.ID, &d.LotID, &d.SellTradeID, &d.AcquiredAt, &d.DisposedAt, &d.Quantity,
+			&d.ProceedsUSD, &d.CostBasisUSD, &d.GainUSD, &d.Method, &d.IsPaperTrade,
+			&d.CreatedAt, &g.BuyFeeUSD, &g.SellFeeUSD, &g.GasETH,
+		); err != nil {
+			return nil, err
+		}
+		out = append(out, g)
+	}
+	return out, rows.Err()
+}
+
+// --- scan helpers ---
+
+func collectDisposals(rows rowsIter) ([]models.LotDisposal, error) {
+	var out []models.LotDisposal
+	for rows.Next() {
+		var d models.LotDisposal
+		if err := rows.Scan(
+			&d.ID, &d.LotID, &d.SellTradeID, &d.AcquiredAt, &d.DisposedAt, &d.Quantity,
+			&d.ProceedsUSD, &d.CostBasisUSD, &d.GainUSD, &d.Method, &d.IsPaperTrade,
+			&d.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/kjannette/trahn-backend/internal/models"
+)
+
+type PendingTxRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewPendingTxRepo(pool *pgxpool.Pool) *PendingTxRepo {
+	return &PendingTxRepo{pool: pool}
+}
+
+// Save inserts the transaction or, if its wallet nonce is already stored,
+// overwrites it with the latest broadcast and status.
+func (r *PendingTxRepo) Save(ctx context.Context, tx *models.PendingTx) error {
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO pending_transactions
+		 (wallet_address, nonce, orig_hash, tx_hash, hashes, to_address, value_wei, data,
+		  gas_limit, gas_price_wei, tip_cap_wei, fee_cap_wei, sent_block, replacements,
+		  cancel_hash, mined_hash, status)
+		 VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17)
+		 ON CONFLICT (wallet_address, nonce) DO UPDATE SET
+		   orig_hash = EXCLUDED.orig_hash,
+		   tx_hash = EXCLUDED.tx_hash,
+		   hashes = EXCLUDED.hashes,
+		   to_address = EXCLUDED.to_address,
+		   value_wei = EXCLUDED.value_wei,
+		   data = EXCLUDED.data,
+		   gas_limit = EXCLUDED.gas_limit,
+		   gas_price_wei = EXCLUDED.gas_price_wei,
+		   tip_cap_wei = EXCLUDED.tip_cap_wei,
+		   fee_cap_wei = EXCLUDED.fee_cap_wei,
+		   sent_block = EXCLUDED.sent_block,
+		   replacements = EXCLUDED.replacements,
+		   cancel_hash = EXCLUDED.cancel_hash,
+		   mined_hash = EXCLUDED.mined_hash,
+		   status = EXCLUDED.status,
+		   updated_at = NOW()`,
+		tx.WalletAddress, tx.Nonce, tx.OrigHash, tx.TxHash, tx.Hashes, tx.ToAddress, tx.ValueWei, tx.Data,
+		tx.GasLimit, tx.GasPriceWei, tx.TipCapWei, tx.FeeCapWei, tx.SentBlock, tx.Replacements,
+		tx.CancelHash, tx.MinedHash, tx.Status,
+	)
+	return err
+}
+
+// GetPending returns the wallet's unresolved transactions by nonce.
+func (r *PendingTxRepo) GetPending(ctx context.Context, wallet string) ([]models.PendingTx, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT * FROM pending_transactions
+		 WHERE wallet_address = $1 AND status = 'pending'
+		 ORDER BY nonce`,
+		wallet,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var txs []models.PendingTx
+	for rows.Next() {
+		tx, err := scanPendingTx(rows)
+		if err != nil {
+			return nil, err
+		}
+		txs = append(txs, *tx)
+	}
+	return txs, rows.Err()
+}
+
+// --- scan helpers ---
+
+func scanPendingTx(row scannable) (*models.PendingTx, error) {
+	var tx models.PendingTx
+	err := row.Scan(
+		&tx.ID, &tx.WalletAddress, &tx.Nonce, &tx.OrigHash, &tx.TxHash, &tx.Hashes,
+		&tx.ToAddress, &tx.ValueWei, &tx.Data, &tx.GasLimit, &tx.GasPriceWei,
+		&tx.TipCapWei, &tx.FeeCapWei, &tx.SentBlock, &tx.Replacements,
+		&tx.CancelHash, &tx.MinedHash, &tx.Status, &tx.CreatedAt, &tx.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &tx, nil
+}
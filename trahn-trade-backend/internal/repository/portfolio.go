@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/kjannette/trahn-backend/internal/models"
+)
+
+type PortfolioRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewPortfolioRepo(pool *pgxpool.Pool) *PortfolioRepo {
+	return &PortfolioRepo{pool: pool}
+}
+
+func (r *PortfolioRepo) Record(ctx context.Context, s *models.PortfolioSnapshot) (*models.PortfolioSnapshot, error) {
+	ts := s.Timestamp
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+	row := r.pool.QueryRow(ctx,
+		`INSERT INTO portfolio_snapshots
+		 (timestamp, wallet_address, eth_balance, usdc_balance, eth_price, value_usd, is_baseline)
+		 VALUES ($1,$2,$3,$4,$5,$6,$7)
+		 RETURNING *`,
+		ts, s.WalletAddress, s.ETHBalance, s.USDCBalance, s.ETHPrice, s.ValueUSD, s.IsBaseline,
+	)
+	return scanSnapshot(row)
+}
+
+// GetBaseline returns the most recent baseline snapshot for a wallet, or nil
+// if none has been recorded.
+func (r *PortfolioRepo) GetBaseline(ctx context.Context, wallet string) (*models.PortfolioSnapshot, error) {
+	row := r.pool.QueryRow(ctx,
+		`SELECT * FROM portfolio_snapshots
+		 WHERE wallet_address = $1 AND is_baseline = true
+		 ORDER BY timestamp DESC LIMIT 1`,
+		wallet,
+	)
+	s, err := scanSnapshot(row)
+	if err != nil {
+		if err.Error() == "no rows in result set" {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return s, nil
+}
+
+// --- scan helpers ---
+
+func scanSnapshot(row scannable) (*models.PortfolioSnapshot, error) {
+	var s models.PortfolioSnapshot
+	err := row.Scan(
+		&s.ID, &s.Timestamp, &s.WalletAddress, &s.ETHBalance, &s.USDCBalance,
+		&s.ETHPrice, &s.ValueUSD, &s.IsBaseline, &s.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
@@ -16,12 +16,14 @@ func NewPriceRepo(pool *pgxpool.Pool) *PriceRepo {
 	return &PriceRepo{pool: pool}
 }
 
-func (r *PriceRepo) Record(ctx context.Context, price float64, ts time.Time) (*models.PricePoint, error) {
+// Record stores a price. source names where it came from, e.g. the
+// contributing oracle sources "coingecko,chainlink".
+func (r *PriceRepo) Record(ctx context.Context, price float64, ts time.Time, source string) (*models.PricePoint, error) {
 	td := TradingDay(ts)
 	row := r.pool.QueryRow(ctx,
 		`INSERT INTO price_history (timestamp, price, trading_day, source)
 		 VALUES ($1, $2, $3, $4) RETURNING *`,
-		ts, price, td, "coingecko",
+		ts, price, td, source,
 	)
 	return scanPrice(row)
 }
@@ -38,6 +40,19 @@ func (r *PriceRepo) GetByDay(ctx context.Context, tradingDay string) ([]models.P
 	return collectPrices(rows)
 }
 
+// GetRange returns price points with from <= timestamp < to, oldest first.
+func (r *PriceRepo) GetRange(ctx context.Context, from, to time.Time) ([]models.PricePoint, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT * FROM price_history WHERE timestamp >= $1 AND timestamp < $2 ORDER BY timestamp ASC`,
+		from, to,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return collectPrices(rows)
+}
+
 func (r *PriceRepo) GetAvailableDays(ctx context.Context) ([]string, error) {
 	rows, err := r.pool.Query(ctx,
 		`SELECT DISTINCT trading_day FROM price_history ORDER BY trading_day ASC LIMIT 30`,
@@ -20,10 +20,13 @@ func TestPriceRepo(t *testing.T) {
 
 	// Record
 	ts := time.Now()
-	p, err := repo.Record(ctx, 2650.42, ts)
+	p, err := repo.Record(ctx, 2650.42, ts, "coingecko,chainlink")
 	if err != nil {
 		t.Fatalf("Record: %v", err)
 	}
+	if p.Source != "coingecko,chainlink" {
+		t.Fatalf("source mismatch: got %q", p.Source)
+	}
 	if p.ID == 0 {
 		t.Fatal("expected non-zero ID")
 	}
@@ -52,6 +55,16 @@ func TestPriceRepo(t *testing.T) {
 	}
 	t.Logf("GetByDay(%s): %d rows", p.TradingDay, len(prices))
 
+	// GetRange
+	ranged, err := repo.GetRange(ctx, ts.Add(-time.Minute), ts.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("GetRange: %v", err)
+	}
+	if len(ranged) == 0 {
+		t.Fatal("expected prices in range")
+	}
+	t.Logf("GetRange: %d rows", len(ranged))
+
 	// GetAvailableDays
 	days, err := repo.GetAvailableDays(ctx)
 	if err != nil {
@@ -63,6 +76,77 @@ func TestPriceRepo(t *testing.T) {
 	t.Logf("Available days: %v", days)
 }
 
+// ---------- CandleRepo ----------
+
+func TestCandleRepo(t *testing.T) {
+	pool := testutil.SetupPool(t)
+	prices := repository.NewPriceRepo(pool)
+	repo := repository.NewCandleRepo(pool)
+	ctx := context.Background()
+
+	bucket := time.Now().UTC().Truncate(time.Minute).Add(time.Minute)
+	for i, p := range []float64{2600, 2640, 2590, 2620} {
+		if _, err := prices.Record(ctx, p, bucket.Add(time.Duration(i)*10*time.Second), "test"); err != nil {
+			t.Fatalf("Record: %v", err)
+		}
+	}
+
+	if _, err := repo.Sync(ctx, "1m"); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	candles, err := repo.GetRange(ctx, "1m", bucket, bucket.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("GetRange: %v", err)
+	}
+	if len(candles) != 1 {
+		t.Fatalf("expected 1 candle, got %d", len(candles))
+	}
+	c := candles[0]
+	if c.Open != 2600 || c.High != 2640 || c.Low != 2590 || c.Close != 2620 || c.Ticks < 4 {
+		t.Fatalf("unexpected candle: %+v", c)
+	}
+
+	// A later sync picks up a new tick in the same bucket.
+	if _, err := prices.Record(ctx, 2700, bucket.Add(50*time.Second), "test"); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+	if _, err := repo.Sync(ctx, "1m"); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	candles, err = repo.GetRange(ctx, "1m", bucket, bucket.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("GetRange: %v", err)
+	}
+	if len(candles) != 1 || candles[0].High != 2700 || candles[0].Close != 2700 {
+		t.Fatalf("expected updated candle, got %+v", candles)
+	}
+
+	// A tick recorded late into the last closed bucket is still counted.
+	if _, err := prices.Record(ctx, 2710, bucket.Add(90*time.Second), "test"); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+	if _, err := repo.Sync(ctx, "1m"); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if _, err := prices.Record(ctx, 2500, bucket.Add(55*time.Second), "test"); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+	if _, err := repo.Sync(ctx, "1m"); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	candles, err = repo.GetRange(ctx, "1m", bucket, bucket.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("GetRange: %v", err)
+	}
+	if len(candles) != 1 || candles[0].Low != 2500 || candles[0].Close != 2500 {
+		t.Fatalf("expected late tick in closed candle, got %+v", candles)
+	}
+
+	if _, err := repo.Sync(ctx, "15m"); err == nil {
+		t.Fatal("expected error for unknown resolution")
+	}
+}
+
 // ---------- TradeRepo ----------
 
 func TestTradeRepo(t *testing.T) {
@@ -98,6 +182,26 @@ func TestTradeRepo(t *testing.T) {
 	}
 	t.Logf("Recorded trade: id=%d side=%s price=%.2f qty=%.4f", recorded.ID, recorded.Side, recorded.Price, recorded.Quantity)
 
+	// Record a closing sell with realized P&L
+	realized := 1.25
+	sellLvl := 6
+	sell, err := repo.Record(ctx, &models.Trade{
+		Timestamp:    time.Now(),
+		Side:         "sell",
+		Price:        2652.00,
+		Quantity:     0.0377,
+		USDValue:     100.00,
+		GridLevel:    &sellLvl,
+		IsPaperTrade: true,
+		RealizedPnL:  &realized,
+	})
+	if err != nil {
+		t.Fatalf("Record(sell): %v", err)
+	}
+	if sell.RealizedPnL == nil || *sell.RealizedPnL != realized {
+		t.Fatalf("realized P&L mismatch: got %v", sell.RealizedPnL)
+	}
+
 	// GetAll (no filter)
 	all, err := repo.GetAll(ctx, 10, nil)
 	if err != nil {
@@ -136,6 +240,174 @@ func TestTradeRepo(t *testing.T) {
 	t.Logf("Stats(paper): total=%d buys=%d sells=%d", paperStats.TotalTrades, paperStats.BuyCount, paperStats.SellCount)
 }
 
+// ---------- LotRepo ----------
+
+func TestLotRepo(t *testing.T) {
+	pool := testutil.SetupPool(t)
+	trades := repository.NewTradeRepo(pool)
+	repo := repository.NewLotRepo(pool)
+	ctx := context.Background()
+
+	// Apply anything already in trade_history first
+	if _, err := repo.Sync(ctx, "fifo"); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+
+	before, err := repo.GetSummary(ctx, nil)
+	if err != nil {
+		t.Fatalf("GetSummary: %v", err)
+	}
+
+	_, err = trades.Record(ctx, &models.Trade{
+		Timestamp: time.Now(), Side: "buy",
+		Price: 2000, Quantity: 0.05, USDValue: 100, IsPaperTrade: true,
+	})
+	if err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+
+	n, err := repo.Sync(ctx, "fifo")
+	if err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if n != 1 {
+		t.Fatalf("expected 1 trade applied, got %d", n)
+	}
+
+	// An empty sell is skipped rather than failing every later sync.
+	for _, tr := range []models.Trade{
+		{Timestamp: time.Now(), Side: "sell", Price: 2000, IsPaperTrade: true},
+		{Timestamp: time.Now(), Side: "buy", Price: 2000, Quantity: 0.05, USDValue: 100, IsPaperTrade: true},
+	} {
+		if _, err := trades.Record(ctx, &tr); err != nil {
+			t.Fatalf("Record: %v", err)
+		}
+	}
+	if n, err = repo.Sync(ctx, "fifo"); err != nil {
+		t.Fatalf("Sync past an empty sell: %v", err)
+	}
+	if n != 1 {
+		t.Fatalf("expected the later buy applied, got %d", n)
+	}
+
+	// A trailing empty sell moves the watermark, so it is not read again.
+	empty, err := trades.Record(ctx, &models.Trade{Timestamp: time.Now(), Side: "sell", Price: 2000, IsPaperTrade: true})
+	if err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+	if n, err = repo.Sync(ctx, "fifo"); err != nil || n != 0 {
+		t.Fatalf("expected nothing applied, got %d (%v)", n, err)
+	}
+	var mark int64
+	if err := pool.QueryRow(ctx, `SELECT last_trade_id FROM lot_sync_state`).Scan(&mark); err != nil {
+		t.Fatalf("read lot_sync_state: %v", err)
+	}
+	if mark != empty.ID {
+		t.Fatalf("expected watermark at trade %d, got %d", empty.ID, mark)
+	}
+
+	after, err := repo.GetSummary(ctx, nil)
+	if err != nil {
+		t.Fatalf("GetSummary: %v", err)
+	}
+	if after.OpenLots != before.OpenLots+2 {
+		t.Fatalf("expected two more open lots, got %d -> %d", before.OpenLots, after.OpenLots)
+	}
+
+	open, err := repo.GetLots(ctx, true, 10, nil)
+	if err != nil {
+		t.Fatalf("GetLots: %v", err)
+	}
+	t.Logf("Open lots: %d, avg cost %v", len(open), after.AvgCostPerETH)
+
+	if _, err := repo.Sync(ctx, "average"); err == nil {
+		t.Fatal("expected invalid method error")
+	}
+}
+
+// ---------- PortfolioRepo ----------
+
+func TestPortfolioRepo(t *testing.T) {
+	pool := testutil.SetupPool(t)
+	repo := repository.NewPortfolioRepo(pool)
+	ctx := context.Background()
+
+	wallet := "0xtest-" + time.Now().Format("20060102150405.000000")
+	base, err := repo.GetBaseline(ctx, wallet)
+	if err != nil {
+		t.Fatalf("GetBaseline: %v", err)
+	}
+	if base != nil {
+		t.Fatal("expected no baseline for a new wallet")
+	}
+
+	price := 2000.0
+	_, err = repo.Record(ctx, &models.PortfolioSnapshot{
+		WalletAddress: wallet, ETHBalance: 1.5, USDCBalance: 2500,
+		ETHPrice: &price, IsBaseline: true,
+	})
+	if err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+
+	base, err = repo.GetBaseline(ctx, wallet)
+	if err != nil {
+		t.Fatalf("GetBaseline: %v", err)
+	}
+	if base == nil || base.ETHBalance != 1.5 || base.USDCBalance != 2500 {
+		t.Fatalf("unexpected baseline: %+v", base)
+	}
+}
+
+// ---------- PendingTxRepo ----------
+
+func TestPendingTxRepo(t *testing.T) {
+	pool := testutil.SetupPool(t)
+	repo := repository.NewPendingTxRepo(pool)
+	ctx := context.Background()
+
+	wallet := "0xtest-" + time.Now().Format("20060102150405.000000")
+	tip, feeCap := "2000000000", "42000000000"
+	tx := &models.PendingTx{
+		WalletAddress: wallet, Nonce: 7, OrigHash: "0xaaa", TxHash: "0xaaa",
+		Hashes: []string{"0xaaa"}, ToAddress: "0xrouter", ValueWei: "0",
+		Data: []byte{0x01, 0x02}, GasLimit: 250000, TipCapWei: &tip, FeeCapWei: &feeCap,
+		SentBlock: 100, Status: "pending",
+	}
+	if err := repo.Save(ctx, tx); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	// A replacement overwrites the same nonce.
+	tx.TxHash = "0xbbb"
+	tx.Hashes = append(tx.Hashes, "0xbbb")
+	tx.Replacements = 1
+	if err := repo.Save(ctx, tx); err != nil {
+		t.Fatalf("Save replacement: %v", err)
+	}
+
+	pending, err := repo.GetPending(ctx, wallet)
+	if err != nil {
+		t.Fatalf("GetPending: %v", err)
+	}
+	if len(pending) != 1 || pending[0].TxHash != "0xbbb" || len(pending[0].Hashes) != 2 || pending[0].Replacements != 1 {
+		t.Fatalf("unexpected pending: %+v", pending)
+	}
+
+	mined := "0xbbb"
+	tx.MinedHash, tx.Status = &mined, "mined"
+	if err := repo.Save(ctx, tx); err != nil {
+		t.Fatalf("Save mined: %v", err)
+	}
+	pending, err = repo.GetPending(ctx, wallet)
+	if err != nil {
+		t.Fatalf("GetPending: %v", err)
+	}
+	if len(pending) != 0 {
+		t.Fatalf("expected no pending after mining, got %d", len(pending))
+	}
+}
+
 // ---------- SRRepo ----------
 
 func TestSRRepo(t *testing.T) {
@@ -27,11 +27,13 @@ func (r *TradeRepo) Record(ctx context.Context, t *models.Trade) (*models.Trade,
 	row := r.pool.QueryRow(ctx,
 		`INSERT INTO trade_history
 		 (timestamp, trading_day, side, price, quantity, usd_value,
-		  grid_level, tx_hash, is_paper_trade, slippage_percent, gas_cost_eth)
-		 VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11)
+		  grid_level, tx_hash, is_paper_trade, slippage_percent, gas_cost_eth,
+		  realized_pnl)
+		 VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12)
 		 RETURNING *`,
 		ts, td, t.Side, t.Price, t.Quantity, t.USDValue,
 		t.GridLevel, t.TxHash, t.IsPaperTrade, t.SlippagePercent, t.GasCostETH,
+		t.RealizedPnL,
 	)
 	return scanTrade(row)
 }
@@ -83,6 +85,7 @@ func (r *TradeRepo) GetStats(ctx context.Context, paperMode *bool) (*models.Trad
 			COUNT(CASE WHEN side = 'sell' THEN 1 END),
 			SUM(usd_value),
 			AVG(price),
+			SUM(realized_pnl),
 			MIN(timestamp),
 			MAX(timestamp)
 		 FROM trade_history WHERE 1=1`,
@@ -93,7 +96,7 @@ func (r *TradeRepo) GetStats(ctx context.Context, paperMode *bool) (*models.Trad
 	var s models.TradeStats
 	err := r.pool.QueryRow(ctx, query, args...).Scan(
 		&s.TotalTrades, &s.BuyCount, &s.SellCount,
-		&s.TotalVolume, &s.AvgPrice, &s.FirstTrade, &s.LastTrade,
+		&s.TotalVolume, &s.AvgPrice, &s.RealizedPnL, &s.FirstTrade, &s.LastTrade,
 	)
 	if err != nil {
 		return nil, err
@@ -127,7 +130,7 @@ func scanTrade(row scannable) (*models.Trade, error) {
 	err := row.Scan(
 		&t.ID, &t.Timestamp, &td, &t.Side, &t.Price, &t.Quantity, &t.USDValue,
 		&t.GridLevel, &t.TxHash, &t.IsPaperTrade, &t.SlippagePercent, &t.GasCostETH,
-		&t.CreatedAt,
+		&t.CreatedAt, &t.RealizedPnL,
 	)
 	if err != nil {
 		return nil, err
@@ -144,7 +147,7 @@ func collectTrades(rows rowsIter) ([]models.Trade, error) {
 		if err := rows.Scan(
 			&t.ID, &t.Timestamp, &td, &t.Side, &t.Price, &t.Quantity, &t.USDValue,
 			&t.GridLevel, &t.TxHash, &t.IsPaperTrade, &t.SlippagePercent, &t.GasCostETH,
-			&t.CreatedAt,
+			&t.CreatedAt, &t.RealizedPnL,
 		); err != nil {
 			return nil, err
 		}
@@ -2,22 +2,35 @@ package risk
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
+	"time"
 )
 
+// ErrStalePrice is returned (wrapped) by PriceCheck when the latest price
+// observation is older than MaxPriceAge.
+var ErrStalePrice = errors.New("price data is stale")
+
+// ErrTWAPDeviation is returned (wrapped) by TWAPCheck when the execution
+// price is too far from the pool's time-weighted average.
+var ErrTWAPDeviation = errors.New("execution price too far from TWAP")
+
 // DailyTradeCounter abstracts the trade-counting dependency so Guardian
 // can be tested without a real database.
 type DailyTradeCounter interface {
 	CountToday(ctx context.Context) (int, error)
 }
 
-// Limits holds the four risk thresholds from config.
+// Limits holds the risk thresholds from config.
 // A zero value for any field means that check is disabled.
 type Limits struct {
 	MaxDailyTrades     int
 	MaxPositionSizeUSD float64
 	StopLossPercent    float64
 	TakeProfitPercent  float64
+	MaxPriceAge        time.Duration
+	MaxTWAPDeviation   float64 // percent
 }
 
 type Guardian struct {
@@ -51,6 +64,13 @@ func (g *Guardian) PreTradeCheck(ctx context.Context, tradeUSDValue float64) err
 	return nil
 }
 
+// MaxPositionUSD is the largest trade PreTradeCheck allows, or 0 when
+// position size is not limited. Grid sizing caps levels at it so a level
+// is never sized to be blocked.
+func (g *Guardian) MaxPositionUSD() float64 {
+	return g.limits.MaxPositionSizeUSD
+}
+
 // PortfolioCheck evaluates portfolio-level circuit breakers.
 // pnlPercent is the unrealized P&L as a percentage (e.g. -8.5 means down 8.5%).
 // Returns nil if trading should continue, a descriptive error if a breaker tripped.
@@ -67,3 +87,38 @@ func (g *Guardian) PortfolioCheck(pnlPercent float64) error {
 
 	return nil
 }
+
+// PriceCheck blocks trading on a price observed longer than MaxPriceAge
+// ago. observedAt is when the price sources last produced a price; the
+// zero time (no price yet) is always stale.
+func (g *Guardian) PriceCheck(observedAt, now time.Time) error {
+	if g.limits.MaxPriceAge <= 0 {
+		return nil
+	}
+	if observedAt.IsZero() {
+		return fmt.Errorf("trade blocked: %w: no price observed yet", ErrStalePrice)
+	}
+	if age := now.Sub(observedAt); age > g.limits.MaxPriceAge {
+		return fmt.Errorf("trade blocked: %w: last price is %s old (max %s)",
+			ErrStalePrice, age.Round(time.Second), g.limits.MaxPriceAge)
+	}
+	return nil
+}
+
+// TWAPCheck blocks a trade whose execution price is more than
+// MaxTWAPDeviation percent from the time-weighted average price, e.g. when
+// the pool has just been pushed off-market.
+func (g *Guardian) TWAPCheck(execPrice, twap float64) error {
+	if g.limits.MaxTWAPDeviation <= 0 {
+		return nil
+	}
+	if twap <= 0 {
+		return fmt.Errorf("trade blocked: %w: no TWAP available", ErrTWAPDeviation)
+	}
+	dev := math.Abs(execPrice-twap) / twap * 100
+	if dev > g.limits.MaxTWAPDeviation {
+		return fmt.Errorf("trade blocked: %w: $%.2f is %.2f%% from TWAP $%.2f (max %.2f%%)",
+			ErrTWAPDeviation, execPrice, dev, twap, g.limits.MaxTWAPDeviation)
+	}
+	return nil
+}
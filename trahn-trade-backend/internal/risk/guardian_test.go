@@ -2,8 +2,10 @@ package risk
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"testing"
+	"time"
 )
 
 type mockCounter struct {
@@ -40,6 +42,17 @@ func TestPreTradeCheck_PositionSize_DisabledWhenZero(t *testing.T) {
 	}
 }
 
+func TestPreTradeCheck_PositionSize_AtMaxPositionUSD(t *testing.T) {
+	g := NewGuardian(Limits{MaxPositionSizeUSD: 500}, &mockCounter{})
+	if g.MaxPositionUSD() != 500 {
+		t.Fatalf("expected MaxPositionUSD 500, got %.2f", g.MaxPositionUSD())
+	}
+	// A level sized exactly at the cap is allowed.
+	if err := g.PreTradeCheck(context.Background(), g.MaxPositionUSD()); err != nil {
+		t.Fatalf("expected trade at the cap to be allowed, got: %v", err)
+	}
+}
+
 func TestPreTradeCheck_DailyTrades_Allowed(t *testing.T) {
 	g := NewGuardian(Limits{MaxDailyTrades: 50}, &mockCounter{count: 49})
 	if err := g.PreTradeCheck(context.Background(), 100); err != nil {
@@ -151,3 +164,50 @@ func TestPortfolioCheck_TakeProfit_ExactBoundary(t *testing.T) {
 		t.Fatal("expected take-profit to trigger at exactly +15%")
 	}
 }
+
+// --- PriceCheck ---
+
+func TestPriceCheck(t *testing.T) {
+	g := NewGuardian(Limits{MaxPriceAge: 3 * time.Minute}, &mockCounter{})
+	now := time.Now()
+
+	if err := g.PriceCheck(now.Add(-time.Minute), now); err != nil {
+		t.Fatalf("expected fresh price to be allowed, got: %v", err)
+	}
+	if err := g.PriceCheck(now.Add(-4*time.Minute), now); !errors.Is(err, ErrStalePrice) {
+		t.Fatalf("expected ErrStalePrice, got: %v", err)
+	}
+	if err := g.PriceCheck(time.Time{}, now); !errors.Is(err, ErrStalePrice) {
+		t.Fatalf("expected ErrStalePrice with no price, got: %v", err)
+	}
+}
+
+func TestPriceCheck_DisabledWhenZero(t *testing.T) {
+	g := NewGuardian(Limits{}, &mockCounter{})
+	if err := g.PriceCheck(time.Now().Add(-24*time.Hour), time.Now()); err != nil {
+		t.Fatalf("zero limit should disable check, got: %v", err)
+	}
+}
+
+// --- TWAPCheck ---
+
+func TestTWAPCheck(t *testing.T) {
+	g := NewGuardian(Limits{MaxTWAPDeviation: 1}, &mockCounter{})
+
+	if err := g.TWAPCheck(2010, 2000); err != nil {
+		t.Fatalf("expected 0.5%% deviation to be allowed, got: %v", err)
+	}
+	if err := g.TWAPCheck(1970, 2000); !errors.Is(err, ErrTWAPDeviation) {
+		t.Fatalf("expected ErrTWAPDeviation for 1.5%% below, got: %v", err)
+	}
+	if err := g.TWAPCheck(2000, 0); !errors.Is(err, ErrTWAPDeviation) {
+		t.Fatalf("expected ErrTWAPDeviation with no TWAP, got: %v", err)
+	}
+}
+
+func TestTWAPCheck_DisabledWhenZero(t *testing.T) {
+	g := NewGuardian(Limits{}, &mockCounter{})
+	if err := g.TWAPCheck(3000, 2000); err != nil {
+		t.Fatalf("zero limit should disable check, got: %v", err)
+	}
+}
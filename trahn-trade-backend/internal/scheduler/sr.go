@@ -28,10 +28,11 @@ type SRSchedulerConfig struct {
 	GetBotState       BotStateProvider
 	OnSRUpdate        func(sr *external.SRResult)
 	OnGridRecalculate func(sr *external.SRResult)
+	CrossCheck        external.SRProvider // optional second source, only logged against the first
 }
 
 type SRScheduler struct {
-	dune   *external.DuneClient
+	source external.SRProvider
 	srRepo *repository.SRRepo
 	cfg    SRSchedulerConfig
 
@@ -40,7 +41,7 @@ type SRScheduler struct {
 	stopCh  chan struct{}
 }
 
-func NewSRScheduler(dune *external.DuneClient, srRepo *repository.SRRepo, cfg SRSchedulerConfig) *SRScheduler {
+func NewSRScheduler(source external.SRProvider, srRepo *repository.SRRepo, cfg SRSchedulerConfig) *SRScheduler {
 	if cfg.CronInterval <= 0 {
 		cfg.CronInterval = 1 * time.Hour
 	}
@@ -48,7 +49,7 @@ func NewSRScheduler(dune *external.DuneClient, srRepo *repository.SRRepo, cfg SR
 		cfg.SRChangeThreshold = 5
 	}
 	return &SRScheduler{
-		dune:   dune,
+		source: source,
 		srRepo: srRepo,
 		cfg:    cfg,
 	}
@@ -119,12 +120,15 @@ func (s *SRScheduler) FetchNow(ctx context.Context) error {
 }
 
 func (s *SRScheduler) fetchAndProcess(ctx context.Context) error {
-	fmt.Println("[SR-SCHEDULER] Fetching S/R levels from Dune...")
+	fmt.Println("[SR-SCHEDULER] Fetching S/R levels...")
 
-	sr, err := s.dune.FetchSupportResistance(ctx, true)
+	sr, err := s.source.FetchSupportResistance(ctx, true)
 	if err != nil {
 		return fmt.Errorf("fetch S/R: %w", err)
 	}
+	if s.cfg.CrossCheck != nil {
+		s.crossCheck(ctx, sr)
+	}
 
 	shouldRecalculate := false
 	var reasons []string
@@ -206,6 +210,26 @@ func (s *SRScheduler) fetchAndProcess(ctx context.Context) error {
 	return nil
 }
 
+// crossCheck logs how far the CrossCheck provider's levels are from sr.
+// Its result is never stored or acted on.
+func (s *SRScheduler) crossCheck(ctx context.Context, sr *external.SRResult) {
+	other, err := s.cfg.CrossCheck.FetchSupportResistance(ctx, true)
+	if err != nil {
+		fmt.Printf("[SR-SCHEDULER] Cross-check unavailable: %v\n", err)
+		return
+	}
+	fmt.Printf("[SR-SCHEDULER] Cross-check %s vs %s: support %+.2f%% | resistance %+.2f%% | midpoint %+.2f%%\n",
+		other.Method, sr.Method,
+		pctDiff(other.Support, sr.Support), pctDiff(other.Resistance, sr.Resistance), pctDiff(other.Midpoint, sr.Midpoint))
+}
+
+func pctDiff(v, ref float64) float64 {
+	if ref == 0 {
+		return 0
+	}
+	return (v - ref) / ref * 100
+}
+
 func gridRange(grid []strategy.GridLevel) (lo, hi float64) {
 	lo = math.MaxFloat64
 	hi = -math.MaxFloat64
@@ -16,6 +16,15 @@ type GridLevel struct {
 	Filled   bool       `json:"filled"`
 	FilledAt *time.Time `json:"filledAt,omitempty"`
 	TxHash   *string    `json:"txHash,omitempty"`
+
+	// PendingTx is the hash of a swap sent for this level that had not
+	// confirmed when the exchange returned. The level does not trigger again
+	// until the swap is settled one way or the other.
+	PendingTx *string `json:"pendingTx,omitempty"`
+
+	// CostBasis is the USD paid per ETH by the open buy on this level,
+	// including gas. Set while a buy level is filled, cleared on re-arm.
+	CostBasis *float64 `json:"costBasis,omitempty"`
 }
 
 type GridStats struct {
@@ -49,46 +58,117 @@ type GridParams struct {
 	LevelCount     int
 	SpacingPercent float64
 	AmountPerGrid  float64
+
+	// SpacingMode derives the spacing from recent volatility instead of
+	// SpacingPercent; see EffectiveSpacing. Empty means SpacingFixed.
+	SpacingMode       string
+	Bars              []Bar // recent history for the atr and volatility modes
+	SpacingMultiplier float64
+	MinSpacingPercent float64
+	MaxSpacingPercent float64
+
+	// LowerPrice and UpperPrice, when set, bound the grid: the lowest level
+	// sits at LowerPrice (e.g. support), the highest at UpperPrice (e.g.
+	// resistance), and LevelCount levels are spread between them per
+	// Layout. Spacing is then implied by the range and the spacing fields
+	// are ignored. CenterPrice still divides buys from sells.
+	LowerPrice float64
+	UpperPrice float64
+	Layout     string // LayoutGeometric (default) or LayoutArithmetic
+
+	// BuyLevels and SellLevels, when either is set, replace the even split
+	// of LevelCount: that many buys below CenterPrice and sells above it.
+	BuyLevels  int
+	SellLevels int
+
+	// Ladder, when set, is the exact list of level prices, overriding the
+	// count, spacing and bounds above. Prices below CenterPrice buy. A ladder
+	// entirely on one side of CenterPrice is allowed: its levels wait for
+	// price to come back.
+	Ladder []float64
+
+	// Sizing picks how much each level trades; see sizeLevels. Empty means
+	// SizingQuote, AmountPerGrid USD per level.
+	Sizing               string
+	PyramidMultiplier    float64 // pyramid: buy size growth per level below center
+	PyramidMaxMultiplier float64 // pyramid: cap on that growth
+	BalanceUSDC          float64 // balance: available funds to split
+	BalanceETH           float64
+	BalancePercent       float64 // balance: share of the balances to deploy
+	MaxLevelUSD          float64 // caps every level's value; 0 for no cap
+}
+
+// Grid layouts for GridParams.Layout.
+const (
+	LayoutGeometric  = "geometric"  // equal percentage steps
+	LayoutArithmetic = "arithmetic" // equal dollar steps
+)
+
+// Bounded reports whether p spans a fixed price range rather than stepping
+// out from CenterPrice.
+func (p GridParams) Bounded() bool {
+	return p.LowerPrice > 0 || p.UpperPrice > 0
+}
+
+// asymmetric reports whether the buy and sell counts are set explicitly.
+func (p GridParams) asymmetric() bool {
+	return p.BuyLevels != 0 || p.SellLevels != 0
 }
 
 func CalculateGridLevels(p GridParams) ([]GridLevel, error) {
 	if p.CenterPrice <= 0 {
 		return nil, fmt.Errorf("center price must be positive")
 	}
-	if p.LevelCount < 2 {
+	switch p.Layout {
+	case "", LayoutGeometric, LayoutArithmetic:
+	default:
+		return nil, fmt.Errorf("unknown grid layout %q", p.Layout)
+	}
+	switch {
+	case len(p.Ladder) > 0:
+	case p.asymmetric():
+		if p.BuyLevels < 1 || p.SellLevels < 1 {
+			return nil, fmt.Errorf("need at least 1 buy and 1 sell level (got %d buys, %d sells)", p.BuyLevels, p.SellLevels)
+		}
+	case p.LevelCount < 2:
 		return nil, fmt.Errorf("level count must be at least 2")
 	}
-	if p.SpacingPercent <= 0 {
-		return nil, fmt.Errorf("spacing percent must be positive")
+
+	var prices []float64
+	var err error
+	switch {
+	case len(p.Ladder) > 0:
+		prices, err = ladderPrices(p)
+	case p.Bounded():
+		prices, err = boundedPrices(p)
+	default:
+		var spacing float64
+		if spacing, err = EffectiveSpacing(p); err != nil {
+			return nil, err
+		}
+		if spacing <= 0 {
+			return nil, fmt.Errorf("spacing percent must be positive")
+		}
+		prices, err = centeredPrices(p, spacing)
+	}
+	if err != nil {
+		return nil, err
 	}
 	if p.AmountPerGrid <= 0 {
 		return nil, fmt.Errorf("amount per grid must be positive")
 	}
 
-	halfLevels := p.LevelCount / 2
-	even := p.LevelCount%2 == 0
-
 	var grid []GridLevel
 
-	for i := -halfLevels; i <= halfLevels; i++ {
-		if i == 0 && even {
-			continue
-		}
-
-		multiplier := math.Pow(1+p.SpacingPercent/100, float64(i))
-		levelPrice := p.CenterPrice * multiplier
-
+	for _, levelPrice := range prices {
 		side := "sell"
-		if i < 0 {
+		if levelPrice < p.CenterPrice {
 			side = "buy"
 		}
 
-		quantity := p.AmountPerGrid / levelPrice
-
 		grid = append(grid, GridLevel{
-			Price:    levelPrice,
-			Side:     side,
-			Quantity: quantity,
+			Price: levelPrice,
+			Side:  side,
 		})
 	}
 
@@ -100,12 +180,109 @@ func CalculateGridLevels(p GridParams) ([]GridLevel, error) {
 		grid[i].Index = i
 	}
 
+	if err := sizeLevels(grid, p); err != nil {
+		return nil, err
+	}
 	return grid, nil
 }
 
+// centeredPrices steps out from CenterPrice by spacing percent, compounding
+// per level for the geometric layout or in fixed dollar steps of spacing
+// percent of center for the arithmetic one. Without explicit buy/sell
+// counts, half the levels go on each side and an odd count puts a sell
+// level at center itself.
+func centeredPrices(p GridParams, spacing float64) ([]float64, error) {
+	lo, hi := -(p.LevelCount / 2), p.LevelCount/2
+	skipCenter := p.LevelCount%2 == 0
+	if p.asymmetric() {
+		lo, hi, skipCenter = -p.BuyLevels, p.SellLevels, true
+	}
+
+	var prices []float64
+	for i := lo; i <= hi; i++ {
+		if i == 0 && skipCenter {
+			continue
+		}
+		var price float64
+		if p.Layout == LayoutArithmetic {
+			price = p.CenterPrice * (1 + spacing/100*float64(i))
+		} else {
+			price = p.CenterPrice * math.Pow(1+spacing/100, float64(i))
+		}
+		if price <= 0 {
+			return nil, fmt.Errorf("arithmetic grid of %d buy levels at %.2f%% spacing goes below zero", -lo, spacing)
+		}
+		prices = append(prices, price)
+	}
+	return prices, nil
+}
+
+// boundedPrices spreads the levels from LowerPrice to UpperPrice inclusive.
+// With explicit buy/sell counts the buys run from LowerPrice up to (not
+// including) CenterPrice and the sells from above it up to UpperPrice.
+func boundedPrices(p GridParams) ([]float64, error) {
+	lo, hi, center := p.LowerPrice, p.UpperPrice, p.CenterPrice
+	if lo <= 0 || hi <= lo {
+		return nil, fmt.Errorf("invalid grid bounds: lower %.2f must be positive and below upper %.2f", lo, hi)
+	}
+	if center <= lo || center >= hi {
+		return nil, fmt.Errorf("center price %.2f must lie within grid bounds %.2f-%.2f", center, lo, hi)
+	}
+
+	if !p.asymmetric() {
+		n := p.LevelCount - 1
+		prices := make([]float64, p.LevelCount)
+		for i := range prices {
+			prices[i] = interpolate(lo, hi, float64(i)/float64(n), p.Layout)
+		}
+		prices[n] = hi // exact, despite rounding
+		return prices, nil
+	}
+
+	var prices []float64
+	for i := 0; i < p.BuyLevels; i++ {
+		prices = append(prices, interpolate(lo, center, float64(i)/float64(p.BuyLevels), p.Layout))
+	}
+	for i := 1; i <= p.SellLevels; i++ {
+		prices = append(prices, interpolate(center, hi, float64(i)/float64(p.SellLevels), p.Layout))
+	}
+	prices[len(prices)-1] = hi
+	return prices, nil
+}
+
+// interpolate returns the point frac of the way from a to b, in equal
+// ratios for the geometric layout or equal differences for the arithmetic.
+func interpolate(a, b, frac float64, layout string) float64 {
+	if layout == LayoutArithmetic {
+		return a + (b-a)*frac
+	}
+	return a * math.Pow(b/a, frac)
+}
+
+// ladderPrices validates a user-supplied ladder: at least 2 positive,
+// distinct prices.
+func ladderPrices(p GridParams) ([]float64, error) {
+	if len(p.Ladder) < 2 {
+		return nil, fmt.Errorf("ladder needs at least 2 prices (got %d)", len(p.Ladder))
+	}
+	prices := make([]float64, len(p.Ladder))
+	copy(prices, p.Ladder)
+	sort.Float64s(prices)
+
+	if prices[0] <= 0 {
+		return nil, fmt.Errorf("ladder prices must be positive (got %.2f)", prices[0])
+	}
+	for i := 1; i < len(prices); i++ {
+		if prices[i] == prices[i-1] {
+			return nil, fmt.Errorf("ladder price %.2f is listed twice", prices[i])
+		}
+	}
+	return prices, nil
+}
+
 func FindTriggeredLevel(currentPrice float64, grid []GridLevel) *GridLevel {
 	for i := range grid {
-		if grid[i].Filled {
+		if grid[i].Filled || grid[i].PendingTx != nil {
 			continue
 		}
 		if grid[i].Side == "buy" && currentPrice <= grid[i].Price {
@@ -131,6 +308,53 @@ func GetOppositeLevelIndex(filledLevel *GridLevel, gridLength int) *int {
 	return nil
 }
 
+// ResetOppositeLevel re-arms the level on the other side of a fill so the
+// grid can trade the same spread again. It returns the reset index and
+// whether anything changed.
+func ResetOppositeLevel(grid []GridLevel, filled *GridLevel) (int, bool) {
+	idx := GetOppositeLevelIndex(filled, len(grid))
+	if idx == nil {
+		return 0, false
+	}
+	adj := &grid[*idx]
+	if !adj.Filled {
+		return *idx, false
+	}
+	adj.Filled = false
+	adj.FilledAt = nil
+	adj.TxHash = nil
+	adj.CostBasis = nil
+	return *idx, true
+}
+
+// SettleFill records the economics of a fill on level. For a buy,
+// ethAmount/usdcAmount are the ETH received and USDC spent, and the cost
+// basis is stored on the level. For a sell they are the ETH sold and USDC
+// received; if the opposite buy level holds an open position, the realized
+// profit of the round trip is returned. gasUSD is the fill's gas cost.
+//
+// Call before ResetOppositeLevel, which clears the paired buy. ok is false
+// for sells with no paired buy (e.g. sold from starting inventory).
+func SettleFill(grid []GridLevel, level *GridLevel, ethAmount, usdcAmount, gasUSD float64) (profit float64, ok bool) {
+	if level.Side == "buy" {
+		if ethAmount > 0 {
+			basis := (usdcAmount + gasUSD) / ethAmount
+			level.CostBasis = &basis
+		}
+		return 0, false
+	}
+
+	idx := GetOppositeLevelIndex(level, len(grid))
+	if idx == nil {
+		return 0, false
+	}
+	buy := &grid[*idx]
+	if buy.Side != "buy" || !buy.Filled || buy.CostBasis == nil {
+		return 0, false
+	}
+	return usdcAmount - gasUSD - ethAmount**buy.CostBasis, true
+}
+
 func GetGridStats(grid []GridLevel) GridStats {
 	if len(grid) == 0 {
 		return GridStats{}
@@ -159,7 +383,10 @@ func GetGridStats(grid []GridLevel) GridStats {
 	return s
 }
 
-func FormatGridDisplay(grid []GridLevel, centerPrice, amountPerGrid float64) string {
+// FormatGridDisplay renders the grid top-down with each level's size in ETH
+// and USD at its price, since sizing modes and position caps make sizes
+// differ by level.
+func FormatGridDisplay(grid []GridLevel, centerPrice float64) string {
 	if len(grid) == 0 {
 		return "No grid levels initialized."
 	}
@@ -175,22 +402,37 @@ func FormatGridDisplay(grid []GridLevel, centerPrice, amountPerGrid float64) str
 	b.WriteString("│              GRID LEVELS (USD)               │\n")
 	b.WriteString("├─────────────────────────────────────────────────┤\n")
 
-	for _, level := range sorted {
+	buys := 0
+	var buyUSD, sellUSD float64
+	for i, level := range sorted {
+		usd := level.Quantity * level.Price
 		sideIcon := "BUY "
 		if level.Side == "sell" {
 			sideIcon = "SELL"
+			sellUSD += usd
+		} else {
+			buys++
+			buyUSD += usd
 		}
 		status := "[ ]"
 		if level.Filled {
 			status = "[X]"
 		}
-		fmt.Fprintf(&b, "│ %s %s @ %10.2f │ %15s │\n",
+		// Gap to the next level down, so arithmetic (constant $), geometric
+		// (constant %) and ladder layouts read at a glance.
+		gap := ""
+		if i+1 < len(sorted) {
+			below := sorted[i+1].Price
+			gap = fmt.Sprintf("+$%.2f/%.2f%%", level.Price-below, (level.Price/below-1)*100)
+		}
+		fmt.Fprintf(&b, "│ %s %s @ %10.2f │ %15s │ %10s │ %18s │\n",
 			status, sideIcon, level.Price,
-			fmt.Sprintf("%.6f ETH", level.Quantity))
+			fmt.Sprintf("%.6f ETH", level.Quantity), fmt.Sprintf("$%.2f", usd), gap)
 	}
 
 	b.WriteString("├─────────────────────────────────────────────────┤\n")
-	fmt.Fprintf(&b, "│  Center: $%8.2f  │  $%.0f/level  │\n", centerPrice, amountPerGrid)
+	fmt.Fprintf(&b, "│  Center: $%8.2f  │  %d buy ($%.0f) / %d sell ($%.0f)  │\n",
+		centerPrice, buys, buyUSD, len(sorted)-buys, sellUSD)
 	b.WriteString("└─────────────────────────────────────────────────┘")
 
 	return b.String()
@@ -205,6 +447,51 @@ func CreateFallbackSR(currentPrice float64) FallbackSR {
 	}
 }
 
+// CalculateSR derives support/resistance from a price series using the same
+// definitions as the Dune S/R queries: "simple" takes the min/max range, and
+// "percentile" uses the 5th/95th percentiles with the median as midpoint.
+func CalculateSR(prices []float64, method string) (FallbackSR, error) {
+	if len(prices) == 0 {
+		return FallbackSR{}, fmt.Errorf("no prices to derive S/R from")
+	}
+	sorted := make([]float64, len(prices))
+	copy(sorted, prices)
+	sort.Float64s(sorted)
+
+	var sr FallbackSR
+	switch method {
+	case "simple":
+		sr.Support = sorted[0]
+		sr.Resistance = sorted[len(sorted)-1]
+		sr.Midpoint = (sr.Support + sr.Resistance) / 2
+	case "percentile":
+		sr.Support = percentile(sorted, 0.05)
+		sr.Resistance = percentile(sorted, 0.95)
+		sr.Midpoint = percentile(sorted, 0.50)
+	default:
+		return FallbackSR{}, fmt.Errorf("unknown S/R method %q", method)
+	}
+	sr.Method = method
+
+	if sr.Support >= sr.Resistance {
+		return FallbackSR{}, fmt.Errorf("invalid S/R range: support %.2f >= resistance %.2f", sr.Support, sr.Resistance)
+	}
+	return sr, nil
+}
+
+// percentile returns the q-th quantile of an ascending slice using linear
+// interpolation between the closest ranks.
+func percentile(sorted []float64, q float64) float64 {
+	if len(sorted) == 1 {
+		return sorted[0]
+	}
+	pos := q * float64(len(sorted)-1)
+	lo := int(math.Floor(pos))
+	hi := int(math.Ceil(pos))
+	frac := pos - float64(lo)
+	return sorted[lo] + (sorted[hi]-sorted[lo])*frac
+}
+
 func IsPriceOutsideGrid(currentPrice float64, grid []GridLevel) bool {
 	if len(grid) == 0 {
 		return true
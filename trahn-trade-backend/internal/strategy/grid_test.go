@@ -2,6 +2,7 @@ package strategy
 
 import (
 	"math"
+	"strings"
 	"testing"
 )
 
@@ -121,6 +122,167 @@ func TestCalculateGridLevels_Validation(t *testing.T) {
 	}
 }
 
+func TestCalculateGridLevels_Bounded(t *testing.T) {
+	grid, err := CalculateGridLevels(GridParams{
+		CenterPrice:   2400,
+		LevelCount:    5,
+		AmountPerGrid: 100,
+		LowerPrice:    2000,
+		UpperPrice:    2800,
+		Layout:        LayoutArithmetic,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []float64{2000, 2200, 2400, 2600, 2800}
+	for i, l := range grid {
+		if math.Abs(l.Price-want[i]) > 1e-9 {
+			t.Fatalf("level %d: price %.4f, want %.0f", i, l.Price, want[i])
+		}
+	}
+	if grid[1].Side != "buy" || grid[2].Side != "sell" {
+		t.Fatalf("expected buys below center and sells from center up, got %s/%s", grid[1].Side, grid[2].Side)
+	}
+
+	grid, err = CalculateGridLevels(GridParams{
+		CenterPrice:   2200,
+		LevelCount:    4,
+		AmountPerGrid: 100,
+		LowerPrice:    2000,
+		UpperPrice:    2662,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Geometric: 10% steps from 2000 to 2662.
+	for i := 1; i < len(grid); i++ {
+		if r := grid[i].Price / grid[i-1].Price; math.Abs(r-1.1) > 1e-9 {
+			t.Fatalf("step %d: ratio %.6f, want 1.1", i, r)
+		}
+	}
+	if grid[0].Price != 2000 || grid[3].Price != 2662 {
+		t.Fatalf("expected levels at the bounds, got %.2f-%.2f", grid[0].Price, grid[3].Price)
+	}
+}
+
+func TestCalculateGridLevels_BoundedValidation(t *testing.T) {
+	cases := []GridParams{
+		{CenterPrice: 2400, LevelCount: 5, AmountPerGrid: 100, LowerPrice: 2800, UpperPrice: 2000},
+		{CenterPrice: 3000, LevelCount: 5, AmountPerGrid: 100, LowerPrice: 2000, UpperPrice: 2800},
+		{CenterPrice: 2400, LevelCount: 5, AmountPerGrid: 100, UpperPrice: 2800},
+		{CenterPrice: 2400, LevelCount: 5, AmountPerGrid: 100, LowerPrice: 2000, UpperPrice: 2800, Layout: "fibonacci"},
+	}
+	for i, c := range cases {
+		if _, err := CalculateGridLevels(c); err == nil {
+			t.Fatalf("case %d: expected validation error", i)
+		}
+	}
+}
+
+func TestCalculateGridLevels_Layouts(t *testing.T) {
+	// Arithmetic: 2% of 2500 = $50 steps, 2 buys and 3 sells.
+	grid, err := CalculateGridLevels(GridParams{
+		CenterPrice:    2500,
+		SpacingPercent: 2,
+		AmountPerGrid:  100,
+		Layout:         LayoutArithmetic,
+		BuyLevels:      2,
+		SellLevels:     3,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []float64{2400, 2450, 2550, 2600, 2650}
+	if len(grid) != len(want) {
+		t.Fatalf("expected %d levels, got %d", len(want), len(grid))
+	}
+	for i, l := range grid {
+		if math.Abs(l.Price-want[i]) > 1e-9 {
+			t.Fatalf("level %d: price %.4f, want %.0f", i, l.Price, want[i])
+		}
+		wantSide := "sell"
+		if i < 2 {
+			wantSide = "buy"
+		}
+		if l.Side != wantSide {
+			t.Fatalf("level %d: side %s, want %s", i, l.Side, wantSide)
+		}
+	}
+
+	// Bounded with 1 buy and 3 sells: buy at support, sells split center-resistance.
+	grid, err = CalculateGridLevels(GridParams{
+		CenterPrice:   2200,
+		AmountPerGrid: 100,
+		LowerPrice:    2000,
+		UpperPrice:    2800,
+		Layout:        LayoutArithmetic,
+		BuyLevels:     1,
+		SellLevels:    3,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = []float64{2000, 2400, 2600, 2800}
+	for i, l := range grid {
+		if math.Abs(l.Price-want[i]) > 1e-9 {
+			t.Fatalf("bounded level %d: price %.4f, want %.0f", i, l.Price, want[i])
+		}
+	}
+
+	// Ladder: used as given (sorted), sides split at center.
+	grid, err = CalculateGridLevels(GridParams{
+		CenterPrice:   2500,
+		AmountPerGrid: 100,
+		Ladder:        []float64{2700, 2300, 2450, 2520},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = []float64{2300, 2450, 2520, 2700}
+	for i, l := range grid {
+		if l.Price != want[i] {
+			t.Fatalf("ladder level %d: price %.2f, want %.0f", i, l.Price, want[i])
+		}
+	}
+	if grid[1].Side != "buy" || grid[2].Side != "sell" {
+		t.Fatalf("expected ladder to split at center, got %s/%s", grid[1].Side, grid[2].Side)
+	}
+
+	// A ladder below the center is all buys rather than an error.
+	grid, err = CalculateGridLevels(GridParams{
+		CenterPrice:   2500,
+		AmountPerGrid: 100,
+		Ladder:        []float64{2100, 2200, 2300},
+	})
+	if err != nil {
+		t.Fatalf("expected one-sided ladder to build, got %v", err)
+	}
+	for _, l := range grid {
+		if l.Side != "buy" {
+			t.Fatalf("expected all buys below center, got %s at %.0f", l.Side, l.Price)
+		}
+	}
+}
+
+func TestCalculateGridLevels_LayoutValidation(t *testing.T) {
+	cases := []GridParams{
+		// arithmetic buys past zero
+		{CenterPrice: 2500, LevelCount: 12, SpacingPercent: 20, AmountPerGrid: 100, Layout: LayoutArithmetic},
+		// one-sided asymmetric counts
+		{CenterPrice: 2500, SpacingPercent: 2, AmountPerGrid: 100, BuyLevels: 3},
+		{CenterPrice: 2500, SpacingPercent: 2, AmountPerGrid: 100, BuyLevels: -1, SellLevels: 3},
+		// ladders: too short, duplicate, non-positive
+		{CenterPrice: 2500, AmountPerGrid: 100, Ladder: []float64{2400}},
+		{CenterPrice: 2500, AmountPerGrid: 100, Ladder: []float64{2400, 2600, 2400}},
+		{CenterPrice: 2500, AmountPerGrid: 100, Ladder: []float64{0, 2600}},
+	}
+	for i, c := range cases {
+		if _, err := CalculateGridLevels(c); err == nil {
+			t.Fatalf("case %d: expected validation error", i)
+		}
+	}
+}
+
 func TestFindTriggeredLevel(t *testing.T) {
 	grid := []GridLevel{
 		{Index: 0, Price: 2550, Side: "buy"},
@@ -171,6 +333,13 @@ func TestFindTriggeredLevel(t *testing.T) {
 	if triggered.Index != 1 {
 		t.Fatalf("expected index 1 (skipping filled 0), got %d", triggered.Index)
 	}
+
+	// So are levels with a swap awaiting confirmation
+	hash := "0xabc"
+	grid[1].PendingTx = &hash
+	if triggered = FindTriggeredLevel(2540, grid); triggered != nil {
+		t.Fatalf("expected pending level to be skipped, got index %d", triggered.Index)
+	}
 }
 
 func TestGetOppositeLevelIndex(t *testing.T) {
@@ -235,13 +404,19 @@ func TestFormatGridDisplay(t *testing.T) {
 		{Index: 0, Price: 2600, Side: "buy", Quantity: 0.0385},
 		{Index: 1, Price: 2700, Side: "sell", Quantity: 0.0370, Filled: true},
 	}
-	out := FormatGridDisplay(grid, 2650, 100)
+	out := FormatGridDisplay(grid, 2650)
 	if out == "" {
 		t.Fatal("expected non-empty display")
 	}
 	t.Logf("\n%s", out)
+	if !strings.Contains(out, "+$100.00/3.85%") || !strings.Contains(out, "1 buy ($100) / 1 sell ($100)") {
+		t.Fatalf("expected level gap and side totals in display:\n%s", out)
+	}
+	if !strings.Contains(out, "$99.90") {
+		t.Fatalf("expected each level's USD value in display:\n%s", out)
+	}
 
-	empty := FormatGridDisplay(nil, 0, 0)
+	empty := FormatGridDisplay(nil, 0)
 	if empty != "No grid levels initialized." {
 		t.Fatalf("expected empty message, got: %s", empty)
 	}
@@ -321,3 +496,98 @@ func TestCalculateSRChange(t *testing.T) {
 		t.Fatalf("expected 0 for no change, got %.2f", pct)
 	}
 }
+
+func TestResetOppositeLevel(t *testing.T) {
+	hash := "0xabc"
+	grid := []GridLevel{
+		{Index: 0, Price: 2500, Side: "buy"},
+		{Index: 1, Price: 2600, Side: "buy"},
+		{Index: 2, Price: 2700, Side: "sell", Filled: true, TxHash: &hash},
+	}
+
+	idx, ok := ResetOppositeLevel(grid, &grid[1])
+	if !ok || idx != 2 {
+		t.Fatalf("expected level 2 reset, got idx=%d ok=%v", idx, ok)
+	}
+	if grid[2].Filled || grid[2].TxHash != nil {
+		t.Fatal("level 2 should be re-armed")
+	}
+
+	// Opposite not filled — nothing to do
+	if _, ok := ResetOppositeLevel(grid, &grid[1]); ok {
+		t.Fatal("expected no reset when opposite is unfilled")
+	}
+
+	// Out of bounds
+	if _, ok := ResetOppositeLevel(grid, &GridLevel{Index: 0, Side: "sell"}); ok {
+		t.Fatal("expected no reset for out-of-bounds opposite")
+	}
+}
+
+func TestSettleFill(t *testing.T) {
+	grid := []GridLevel{
+		{Index: 0, Price: 1960, Side: "buy"},
+		{Index: 1, Price: 2040, Side: "sell"},
+		{Index: 2, Price: 2080, Side: "sell"},
+	}
+
+	// Buy 0.05 ETH for $98 plus $2 gas -> $2000/ETH basis.
+	if _, ok := SettleFill(grid, &grid[0], 0.05, 98, 2); ok {
+		t.Fatal("buy should not realize profit")
+	}
+	grid[0].Filled = true
+	if grid[0].CostBasis == nil || math.Abs(*grid[0].CostBasis-2000) > 1e-9 {
+		t.Fatalf("expected $2000 cost basis, got %v", grid[0].CostBasis)
+	}
+
+	// Sell 0.05 ETH for $102 minus $1 gas closes the buy: 102 - 1 - 100 = 1.
+	profit, ok := SettleFill(grid, &grid[1], 0.05, 102, 1)
+	if !ok || math.Abs(profit-1) > 1e-9 {
+		t.Fatalf("expected $1 realized, got %.6f ok=%v", profit, ok)
+	}
+
+	// Re-arming the buy clears its basis.
+	grid[1].Filled = true
+	ResetOppositeLevel(grid, &grid[1])
+	if grid[0].CostBasis != nil {
+		t.Fatal("re-armed buy should have no cost basis")
+	}
+
+	// Sell with no open buy below (level 1 is a sell) realizes nothing.
+	if _, ok := SettleFill(grid, &grid[2], 0.05, 104, 1); ok {
+		t.Fatal("sell without paired buy should not realize profit")
+	}
+}
+
+func TestCalculateSR(t *testing.T) {
+	prices := make([]float64, 101)
+	for i := range prices {
+		prices[i] = 2000 + float64(i)*10 // 2000..3000
+	}
+
+	simple, err := CalculateSR(prices, "simple")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if simple.Support != 2000 || simple.Resistance != 3000 || simple.Midpoint != 2500 {
+		t.Fatalf("simple: unexpected %+v", simple)
+	}
+
+	pct, err := CalculateSR(prices, "percentile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(pct.Support-2050) > 1e-9 || math.Abs(pct.Resistance-2950) > 1e-9 || pct.Midpoint != 2500 {
+		t.Fatalf("percentile: unexpected %+v", pct)
+	}
+
+	if _, err := CalculateSR(prices, "bogus"); err == nil {
+		t.Fatal("expected error for unknown method")
+	}
+	if _, err := CalculateSR(nil, "simple"); err == nil {
+		t.Fatal("expected error for empty series")
+	}
+	if _, err := CalculateSR([]float64{2000, 2000}, "simple"); err == nil {
+		t.Fatal("expected error for flat series")
+	}
+}
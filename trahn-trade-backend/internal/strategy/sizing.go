@@ -0,0 +1,88 @@
+package strategy
+
+import (
+	"fmt"
+	"math"
+)
+
+// Position sizing modes for GridParams.Sizing.
+const (
+	SizingQuote   = "quote"   // AmountPerGrid USD at every level
+	SizingBase    = "base"    // the same ETH at every level, AmountPerGrid USD at center
+	SizingPyramid = "pyramid" // buys grow by PyramidMultiplier per level below center
+	SizingBalance = "balance" // BalancePercent of the balances split across each side
+)
+
+// sizeLevels sets the Quantity of every level in grid, which must be sorted
+// ascending with sides assigned. Sells are sized like buys except under
+// SizingPyramid, where they stay at AmountPerGrid USD, and SizingBalance,
+// where they split the ETH balance. Under SizingBalance a side with no
+// balance to split, such as the sells of a USDC-only wallet, is sized at
+// AmountPerGrid USD so the grid can still start. A positive MaxLevelUSD
+// caps any level's value at its price, so it never exceeds the risk
+// guardian's position limit.
+func sizeLevels(grid []GridLevel, p GridParams) error {
+	buys := 0
+	for _, l := range grid {
+		if l.Side == "buy" {
+			buys++
+		}
+	}
+	sells := len(grid) - buys
+
+	var buyUSD, sellETH float64
+	switch p.Sizing {
+	case "", SizingQuote, SizingBase:
+	case SizingPyramid:
+		if p.PyramidMultiplier < 1 {
+			return fmt.Errorf("pyramid multiplier must be at least 1 (got %.2f)", p.PyramidMultiplier)
+		}
+		if p.PyramidMaxMultiplier < 1 {
+			return fmt.Errorf("pyramid max multiplier must be at least 1 (got %.2f)", p.PyramidMaxMultiplier)
+		}
+	case SizingBalance:
+		if p.BalancePercent <= 0 || p.BalancePercent > 100 {
+			return fmt.Errorf("balance percent must be in (0, 100] (got %.2f)", p.BalancePercent)
+		}
+		if p.BalanceUSDC > 0 {
+			buyUSD = p.BalanceUSDC * p.BalancePercent / 100 / float64(max(buys, 1))
+		}
+		if p.BalanceETH > 0 {
+			sellETH = p.BalanceETH * p.BalancePercent / 100 / float64(max(sells, 1))
+		}
+	default:
+		return fmt.Errorf("unknown sizing mode %q", p.Sizing)
+	}
+
+	for i := range grid {
+		l := &grid[i]
+		switch p.Sizing {
+		case SizingBase:
+			l.Quantity = p.AmountPerGrid / p.CenterPrice
+		case SizingPyramid:
+			usd := p.AmountPerGrid
+			if l.Side == "buy" {
+				// The buy nearest center is depth 0.
+				depth := buys - 1 - i
+				usd *= math.Min(math.Pow(p.PyramidMultiplier, float64(depth)), p.PyramidMaxMultiplier)
+			}
+			l.Quantity = usd / l.Price
+		case SizingBalance:
+			switch {
+			case l.Side == "buy" && buyUSD > 0:
+				l.Quantity = buyUSD / l.Price
+			case l.Side == "sell" && sellETH > 0:
+				l.Quantity = sellETH
+			default:
+				l.Quantity = p.AmountPerGrid / l.Price
+			}
+		default:
+			l.Quantity = p.AmountPerGrid / l.Price
+		}
+
+		if p.MaxLevelUSD > 0 && l.Quantity*l.Price > p.MaxLevelUSD {
+			l.Quantity = p.MaxLevelUSD / l.Price
+		}
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package strategy
+
+import (
+	"math"
+	"testing"
+)
+
+// sizingGrid is 2 buys and 2 sells at 2% arithmetic steps around 2500.
+func sizingGrid(t *testing.T, p GridParams) []GridLevel {
+	t.Helper()
+	p.CenterPrice = 2500
+	p.SpacingPercent = 2
+	p.Layout = LayoutArithmetic
+	p.LevelCount = 4
+	if p.AmountPerGrid == 0 {
+		p.AmountPerGrid = 100
+	}
+	grid, err := CalculateGridLevels(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return grid
+}
+
+func usd(l GridLevel) float64 { return l.Quantity * l.Price }
+
+func TestSizeLevels_QuoteAndBase(t *testing.T) {
+	for _, l := range sizingGrid(t, GridParams{}) {
+		if math.Abs(usd(l)-100) > 1e-9 {
+			t.Fatalf("quote: level at %.0f worth $%.4f, want $100", l.Price, usd(l))
+		}
+	}
+	for _, l := range sizingGrid(t, GridParams{Sizing: SizingBase}) {
+		if math.Abs(l.Quantity-0.04) > 1e-12 {
+			t.Fatalf("base: level at %.0f trades %.6f ETH, want 0.04", l.Price, l.Quantity)
+		}
+	}
+}
+
+func TestSizeLevels_Pyramid(t *testing.T) {
+	grid := sizingGrid(t, GridParams{
+		Sizing:               SizingPyramid,
+		PyramidMultiplier:    2,
+		PyramidMaxMultiplier: 3,
+	})
+	// Buys at 2400 (depth 1) and 2450 (depth 0); sells stay at $100.
+	want := []float64{200, 100, 100, 100}
+	for i, l := range grid {
+		if math.Abs(usd(l)-want[i]) > 1e-9 {
+			t.Fatalf("level %d at %.0f worth $%.4f, want $%.0f", i, l.Price, usd(l), want[i])
+		}
+	}
+
+	grid = sizingGrid(t, GridParams{
+		Sizing:               SizingPyramid,
+		PyramidMultiplier:    4,
+		PyramidMaxMultiplier: 3,
+	})
+	if math.Abs(usd(grid[0])-300) > 1e-9 {
+		t.Fatalf("expected deepest buy capped at 3x ($300), got $%.4f", usd(grid[0]))
+	}
+}
+
+func TestSizeLevels_Balance(t *testing.T) {
+	grid := sizingGrid(t, GridParams{
+		Sizing:         SizingBalance,
+		BalanceUSDC:    1000,
+		BalanceETH:     0.5,
+		BalancePercent: 80,
+	})
+	for _, l := range grid {
+		if l.Side == "buy" && math.Abs(usd(l)-400) > 1e-9 {
+			t.Fatalf("buy at %.0f worth $%.4f, want $400", l.Price, usd(l))
+		}
+		if l.Side == "sell" && math.Abs(l.Quantity-0.2) > 1e-12 {
+			t.Fatalf("sell at %.0f trades %.6f ETH, want 0.2", l.Price, l.Quantity)
+		}
+	}
+}
+
+func TestSizeLevels_BalanceOneSided(t *testing.T) {
+	// A USDC-only wallet: buys split the USDC, sells fall back to $100.
+	grid := sizingGrid(t, GridParams{
+		Sizing:         SizingBalance,
+		BalanceUSDC:    1000,
+		BalancePercent: 80,
+	})
+	for _, l := range grid {
+		want := 400.0
+		if l.Side == "sell" {
+			want = 100
+		}
+		if math.Abs(usd(l)-want) > 1e-9 {
+			t.Fatalf("%s at %.0f worth $%.4f, want $%.0f", l.Side, l.Price, usd(l), want)
+		}
+	}
+}
+
+func TestSizeLevels_MaxLevelUSD(t *testing.T) {
+	grid := sizingGrid(t, GridParams{
+		Sizing:               SizingPyramid,
+		PyramidMultiplier:    2,
+		PyramidMaxMultiplier: 4,
+		MaxLevelUSD:          150,
+	})
+	if math.Abs(usd(grid[0])-150) > 1e-9 {
+		t.Fatalf("expected deepest buy capped at $150, got $%.4f", usd(grid[0]))
+	}
+	if math.Abs(usd(grid[1])-100) > 1e-9 {
+		t.Fatalf("expected buy under the cap left at $100, got $%.4f", usd(grid[1]))
+	}
+}
+
+func TestSizeLevels_Validation(t *testing.T) {
+	base := GridParams{CenterPrice: 2500, LevelCount: 4, SpacingPercent: 2, AmountPerGrid: 100}
+	cases := []func(p *GridParams){
+		func(p *GridParams) { p.Sizing = "kelly" },
+		func(p *GridParams) { p.Sizing, p.PyramidMultiplier, p.PyramidMaxMultiplier = SizingPyramid, 0.5, 2 },
+		func(p *GridParams) { p.Sizing, p.PyramidMultiplier, p.PyramidMaxMultiplier = SizingPyramid, 2, 0 },
+		func(p *GridParams) {
+			p.Sizing, p.BalanceUSDC, p.BalanceETH, p.BalancePercent = SizingBalance, 1000, 1, 0
+		},
+	}
+	for i, mod := range cases {
+		p := base
+		mod(&p)
+		if _, err := CalculateGridLevels(p); err == nil {
+			t.Fatalf("case %d: expected validation error", i)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package strategy
+
+import (
+	"fmt"
+	"math"
+)
+
+// Spacing modes for GridParams.SpacingMode.
+const (
+	SpacingFixed      = "fixed"      // SpacingPercent as given
+	SpacingATR        = "atr"        // average true range of Bars, as % of the last close
+	SpacingVolatility = "volatility" // standard deviation of Bars' close-to-close log returns
+)
+
+// Bar is one OHLC period of recent price history, oldest first in a slice.
+type Bar struct {
+	High  float64
+	Low   float64
+	Close float64
+}
+
+// EffectiveSpacing returns the spacing percent CalculateGridLevels will use.
+// In the fixed mode that is SpacingPercent. Otherwise it is the ATR or
+// realized volatility of Bars times SpacingMultiplier (default 1), clamped
+// to [MinSpacingPercent, MaxSpacingPercent] when those are set.
+func EffectiveSpacing(p GridParams) (float64, error) {
+	var vol float64
+	switch p.SpacingMode {
+	case "", SpacingFixed:
+		return p.SpacingPercent, nil
+	case SpacingATR:
+		if len(p.Bars) < 2 {
+			return 0, fmt.Errorf("ATR spacing needs at least 2 bars, got %d", len(p.Bars))
+		}
+		vol = ATRPercent(p.Bars)
+	case SpacingVolatility:
+		if len(p.Bars) < 3 {
+			return 0, fmt.Errorf("volatility spacing needs at least 3 bars, got %d", len(p.Bars))
+		}
+		vol = RealizedVolPercent(p.Bars)
+	default:
+		return 0, fmt.Errorf("unknown spacing mode %q", p.SpacingMode)
+	}
+
+	mult := p.SpacingMultiplier
+	if mult <= 0 {
+		mult = 1
+	}
+	spacing := vol * mult
+	if p.MinSpacingPercent > 0 {
+		spacing = math.Max(spacing, p.MinSpacingPercent)
+	}
+	if p.MaxSpacingPercent > 0 {
+		spacing = math.Min(spacing, p.MaxSpacingPercent)
+	}
+	return spacing, nil
+}
+
+// ATRPercent is the average true range over bars as a percentage of the
+// last close. The first bar only supplies the previous close.
+func ATRPercent(bars []Bar) float64 {
+	if len(bars) < 2 {
+		return 0
+	}
+	var sum float64
+	for i := 1; i < len(bars); i++ {
+		prev := bars[i-1].Close
+		tr := math.Max(bars[i].High-bars[i].Low,
+			math.Max(math.Abs(bars[i].High-prev), math.Abs(bars[i].Low-prev)))
+		sum += tr
+	}
+	last := bars[len(bars)-1].Close
+	if last <= 0 {
+		return 0
+	}
+	return sum / float64(len(bars)-1) / last * 100
+}
+
+// RealizedVolPercent is the sample standard deviation of close-to-close log
+// returns over bars, in percent per bar.
+func RealizedVolPercent(bars []Bar) float64 {
+	if len(bars) < 3 {
+		return 0
+	}
+	rets := make([]float64, 0, len(bars)-1)
+	for i := 1; i < len(bars); i++ {
+		if bars[i-1].Close <= 0 || bars[i].Close <= 0 {
+			continue
+		}
+		rets = append(rets, math.Log(bars[i].Close/bars[i-1].Close))
+	}
+	if len(rets) < 2 {
+		return 0
+	}
+	var mean float64
+	for _, r := range rets {
+		mean += r
+	}
+	mean /= float64(len(rets))
+	var ss float64
+	for _, r := range rets {
+		ss += (r - mean) * (r - mean)
+	}
+	return math.Sqrt(ss/float64(len(rets)-1)) * 100
+}
@@ -0,0 +1,93 @@
+package strategy
+
+import (
+	"math"
+	"testing"
+)
+
+func TestATRPercent(t *testing.T) {
+	bars := []Bar{
+		{High: 2010, Low: 1990, Close: 2000},
+		{High: 2030, Low: 2000, Close: 2020}, // TR 30
+		{High: 2025, Low: 1990, Close: 2000}, // TR max(35, 5, 30) = 35
+		{High: 2060, Low: 2040, Close: 2050}, // gap up: TR max(20, 60, 40) = 60
+	}
+	want := (30.0 + 35 + 60) / 3 / 2050 * 100
+	if got := ATRPercent(bars); math.Abs(got-want) > 1e-9 {
+		t.Fatalf("ATRPercent = %f, want %f", got, want)
+	}
+}
+
+func TestRealizedVolPercent(t *testing.T) {
+	flat := []Bar{{Close: 2000}, {Close: 2000}, {Close: 2000}}
+	if got := RealizedVolPercent(flat); got != 0 {
+		t.Fatalf("flat series: expected 0, got %f", got)
+	}
+
+	// Alternating +1%/-1% moves: log returns of about ±1%.
+	bars := []Bar{{Close: 2000}}
+	for i := 0; i < 20; i++ {
+		c := bars[len(bars)-1].Close
+		if i%2 == 0 {
+			c *= 1.01
+		} else {
+			c /= 1.01
+		}
+		bars = append(bars, Bar{Close: c})
+	}
+	if got := RealizedVolPercent(bars); math.Abs(got-1.02) > 0.05 {
+		t.Fatalf("RealizedVolPercent = %f, want about 1.02", got)
+	}
+}
+
+func TestEffectiveSpacing(t *testing.T) {
+	bars := []Bar{
+		{High: 2010, Low: 1990, Close: 2000},
+		{High: 2030, Low: 1990, Close: 2000}, // TR 40 = 2%
+	}
+
+	got, err := EffectiveSpacing(GridParams{SpacingPercent: 1.5})
+	if err != nil || got != 1.5 {
+		t.Fatalf("fixed: got %f, %v", got, err)
+	}
+
+	got, err = EffectiveSpacing(GridParams{SpacingMode: SpacingATR, Bars: bars, SpacingMultiplier: 0.5})
+	if err != nil || math.Abs(got-1) > 1e-9 {
+		t.Fatalf("atr x0.5: got %f, %v", got, err)
+	}
+
+	got, _ = EffectiveSpacing(GridParams{SpacingMode: SpacingATR, Bars: bars, MaxSpacingPercent: 1.2})
+	if got != 1.2 {
+		t.Fatalf("expected clamp to max 1.2, got %f", got)
+	}
+	got, _ = EffectiveSpacing(GridParams{SpacingMode: SpacingATR, Bars: bars, MinSpacingPercent: 3})
+	if got != 3 {
+		t.Fatalf("expected clamp to min 3, got %f", got)
+	}
+
+	if _, err := EffectiveSpacing(GridParams{SpacingMode: SpacingATR, Bars: bars[:1]}); err == nil {
+		t.Fatal("expected error with one bar")
+	}
+	if _, err := EffectiveSpacing(GridParams{SpacingMode: "bollinger"}); err == nil {
+		t.Fatal("expected error for unknown mode")
+	}
+}
+
+func TestCalculateGridLevels_ATRSpacing(t *testing.T) {
+	grid, err := CalculateGridLevels(GridParams{
+		CenterPrice:   2000,
+		LevelCount:    2,
+		AmountPerGrid: 100,
+		SpacingMode:   SpacingATR,
+		Bars: []Bar{
+			{High: 2010, Low: 1990, Close: 2000},
+			{High: 2030, Low: 1990, Close: 2000},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(grid[1].Price-2040) > 1e-6 || math.Abs(grid[0].Price-2000/1.02) > 1e-6 {
+		t.Fatalf("expected 2%% spacing around 2000, got %.4f / %.4f", grid[0].Price, grid[1].Price)
+	}
+}